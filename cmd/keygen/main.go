@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"flag"
+	"log"
+	"os"
+	"rounds/node"
+	"strings"
+)
+
+// keygen generates threshold key shares offline by distributed key generation, so nobody learns the group secret,
+// every node operator writes node keys and hands public key to other operators:
+//
+//	keygen keys keys-node-1
+//
+// deals shares of its own secret to all nodes, nodes are given by their public key dirs, own keys dir included:
+//
+//	keygen deal -t 2 -keys keys-node-1 -peers keys-node-1,keys-node-2,keys-node-3,keys-node-4 -out deal-node-1.pem
+//
+// deals are encrypted to node keys and signed by dealers, so deal files are handed to every operator openly,
+// once deals of all nodes are collected every operator combines them into node key share and group key in keys dir:
+//
+//	keygen combine -keys keys-node-1 -peers keys-node-1,keys-node-2,keys-node-3,keys-node-4 deal-node-*.pem
+//
+// operators compare group keys printed by combine, different keys mean some dealer sent different deals
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("usage: keygen keys|deal|combine [flags]")
+	}
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "keys":
+		writeKeys(args)
+	case "deal":
+		deal(args)
+	case "combine":
+		combine(args)
+	default:
+		log.Fatalf("unknown command %s, commands: keys, deal, combine", cmd)
+	}
+}
+
+func writeKeys(args []string) {
+	for _, dir := range args {
+		written, err := node.WriteKeyPair(dir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !written {
+			log.Printf("keys already exist in %s", dir)
+		}
+	}
+}
+
+func deal(args []string) {
+	fs := flag.NewFlagSet("deal", flag.ExitOnError)
+	t := fs.Int("t", 0, "signature shares needed to recover group signature, f+1 if not set")
+	keys, peers := keyFlags(fs)
+	out := fs.String("out", "deal.pem", "deal file")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	priv, participants := loadParticipants(*keys, *peers)
+	n := len(participants)
+	if *t == 0 {
+		*t = node.FaultTolerance(n) + 1
+	}
+	if *t < 1 || *t > n {
+		log.Fatalf("threshold must be in range [1, %d]", n)
+	}
+	d, err := node.NewDkgDeal(priv, *t, participants)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := node.WriteDkgDeal(*out, d); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("deal of %s (%d of %d) written to %s", d.Dealer, *t, n, *out)
+}
+
+func combine(args []string) {
+	fs := flag.NewFlagSet("combine", flag.ExitOnError)
+	keys, peers := keyFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	priv, participants := loadParticipants(*keys, *peers)
+	deals := make([]*node.DkgDeal, 0, fs.NArg())
+	for _, file := range fs.Args() {
+		d, err := node.ReadDkgDeal(file)
+		if err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+		deals = append(deals, d)
+	}
+	t, err := node.CombineDkgDeals(priv, participants, deals)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := node.WriteThresholdKeys(*keys, t.Share, t.Group, t.N); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("share #%d written to %s", t.Share.I, *keys)
+	log.Printf("group key (%d of %d): %x", t.Group.Threshold(), t.N, t.GroupKey())
+}
+
+func keyFlags(fs *flag.FlagSet) (*string, *string) {
+	keys := fs.String("keys", "", "keys dir of this node")
+	peers := fs.String("peers", "", "comma separated public key dirs of all nodes, this node included")
+	return keys, peers
+}
+
+// loadParticipants loads private key of this node and public keys of all nodes, this node must be one of them
+func loadParticipants(keys string, peers string) (*ecdsa.PrivateKey, []node.DkgParticipant) {
+	if keys == "" || peers == "" {
+		log.Fatal("keys and peers are required")
+	}
+	c := &node.Config{}
+	c.Node.Keyspath = keys
+	priv, _, _ := node.LoadKeyPair(c)
+	pubs := make([]*ecdsa.PublicKey, 0)
+	for _, dir := range strings.Split(peers, ",") {
+		pubs = append(pubs, node.LoadPublicKey(dir))
+	}
+	if err := node.CheckClusterSize(len(pubs)); err != nil {
+		log.Fatal(err)
+	}
+	participants, err := node.NewDkgParticipants(pubs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return priv, participants
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"flag"
 	"github.com/spf13/viper"
+	"log"
+	"os"
 	"rounds/ledger"
 	"rounds/telemetry"
 )
@@ -13,6 +16,27 @@ func main() {
 		panic(err)
 	}
 
+	switch flag.Arg(0) {
+	// db -config ledger.yml verify
+	case "verify":
+		if !ledger.Verify(cfg) {
+			os.Exit(1)
+		}
+		return
+	// db -config ledger.yml snapshot <file> [ledger addr]
+	case "snapshot":
+		if err := ledger.WriteSnapshot(cfg, flag.Arg(2), flag.Arg(1)); err != nil {
+			log.Fatalf("failed to write snapshot: %v", err)
+		}
+		return
+	// db -config ledger.yml restore <file>
+	case "restore":
+		if err := ledger.RestoreSnapshot(cfg, flag.Arg(1)); err != nil {
+			log.Fatalf("failed to restore snapshot: %v", err)
+		}
+		return
+	}
+
 	go ledger.Serve(cfg)
 	telemetry.PromExporter(cfg.Opencensus)
 	telemetry.Tracing(cfg.Opencensus)
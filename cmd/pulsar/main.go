@@ -2,6 +2,8 @@ package main
 
 import (
 	"go.opencensus.io/stats/view"
+	// registers embedded ledger storage
+	_ "rounds/ledger"
 	"rounds/node"
 	"rounds/telemetry"
 )
@@ -9,6 +11,7 @@ import (
 func main() {
 	cfg := node.MakeConfig()
 	node.ValidateConfig(cfg)
+	node.ValidateCluster(cfg)
 
 	node.WriteKeyPairIfNotExists(cfg)
 	priv, pub, pubPem := node.LoadKeyPair(cfg)
@@ -23,7 +26,7 @@ func main() {
 
 	telemetry.PromExporter(cfg.Opencensus)
 	telemetry.Tracing(cfg.Opencensus)
-	if err := view.Register(node.LatencyView); err != nil {
+	if err := view.Register(node.LatencyView, node.RoundLatencyView, node.RejectedMessagesView); err != nil {
 		panic(err)
 	}
 	telemetry.ServeZPages(cfg.Opencensus)
@@ -30,6 +30,7 @@ require (
 	github.com/stretchr/testify v1.5.1
 	github.com/yuin/gluamapper v0.0.0-20150323120927-d836955830e7
 	github.com/yuin/gopher-lua v0.0.0-20191220021717-ab39c6098bdb
+	go.dedis.ch/kyber/v3 v3.0.12
 	go.etcd.io/etcd v3.3.18+incompatible // indirect
 	go.opencensus.io v0.22.2
 	go.uber.org/multierr v1.2.0 // indirect
@@ -0,0 +1,192 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/mr-tron/base58"
+	"log"
+	"net/http"
+	"rounds/logger"
+	"rounds/node"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BeaconConfirmation node signature of confirmed entropy
+type BeaconConfirmation struct {
+	From      string `json:"from"`
+	Signature string `json:"signature"`
+}
+
+// BeaconBlock public pulse, binary fields are hex encoded,
+// winner entropy is the stored entropy group signature is made over with epoch
+type BeaconBlock struct {
+	Epoch         uint64               `json:"epoch"`
+	Timestamp     int64                `json:"timestamp"`
+	RoundStart    int64                `json:"round_start"`
+	Entropy       string               `json:"entropy"`
+	WinnerEntropy string               `json:"winner_entropy"`
+	Hash          string               `json:"hash"`
+	PreviousHash  string               `json:"previous_hash"`
+	Proposers     []string             `json:"proposers"`
+	Confirmations []BeaconConfirmation `json:"confirmations"`
+	Signature     string               `json:"signature,omitempty"`
+}
+
+// BeaconInfo chain info clients need to verify pulses
+type BeaconInfo struct {
+	LatestEpoch uint64   `json:"latest_epoch"`
+	GenesisHash string   `json:"genesis_hash"`
+	GroupKey    string   `json:"group_key,omitempty"`
+	Nodes       []string `json:"nodes"`
+}
+
+type beaconError struct {
+	Error string `json:"error"`
+}
+
+// NewBeaconBlock encodes block for beacon, entropy is hex of randomness bytes of pulse entropy
+func NewBeaconBlock(b *node.Block) (*BeaconBlock, error) {
+	entropy, err := node.DecodeEntropy(b.WinnerEntropy)
+	if err != nil {
+		return nil, err
+	}
+	randomness, err := base58.Decode(entropy)
+	if err != nil {
+		return nil, err
+	}
+	confirmations := make([]BeaconConfirmation, 0, len(b.Confirmations))
+	for _, c := range b.Confirmations {
+		confirmations = append(confirmations, BeaconConfirmation{c.From, hex.EncodeToString(c.Signature)})
+	}
+	proposers := b.Proposers
+	if proposers == nil {
+		proposers = []string{}
+	}
+	return &BeaconBlock{
+		b.Epoch,
+		b.Timestamp,
+		b.Rst,
+		hex.EncodeToString(randomness),
+		hex.EncodeToString(b.WinnerEntropy),
+		hex.EncodeToString(b.Hash),
+		hex.EncodeToString(b.PrevHash),
+		proposers,
+		confirmations,
+		hex.EncodeToString(b.Signature),
+	}, nil
+}
+
+// beacon serves pulses over http as json from ledger store
+type beacon struct {
+	store    Storer
+	groupKey []byte
+	nodes    []string
+
+	log *logger.Logger
+}
+
+func newBeacon(store Storer, v *ChainVerifier) *beacon {
+	nodes := make([]string, 0, len(v.Keys))
+	for id := range v.Keys {
+		nodes = append(nodes, id)
+	}
+	sort.Strings(nodes)
+	return &beacon{
+		store,
+		v.GroupKey,
+		nodes,
+		logger.NewLogger(),
+	}
+}
+
+func (m *beacon) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/public/", m.public)
+	mux.HandleFunc("/info", m.info)
+	return mux
+}
+
+// public serves /public/latest and /public/{epoch}
+func (m *beacon) public(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		m.writeError(w, http.StatusMethodNotAllowed, "only GET is allowed")
+		return
+	}
+	var b *node.Block
+	var err error
+	name := strings.TrimPrefix(r.URL.Path, "/public/")
+	if name == "latest" {
+		b, err = m.store.GetLatestBlock()
+	} else {
+		epoch, perr := strconv.ParseUint(name, 10, 64)
+		if perr != nil {
+			m.writeError(w, http.StatusBadRequest, "epoch must be a number or latest")
+			return
+		}
+		b, err = m.store.GetBlock(epoch)
+	}
+	if err != nil {
+		m.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if b == nil {
+		m.writeError(w, http.StatusNotFound, "pulse not found")
+		return
+	}
+	bb, err := NewBeaconBlock(b)
+	if err != nil {
+		m.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	m.writeJSON(w, http.StatusOK, bb)
+}
+
+func (m *beacon) info(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		m.writeError(w, http.StatusMethodNotAllowed, "only GET is allowed")
+		return
+	}
+	latest, err := m.store.GetLatestBlockEpoch()
+	if err != nil {
+		m.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	genesis, err := m.store.GetBlock(1)
+	if err != nil {
+		m.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	info := &BeaconInfo{
+		LatestEpoch: latest,
+		GroupKey:    hex.EncodeToString(m.groupKey),
+		Nodes:       m.nodes,
+	}
+	if genesis != nil {
+		info.GenesisHash = hex.EncodeToString(genesis.Hash)
+	}
+	m.writeJSON(w, http.StatusOK, info)
+}
+
+func (m *beacon) writeError(w http.ResponseWriter, status int, msg string) {
+	m.writeJSON(w, status, &beaconError{msg})
+}
+
+func (m *beacon) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		m.log.Error(err)
+	}
+}
+
+// ServeBeacon serves public pulses over http from ledger store, keys of chain info are the ones chain is verified against
+func ServeBeacon(c *Config, store Storer) {
+	b := newBeacon(store, NewChainVerifier(c))
+	log.Printf("serving beacon: %s", c.HTTP.Host)
+	if err := http.ListenAndServe(c.HTTP.Host, b.Handler()); err != nil {
+		log.Fatalf("failed to serve beacon: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package ledger
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"rounds/node"
+	"testing"
+)
+
+func getJSON(t *testing.T, srv *httptest.Server, path string, v interface{}) int {
+	resp, err := http.Get(srv.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestBeaconServesPulses(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	a := newTestSigner(t)
+	b := newBeacon(s, &ChainVerifier{Keys: map[string]*ecdsa.PublicKey{a.id: &a.priv.PublicKey}, GroupKey: []byte{1, 2}})
+	srv := httptest.NewServer(b.Handler())
+	defer srv.Close()
+
+	var e beaconError
+	require.Equal(t, http.StatusNotFound, getJSON(t, srv, "/public/latest", &e))
+	require.NotEmpty(t, e.Error)
+
+	entropy := base58.Encode([]byte("randomness"))
+	for epoch := uint64(1); epoch <= 2; epoch++ {
+		commitSigned(t, s, epoch, entropy, a)
+	}
+
+	var latest BeaconBlock
+	require.Equal(t, http.StatusOK, getJSON(t, srv, "/public/latest", &latest))
+	require.Equal(t, uint64(2), latest.Epoch)
+	require.Equal(t, hex.EncodeToString([]byte("randomness")), latest.Entropy)
+	require.Equal(t, []BeaconConfirmation{{a.id, latest.Confirmations[0].Signature}}, latest.Confirmations)
+	require.NotEmpty(t, latest.Confirmations[0].Signature)
+
+	var first BeaconBlock
+	require.Equal(t, http.StatusOK, getJSON(t, srv, "/public/1", &first))
+	require.Equal(t, uint64(1), first.Epoch)
+	require.Equal(t, first.Hash, latest.PreviousHash)
+	require.Empty(t, first.PreviousHash)
+	winnerEntropy, err := hex.DecodeString(first.WinnerEntropy)
+	require.NoError(t, err)
+	decoded, err := node.DecodeEntropy(winnerEntropy)
+	require.NoError(t, err)
+	require.Equal(t, entropy, decoded)
+
+	require.Equal(t, http.StatusNotFound, getJSON(t, srv, "/public/3", &e))
+	require.Equal(t, http.StatusBadRequest, getJSON(t, srv, "/public/first", &e))
+
+	var info BeaconInfo
+	require.Equal(t, http.StatusOK, getJSON(t, srv, "/info", &info))
+	require.Equal(t, uint64(2), info.LatestEpoch)
+	require.Equal(t, first.Hash, info.GenesisHash)
+	require.Equal(t, "0102", info.GroupKey)
+	require.Equal(t, []string{a.id}, info.Nodes)
+}
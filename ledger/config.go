@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"github.com/spf13/viper"
+	"rounds/node"
 	"rounds/telemetry"
 )
 
@@ -12,8 +13,24 @@ type Config struct {
 		Host string `validate:"required"`
 	} `validate:"required"`
 	DB struct {
+		// Type store backend, badger or memory, badger if not set
+		Type string
 		Path string `validate:"required"`
+		// Retention blocks out of retention are pruned by badger store, all blocks are kept if not set
+		Retention Retention
 	} `validate:"required"`
+	// HTTP public beacon api, not served if host is empty
+	HTTP struct {
+		Host string
+	}
+	// Verify keys chain is verified against by verify command
+	Verify struct {
+		// Peers nodes which sign blocks, only public key dirs are used, evidence committed to ledger is verified
+		// against them too
+		Peers []node.Peer
+		// GroupKeyDir keys dir with threshold group key, block group signatures aren't checked if empty
+		GroupKeyDir string `yaml:"groupKeyDir"`
+	}
 	Opencensus telemetry.OpencensusConfig
 	Logging    struct {
 		Level    string `validate:"required"`
@@ -0,0 +1,75 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"rounds/logger"
+	"rounds/node"
+)
+
+func init() {
+	node.RegisterStorage(node.EmbeddedStore, func(c *node.Config) (node.Storage, error) {
+		if c.Store.Path == "" {
+			return nil, fmt.Errorf("store path is required for store type %q", node.EmbeddedStore)
+		}
+		return NewEmbeddedStorage(c.Store.Path, c.Store.Host, c.Node.Peers), nil
+	})
+}
+
+// EmbeddedStorage node storage committing blocks to its own ledger in node process,
+// ledger api is served for peers and quorum readers
+type EmbeddedStorage struct {
+	srv *server
+
+	log *logger.Logger
+}
+
+// Commit commits block to local ledger
+func (m *EmbeddedStorage) Commit(ctx context.Context, d node.BlockData) error {
+	_, err := m.srv.commit(node.NewBlock(d))
+	return err
+}
+
+func (m *EmbeddedStorage) GetLatestBlock() (*node.Block, error) {
+	return m.srv.store.GetLatestBlock()
+}
+
+func (m *EmbeddedStorage) GetBlock(epoch uint64) (*node.Block, error) {
+	return m.srv.store.GetBlock(epoch)
+}
+
+func (m *EmbeddedStorage) GetBlockRange(from uint64, limit int) ([]*node.Block, uint64, error) {
+	blocks, err := m.srv.store.GetBlockRange(from, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	next, err := nextEpoch(m.srv.store, blocks, limit)
+	return blocks, next, err
+}
+
+func (m *EmbeddedStorage) GetLatestBlockEpoch() uint64 {
+	epoch, err := m.srv.store.GetLatestBlockEpoch()
+	if err != nil {
+		m.log.Error(err)
+	}
+	return epoch
+}
+
+func (m *EmbeddedStorage) CommitEvidence(ctx context.Context, e *node.Evidence) error {
+	return m.srv.store.CommitEvidence(e)
+}
+
+// NewEmbeddedStorage opens ledger db by path, ledger api is served on host if it's set,
+// evidence committed over api is verified against keys of peers
+func NewEmbeddedStorage(path string, host string, peers []node.Peer) *EmbeddedStorage {
+	c := &Config{}
+	c.DB.Path = path
+	srv := newServer(NewBadgerStore(c), peerKeys(peers))
+	if host != "" {
+		go srv.listen(host)
+	}
+	return &EmbeddedStorage{
+		srv,
+		logger.NewLogger(),
+	}
+}
@@ -0,0 +1,119 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"io/ioutil"
+	"net"
+	"os"
+	pb "rounds/ledger/pb"
+	"rounds/node"
+	"testing"
+)
+
+func TestEmbeddedStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ledger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	c := &node.Config{}
+	c.Store.Type = node.EmbeddedStore
+	c.Store.Path = dir
+	s, err := node.NewStorage(c)
+	require.NoError(t, err)
+	defer s.(*EmbeddedStorage).srv.store.(*BadgerStore).db.Close()
+	ctx := context.Background()
+
+	for epoch := uint64(1); epoch <= 2; epoch++ {
+		d, err := node.NewBlockData(epoch, 1000, []string{"N0"}, "entropy", nil)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit(ctx, d))
+		// commit is retried by every node of cluster sharing ledger
+		require.NoError(t, s.Commit(ctx, d))
+	}
+	d, err := node.NewBlockData(2, 1000, []string{"N0"}, "another", nil)
+	require.NoError(t, err)
+	require.True(t, errors.Is(s.Commit(ctx, d), node.ErrConflict))
+	require.Equal(t, uint64(2), s.GetLatestBlockEpoch())
+
+	blocks, next, err := s.GetBlockRange(1, 1)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, uint64(2), next)
+	blocks, next, err = s.GetBlockRange(next, 2)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, uint64(0), next)
+	require.Equal(t, blocks[0].PrevHash, mustBlock(t, s, 1).Hash)
+}
+
+func mustBlock(t *testing.T, s node.Storage, epoch uint64) *node.Block {
+	b, err := s.GetBlock(epoch)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	return b
+}
+
+// testBlockRange checks pages of 5 committed blocks, range is inclusive of both its epoch and the latest one,
+// as node storages page blocks
+func testBlockRange(t *testing.T, s node.Storage) {
+	ctx := context.Background()
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		d, err := node.NewBlockData(epoch, int64(1000+epoch), []string{"N0"}, "entropy", nil)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit(ctx, d))
+	}
+	pages := []struct {
+		from   uint64
+		limit  int
+		epochs []uint64
+		next   uint64
+	}{
+		{0, 2, []uint64{1, 2}, 3},
+		{3, 2, []uint64{3, 4}, 5},
+		{5, 2, []uint64{5}, 0},
+		// full page ending with the latest block is the last one
+		{4, 2, []uint64{4, 5}, 0},
+		{1, 10, []uint64{1, 2, 3, 4, 5}, 0},
+		{6, 2, []uint64{}, 0},
+	}
+	for _, p := range pages {
+		blocks, next, err := s.GetBlockRange(p.from, p.limit)
+		require.NoError(t, err)
+		epochs := make([]uint64, 0, len(blocks))
+		for _, b := range blocks {
+			epochs = append(epochs, b.Epoch)
+		}
+		require.Equal(t, p.epochs, epochs, "range from %d", p.from)
+		require.Equal(t, p.next, next, "next of range from %d", p.from)
+	}
+}
+
+func TestEmbeddedStorageBlockRange(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	testBlockRange(t, &EmbeddedStorage{srv: newServer(s, nil), log: s.log})
+}
+
+// serveStore serves ledger api of store on a free port
+func serveStore(t *testing.T, s Storer) (string, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	pb.RegisterLedgerServer(srv, newServer(s, nil))
+	go srv.Serve(lis)
+	return lis.Addr().String(), srv.Stop
+}
+
+func TestRemoteStorageBlockRange(t *testing.T) {
+	addr, stop := serveStore(t, NewMemoryStore())
+	defer stop()
+	testBlockRange(t, node.NewBadgerStorage(addr))
+
+	s, done := testStore(t)
+	defer done()
+	addr, stop = serveStore(t, s)
+	defer stop()
+	testBlockRange(t, node.NewBadgerStorage(addr))
+}
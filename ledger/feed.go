@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"rounds/node"
+	"sync"
+)
+
+const (
+	// subscriberBuffer blocks buffered for subscriber, subscriber lags if it's full
+	subscriberBuffer = 64
+)
+
+// subscriber receives committed blocks, lagged is signaled when blocks are dropped because buffer is full
+type subscriber struct {
+	blocks chan *node.Block
+	lagged chan struct{}
+}
+
+// pulseFeed fans out committed blocks to subscribers, publishing never blocks on slow subscribers,
+// they catch up with dropped blocks from store
+type pulseFeed struct {
+	mu   sync.Mutex
+	subs map[*subscriber]struct{}
+}
+
+func newPulseFeed() *pulseFeed {
+	return &pulseFeed{
+		subs: make(map[*subscriber]struct{}),
+	}
+}
+
+func (f *pulseFeed) subscribe() *subscriber {
+	s := &subscriber{
+		make(chan *node.Block, subscriberBuffer),
+		make(chan struct{}, 1),
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subs[s] = struct{}{}
+	return s
+}
+
+func (f *pulseFeed) unsubscribe(s *subscriber) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.subs, s)
+}
+
+// publish sends block to subscribers, subscriber with full buffer is signaled it lags
+func (f *pulseFeed) publish(b *node.Block) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for s := range f.subs {
+		select {
+		case s.blocks <- b:
+		default:
+			select {
+			case s.lagged <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// subscribers number of active subscribers
+func (f *pulseFeed) subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
@@ -0,0 +1,113 @@
+package ledger
+
+import (
+	"rounds/logger"
+	"rounds/node"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStore keeps blocks and evidence in memory, for tests and simulations
+type MemoryStore struct {
+	mu       sync.RWMutex
+	blocks   map[uint64]*node.Block
+	latest   *node.Block
+	evidence map[string]*node.Evidence
+
+	log *logger.Logger
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		blocks:   make(map[uint64]*node.Block),
+		evidence: make(map[string]*node.Evidence),
+		log:      logger.NewLogger(),
+	}
+}
+
+func (m *MemoryStore) CommitPulse(b *node.Block) (*node.Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, err := node.ChainBlock(b, m.latest, m.getBlock)
+	if err != nil {
+		return nil, err
+	}
+	if stored != b {
+		return committed(stored, b)
+	}
+	m.log.Infof("committing pulse: %s", b.String())
+	m.blocks[b.Epoch] = b
+	m.latest = b
+	return b, nil
+}
+
+func (m *MemoryStore) getBlock(epoch uint64) (*node.Block, error) {
+	return m.blocks[epoch], nil
+}
+
+func (m *MemoryStore) GetBlock(epoch uint64) (*node.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.getBlock(epoch)
+}
+
+func (m *MemoryStore) GetBlockRange(from uint64, limit int) ([]*node.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	blocks := make([]*node.Block, 0)
+	if m.latest == nil {
+		return blocks, nil
+	}
+	for epoch := from; epoch <= m.latest.Epoch && len(blocks) < limit; epoch++ {
+		if b, ok := m.blocks[epoch]; ok {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks, nil
+}
+
+func (m *MemoryStore) GetLatestBlock() (*node.Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest, nil
+}
+
+func (m *MemoryStore) GetLatestBlockEpoch() (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.latest == nil {
+		return 0, nil
+	}
+	return m.latest.Epoch, nil
+}
+
+// CommitEvidence stores evidence once per offender, round and message type
+func (m *MemoryStore) CommitEvidence(e *node.Evidence) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evidence[string(evidenceKey(e))] = e
+	return nil
+}
+
+// GetEvidence gets evidence in the order of badger store keys
+func (m *MemoryStore) GetEvidence(offender string) ([]*node.Evidence, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	prefix := string(evidencePrefix)
+	if offender != "" {
+		prefix = string(prefixed(evidencePrefix, []byte(offender+"/")))
+	}
+	keys := make([]string, 0)
+	for k := range m.evidence {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	evidence := make([]*node.Evidence, 0, len(keys))
+	for _, k := range keys {
+		evidence = append(evidence, m.evidence[k])
+	}
+	return evidence, nil
+}
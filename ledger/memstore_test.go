@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"errors"
+	"github.com/stretchr/testify/require"
+	"rounds/node"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	latest, err := s.GetLatestBlock()
+	require.NoError(t, err)
+	require.Nil(t, latest)
+
+	for epoch := uint64(1); epoch <= 3; epoch++ {
+		b, err := s.CommitPulse(&node.Block{Rst: int64(1000 + epoch), WinnerEntropy: []byte("entropy")})
+		require.NoError(t, err)
+		require.Equal(t, epoch, b.Epoch)
+	}
+	// retried append of the latest block isn't appended again
+	appended, err := s.CommitPulse(&node.Block{Rst: 1003, WinnerEntropy: []byte("entropy")})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), appended.Epoch)
+	epoch, err := s.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), epoch)
+	blocks, err := s.GetBlockRange(2, MaxBlockRange)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.Equal(t, blocks[0].Hash, blocks[1].PrevHash)
+
+	retry, err := s.CommitPulse(&node.Block{Epoch: 2, WinnerEntropy: []byte("entropy")})
+	require.NoError(t, err)
+	require.Equal(t, blocks[0], retry)
+	conflict, err := s.CommitPulse(&node.Block{Epoch: 2, WinnerEntropy: []byte("another")})
+	require.IsType(t, &ConflictError{}, err)
+	require.True(t, errors.Is(err, node.ErrConflict))
+	require.Equal(t, node.ErrEpochConflict(2).Error(), err.Error())
+	require.Equal(t, blocks[0], conflict)
+	_, err = s.CommitPulse(&node.Block{Epoch: 5, WinnerEntropy: []byte("entropy")})
+	require.Error(t, err)
+
+	require.NoError(t, s.CommitEvidence(&node.Evidence{Offender: "b", Epoch: 1}))
+	require.NoError(t, s.CommitEvidence(&node.Evidence{Offender: "a", Epoch: 1}))
+	// another pair of conflicting messages in the same round is kept
+	require.NoError(t, s.CommitEvidence(&node.Evidence{Offender: "a", Epoch: 1, First: node.SignedMessage{Data: []byte("other")}}))
+	evidence, err := s.GetEvidence("")
+	require.NoError(t, err)
+	require.Len(t, evidence, 3)
+	require.Equal(t, "a", evidence[0].Offender)
+	evidence, err = s.GetEvidence("b")
+	require.NoError(t, err)
+	require.Len(t, evidence, 1)
+}
@@ -102,111 +102,1260 @@ func (m *LatestPNResponse) GetError() string {
 	return ""
 }
 
+type Confirmation struct {
+	From      string `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	// threshold signature share of the block
+	Share []byte `protobuf:"bytes,3,opt,name=share,proto3" json:"share,omitempty"`
+	// view confirmation is signed in
+	View                 uint64   `protobuf:"varint,4,opt,name=view,proto3" json:"view,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Confirmation) Reset()         { *m = Confirmation{} }
+func (m *Confirmation) String() string { return proto.CompactTextString(m) }
+func (*Confirmation) ProtoMessage()    {}
+func (*Confirmation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{2}
+}
+
+func (m *Confirmation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Confirmation.Unmarshal(m, b)
+}
+func (m *Confirmation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Confirmation.Marshal(b, m, deterministic)
+}
+func (m *Confirmation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Confirmation.Merge(m, src)
+}
+func (m *Confirmation) XXX_Size() int {
+	return xxx_messageInfo_Confirmation.Size(m)
+}
+func (m *Confirmation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Confirmation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Confirmation proto.InternalMessageInfo
+
+func (m *Confirmation) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Confirmation) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Confirmation) GetShare() []byte {
+	if m != nil {
+		return m.Share
+	}
+	return nil
+}
+
+func (m *Confirmation) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+// QuorumCert votes of 2f+1 nodes for entropy proposed at height, stored alongside the block
+type QuorumCert struct {
+	Height               uint64          `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Epoch                uint64          `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Rst                  int64           `protobuf:"varint,3,opt,name=rst,proto3" json:"rst,omitempty"`
+	Entropy              string          `protobuf:"bytes,4,opt,name=entropy,proto3" json:"entropy,omitempty"`
+	Votes                []*Confirmation `protobuf:"bytes,5,rep,name=votes,proto3" json:"votes,omitempty"`
+	Proposer             string          `protobuf:"bytes,6,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *QuorumCert) Reset()         { *m = QuorumCert{} }
+func (m *QuorumCert) String() string { return proto.CompactTextString(m) }
+func (*QuorumCert) ProtoMessage()    {}
+func (*QuorumCert) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{3}
+}
+
+func (m *QuorumCert) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QuorumCert.Unmarshal(m, b)
+}
+func (m *QuorumCert) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QuorumCert.Marshal(b, m, deterministic)
+}
+func (m *QuorumCert) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QuorumCert.Merge(m, src)
+}
+func (m *QuorumCert) XXX_Size() int {
+	return xxx_messageInfo_QuorumCert.Size(m)
+}
+func (m *QuorumCert) XXX_DiscardUnknown() {
+	xxx_messageInfo_QuorumCert.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QuorumCert proto.InternalMessageInfo
+
+func (m *QuorumCert) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *QuorumCert) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *QuorumCert) GetRst() int64 {
+	if m != nil {
+		return m.Rst
+	}
+	return 0
+}
+
+func (m *QuorumCert) GetEntropy() string {
+	if m != nil {
+		return m.Entropy
+	}
+	return ""
+}
+
+func (m *QuorumCert) GetVotes() []*Confirmation {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
+
+func (m *QuorumCert) GetProposer() string {
+	if m != nil {
+		return m.Proposer
+	}
+	return ""
+}
+
+// VrfProof proof of node entropy contribution to the pulse
+type VrfProof struct {
+	From                 string   `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Proof                []byte   `protobuf:"bytes,2,opt,name=proof,proto3" json:"proof,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VrfProof) Reset()         { *m = VrfProof{} }
+func (m *VrfProof) String() string { return proto.CompactTextString(m) }
+func (*VrfProof) ProtoMessage()    {}
+func (*VrfProof) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{4}
+}
+
+func (m *VrfProof) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VrfProof.Unmarshal(m, b)
+}
+func (m *VrfProof) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VrfProof.Marshal(b, m, deterministic)
+}
+func (m *VrfProof) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VrfProof.Merge(m, src)
+}
+func (m *VrfProof) XXX_Size() int {
+	return xxx_messageInfo_VrfProof.Size(m)
+}
+func (m *VrfProof) XXX_DiscardUnknown() {
+	xxx_messageInfo_VrfProof.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VrfProof proto.InternalMessageInfo
+
+func (m *VrfProof) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *VrfProof) GetProof() []byte {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+// Block record stored by epoch, hash covers all fields but itself and links block to the previous one
+type Block struct {
+	Epoch                uint64          `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	PrevHash             []byte          `protobuf:"bytes,2,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	Hash                 []byte          `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	Timestamp            int64           `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Rst                  int64           `protobuf:"varint,5,opt,name=rst,proto3" json:"rst,omitempty"`
+	Entropy              []byte          `protobuf:"bytes,6,opt,name=entropy,proto3" json:"entropy,omitempty"`
+	Proposers            []string        `protobuf:"bytes,7,rep,name=proposers,proto3" json:"proposers,omitempty"`
+	Confirmations        []*Confirmation `protobuf:"bytes,8,rep,name=confirmations,proto3" json:"confirmations,omitempty"`
+	Qc                   *QuorumCert     `protobuf:"bytes,9,opt,name=qc,proto3" json:"qc,omitempty"`
+	Proofs               []*VrfProof     `protobuf:"bytes,10,rep,name=proofs,proto3" json:"proofs,omitempty"`
+	Signature            []byte          `protobuf:"bytes,11,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Block) Reset()         { *m = Block{} }
+func (m *Block) String() string { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()    {}
+func (*Block) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{5}
+}
+
+func (m *Block) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Block.Unmarshal(m, b)
+}
+func (m *Block) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Block.Marshal(b, m, deterministic)
+}
+func (m *Block) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Block.Merge(m, src)
+}
+func (m *Block) XXX_Size() int {
+	return xxx_messageInfo_Block.Size(m)
+}
+func (m *Block) XXX_DiscardUnknown() {
+	xxx_messageInfo_Block.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Block proto.InternalMessageInfo
+
+func (m *Block) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *Block) GetPrevHash() []byte {
+	if m != nil {
+		return m.PrevHash
+	}
+	return nil
+}
+
+func (m *Block) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Block) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Block) GetRst() int64 {
+	if m != nil {
+		return m.Rst
+	}
+	return 0
+}
+
+func (m *Block) GetEntropy() []byte {
+	if m != nil {
+		return m.Entropy
+	}
+	return nil
+}
+
+func (m *Block) GetProposers() []string {
+	if m != nil {
+		return m.Proposers
+	}
+	return nil
+}
+
+func (m *Block) GetConfirmations() []*Confirmation {
+	if m != nil {
+		return m.Confirmations
+	}
+	return nil
+}
+
+func (m *Block) GetQc() *QuorumCert {
+	if m != nil {
+		return m.Qc
+	}
+	return nil
+}
+
+func (m *Block) GetProofs() []*VrfProof {
+	if m != nil {
+		return m.Proofs
+	}
+	return nil
+}
+
+func (m *Block) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
 type CommitPulseRequest struct {
-	Entropy              []byte   `protobuf:"bytes,2,opt,name=entropy,proto3" json:"entropy,omitempty"`
+	Entropy       []byte          `protobuf:"bytes,2,opt,name=entropy,proto3" json:"entropy,omitempty"`
+	Confirmations []*Confirmation `protobuf:"bytes,3,rep,name=confirmations,proto3" json:"confirmations,omitempty"`
+	Qc            *QuorumCert     `protobuf:"bytes,4,opt,name=qc,proto3" json:"qc,omitempty"`
+	Proofs        []*VrfProof     `protobuf:"bytes,5,rep,name=proofs,proto3" json:"proofs,omitempty"`
+	// threshold group signature of the block epoch and entropy
+	Signature []byte   `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	Rst       int64    `protobuf:"varint,7,opt,name=rst,proto3" json:"rst,omitempty"`
+	Proposers []string `protobuf:"bytes,8,rep,name=proposers,proto3" json:"proposers,omitempty"`
+	// epoch block is committed at, appended to the latest block if 0
+	Epoch                uint64   `protobuf:"varint,9,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommitPulseRequest) Reset()         { *m = CommitPulseRequest{} }
+func (m *CommitPulseRequest) String() string { return proto.CompactTextString(m) }
+func (*CommitPulseRequest) ProtoMessage()    {}
+func (*CommitPulseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{6}
+}
+
+func (m *CommitPulseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitPulseRequest.Unmarshal(m, b)
+}
+func (m *CommitPulseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitPulseRequest.Marshal(b, m, deterministic)
+}
+func (m *CommitPulseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitPulseRequest.Merge(m, src)
+}
+func (m *CommitPulseRequest) XXX_Size() int {
+	return xxx_messageInfo_CommitPulseRequest.Size(m)
+}
+func (m *CommitPulseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitPulseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitPulseRequest proto.InternalMessageInfo
+
+func (m *CommitPulseRequest) GetEntropy() []byte {
+	if m != nil {
+		return m.Entropy
+	}
+	return nil
+}
+
+func (m *CommitPulseRequest) GetConfirmations() []*Confirmation {
+	if m != nil {
+		return m.Confirmations
+	}
+	return nil
+}
+
+func (m *CommitPulseRequest) GetQc() *QuorumCert {
+	if m != nil {
+		return m.Qc
+	}
+	return nil
+}
+
+func (m *CommitPulseRequest) GetProofs() []*VrfProof {
+	if m != nil {
+		return m.Proofs
+	}
+	return nil
+}
+
+func (m *CommitPulseRequest) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *CommitPulseRequest) GetRst() int64 {
+	if m != nil {
+		return m.Rst
+	}
+	return 0
+}
+
+func (m *CommitPulseRequest) GetProposers() []string {
+	if m != nil {
+		return m.Proposers
+	}
+	return nil
+}
+
+func (m *CommitPulseRequest) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+// CommitPulseResponse block is the committed block, or the one already committed at the epoch
+// on retry or conflict, conflict is set if it has another entropy
+type CommitPulseResponse struct {
+	Error                string   `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Block                *Block   `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
+	Conflict             bool     `protobuf:"varint,3,opt,name=conflict,proto3" json:"conflict,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommitPulseResponse) Reset()         { *m = CommitPulseResponse{} }
+func (m *CommitPulseResponse) String() string { return proto.CompactTextString(m) }
+func (*CommitPulseResponse) ProtoMessage()    {}
+func (*CommitPulseResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{7}
+}
+
+func (m *CommitPulseResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitPulseResponse.Unmarshal(m, b)
+}
+func (m *CommitPulseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitPulseResponse.Marshal(b, m, deterministic)
+}
+func (m *CommitPulseResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitPulseResponse.Merge(m, src)
+}
+func (m *CommitPulseResponse) XXX_Size() int {
+	return xxx_messageInfo_CommitPulseResponse.Size(m)
+}
+func (m *CommitPulseResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitPulseResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitPulseResponse proto.InternalMessageInfo
+
+func (m *CommitPulseResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *CommitPulseResponse) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *CommitPulseResponse) GetConflict() bool {
+	if m != nil {
+		return m.Conflict
+	}
+	return false
+}
+
+type SignedMessage struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Signature            []byte   `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SignedMessage) Reset()         { *m = SignedMessage{} }
+func (m *SignedMessage) String() string { return proto.CompactTextString(m) }
+func (*SignedMessage) ProtoMessage()    {}
+func (*SignedMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{8}
+}
+
+func (m *SignedMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SignedMessage.Unmarshal(m, b)
+}
+func (m *SignedMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SignedMessage.Marshal(b, m, deterministic)
+}
+func (m *SignedMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SignedMessage.Merge(m, src)
+}
+func (m *SignedMessage) XXX_Size() int {
+	return xxx_messageInfo_SignedMessage.Size(m)
+}
+func (m *SignedMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_SignedMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SignedMessage proto.InternalMessageInfo
+
+func (m *SignedMessage) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *SignedMessage) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+type Evidence struct {
+	Offender             string         `protobuf:"bytes,1,opt,name=offender,proto3" json:"offender,omitempty"`
+	Type                 int32          `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	Epoch                uint64         `protobuf:"varint,3,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Rst                  int64          `protobuf:"varint,4,opt,name=rst,proto3" json:"rst,omitempty"`
+	First                *SignedMessage `protobuf:"bytes,5,opt,name=first,proto3" json:"first,omitempty"`
+	Second               *SignedMessage `protobuf:"bytes,6,opt,name=second,proto3" json:"second,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Evidence) Reset()         { *m = Evidence{} }
+func (m *Evidence) String() string { return proto.CompactTextString(m) }
+func (*Evidence) ProtoMessage()    {}
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{9}
+}
+
+func (m *Evidence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Evidence.Unmarshal(m, b)
+}
+func (m *Evidence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Evidence.Marshal(b, m, deterministic)
+}
+func (m *Evidence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Evidence.Merge(m, src)
+}
+func (m *Evidence) XXX_Size() int {
+	return xxx_messageInfo_Evidence.Size(m)
+}
+func (m *Evidence) XXX_DiscardUnknown() {
+	xxx_messageInfo_Evidence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Evidence proto.InternalMessageInfo
+
+func (m *Evidence) GetOffender() string {
+	if m != nil {
+		return m.Offender
+	}
+	return ""
+}
+
+func (m *Evidence) GetType() int32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+func (m *Evidence) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *Evidence) GetRst() int64 {
+	if m != nil {
+		return m.Rst
+	}
+	return 0
+}
+
+func (m *Evidence) GetFirst() *SignedMessage {
+	if m != nil {
+		return m.First
+	}
+	return nil
+}
+
+func (m *Evidence) GetSecond() *SignedMessage {
+	if m != nil {
+		return m.Second
+	}
+	return nil
+}
+
+type CommitEvidenceRequest struct {
+	Evidence             *Evidence `protobuf:"bytes,1,opt,name=evidence,proto3" json:"evidence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *CommitEvidenceRequest) Reset()         { *m = CommitEvidenceRequest{} }
+func (m *CommitEvidenceRequest) String() string { return proto.CompactTextString(m) }
+func (*CommitEvidenceRequest) ProtoMessage()    {}
+func (*CommitEvidenceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{10}
+}
+
+func (m *CommitEvidenceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitEvidenceRequest.Unmarshal(m, b)
+}
+func (m *CommitEvidenceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitEvidenceRequest.Marshal(b, m, deterministic)
+}
+func (m *CommitEvidenceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitEvidenceRequest.Merge(m, src)
+}
+func (m *CommitEvidenceRequest) XXX_Size() int {
+	return xxx_messageInfo_CommitEvidenceRequest.Size(m)
+}
+func (m *CommitEvidenceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitEvidenceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitEvidenceRequest proto.InternalMessageInfo
+
+func (m *CommitEvidenceRequest) GetEvidence() *Evidence {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+type CommitEvidenceResponse struct {
+	Error                string   `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommitEvidenceResponse) Reset()         { *m = CommitEvidenceResponse{} }
+func (m *CommitEvidenceResponse) String() string { return proto.CompactTextString(m) }
+func (*CommitEvidenceResponse) ProtoMessage()    {}
+func (*CommitEvidenceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{11}
+}
+
+func (m *CommitEvidenceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitEvidenceResponse.Unmarshal(m, b)
+}
+func (m *CommitEvidenceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitEvidenceResponse.Marshal(b, m, deterministic)
+}
+func (m *CommitEvidenceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitEvidenceResponse.Merge(m, src)
+}
+func (m *CommitEvidenceResponse) XXX_Size() int {
+	return xxx_messageInfo_CommitEvidenceResponse.Size(m)
+}
+func (m *CommitEvidenceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitEvidenceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitEvidenceResponse proto.InternalMessageInfo
+
+func (m *CommitEvidenceResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type GetEvidenceRequest struct {
+	// offender node id, all evidence if empty
+	Offender             string   `protobuf:"bytes,1,opt,name=offender,proto3" json:"offender,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetEvidenceRequest) Reset()         { *m = GetEvidenceRequest{} }
+func (m *GetEvidenceRequest) String() string { return proto.CompactTextString(m) }
+func (*GetEvidenceRequest) ProtoMessage()    {}
+func (*GetEvidenceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{12}
+}
+
+func (m *GetEvidenceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetEvidenceRequest.Unmarshal(m, b)
+}
+func (m *GetEvidenceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetEvidenceRequest.Marshal(b, m, deterministic)
+}
+func (m *GetEvidenceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetEvidenceRequest.Merge(m, src)
+}
+func (m *GetEvidenceRequest) XXX_Size() int {
+	return xxx_messageInfo_GetEvidenceRequest.Size(m)
+}
+func (m *GetEvidenceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetEvidenceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetEvidenceRequest proto.InternalMessageInfo
+
+func (m *GetEvidenceRequest) GetOffender() string {
+	if m != nil {
+		return m.Offender
+	}
+	return ""
+}
+
+type GetEvidenceResponse struct {
+	Evidence             []*Evidence `protobuf:"bytes,1,rep,name=evidence,proto3" json:"evidence,omitempty"`
+	Error                string      `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetEvidenceResponse) Reset()         { *m = GetEvidenceResponse{} }
+func (m *GetEvidenceResponse) String() string { return proto.CompactTextString(m) }
+func (*GetEvidenceResponse) ProtoMessage()    {}
+func (*GetEvidenceResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{13}
+}
+
+func (m *GetEvidenceResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetEvidenceResponse.Unmarshal(m, b)
+}
+func (m *GetEvidenceResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetEvidenceResponse.Marshal(b, m, deterministic)
+}
+func (m *GetEvidenceResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetEvidenceResponse.Merge(m, src)
+}
+func (m *GetEvidenceResponse) XXX_Size() int {
+	return xxx_messageInfo_GetEvidenceResponse.Size(m)
+}
+func (m *GetEvidenceResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetEvidenceResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetEvidenceResponse proto.InternalMessageInfo
+
+func (m *GetEvidenceResponse) GetEvidence() []*Evidence {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+func (m *GetEvidenceResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type GetBlockRequest struct {
+	Epoch                uint64   `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockRequest) Reset()         { *m = GetBlockRequest{} }
+func (m *GetBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlockRequest) ProtoMessage()    {}
+func (*GetBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{14}
+}
+
+func (m *GetBlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockRequest.Unmarshal(m, b)
+}
+func (m *GetBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockRequest.Merge(m, src)
+}
+func (m *GetBlockRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlockRequest.Size(m)
+}
+func (m *GetBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockRequest proto.InternalMessageInfo
+
+func (m *GetBlockRequest) GetEpoch() uint64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+// GetBlockResponse block is empty if it's not found or ledger has no blocks yet
+type GetBlockResponse struct {
+	Block                *Block   `protobuf:"bytes,1,opt,name=block,proto3" json:"block,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockResponse) Reset()         { *m = GetBlockResponse{} }
+func (m *GetBlockResponse) String() string { return proto.CompactTextString(m) }
+func (*GetBlockResponse) ProtoMessage()    {}
+func (*GetBlockResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{15}
+}
+
+func (m *GetBlockResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockResponse.Unmarshal(m, b)
+}
+func (m *GetBlockResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockResponse.Marshal(b, m, deterministic)
+}
+func (m *GetBlockResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockResponse.Merge(m, src)
+}
+func (m *GetBlockResponse) XXX_Size() int {
+	return xxx_messageInfo_GetBlockResponse.Size(m)
+}
+func (m *GetBlockResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockResponse proto.InternalMessageInfo
+
+func (m *GetBlockResponse) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *GetBlockResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type GetBlockRangeRequest struct {
+	FromEpoch uint64 `protobuf:"varint,1,opt,name=from_epoch,json=fromEpoch,proto3" json:"from_epoch,omitempty"`
+	// max blocks in response, limited by ledger page size
+	Limit                uint32   `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockRangeRequest) Reset()         { *m = GetBlockRangeRequest{} }
+func (m *GetBlockRangeRequest) String() string { return proto.CompactTextString(m) }
+func (*GetBlockRangeRequest) ProtoMessage()    {}
+func (*GetBlockRangeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{16}
+}
+
+func (m *GetBlockRangeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockRangeRequest.Unmarshal(m, b)
+}
+func (m *GetBlockRangeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockRangeRequest.Marshal(b, m, deterministic)
+}
+func (m *GetBlockRangeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockRangeRequest.Merge(m, src)
+}
+func (m *GetBlockRangeRequest) XXX_Size() int {
+	return xxx_messageInfo_GetBlockRangeRequest.Size(m)
+}
+func (m *GetBlockRangeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockRangeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockRangeRequest proto.InternalMessageInfo
+
+func (m *GetBlockRangeRequest) GetFromEpoch() uint64 {
+	if m != nil {
+		return m.FromEpoch
+	}
+	return 0
+}
+
+func (m *GetBlockRangeRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type GetBlockRangeResponse struct {
+	Blocks []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// next_epoch epoch next page starts from, 0 if page is not full, so there are no more blocks
+	NextEpoch            uint64   `protobuf:"varint,2,opt,name=next_epoch,json=nextEpoch,proto3" json:"next_epoch,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetBlockRangeResponse) Reset()         { *m = GetBlockRangeResponse{} }
+func (m *GetBlockRangeResponse) String() string { return proto.CompactTextString(m) }
+func (*GetBlockRangeResponse) ProtoMessage()    {}
+func (*GetBlockRangeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{17}
+}
+
+func (m *GetBlockRangeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetBlockRangeResponse.Unmarshal(m, b)
+}
+func (m *GetBlockRangeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetBlockRangeResponse.Marshal(b, m, deterministic)
+}
+func (m *GetBlockRangeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetBlockRangeResponse.Merge(m, src)
+}
+func (m *GetBlockRangeResponse) XXX_Size() int {
+	return xxx_messageInfo_GetBlockRangeResponse.Size(m)
+}
+func (m *GetBlockRangeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetBlockRangeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetBlockRangeResponse proto.InternalMessageInfo
+
+func (m *GetBlockRangeResponse) GetBlocks() []*Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *GetBlockRangeResponse) GetNextEpoch() uint64 {
+	if m != nil {
+		return m.NextEpoch
+	}
+	return 0
+}
+
+func (m *GetBlockRangeResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type GetLatestBlockRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetLatestBlockRequest) Reset()         { *m = GetLatestBlockRequest{} }
+func (m *GetLatestBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*GetLatestBlockRequest) ProtoMessage()    {}
+func (*GetLatestBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{18}
+}
+
+func (m *GetLatestBlockRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetLatestBlockRequest.Unmarshal(m, b)
+}
+func (m *GetLatestBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetLatestBlockRequest.Marshal(b, m, deterministic)
+}
+func (m *GetLatestBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetLatestBlockRequest.Merge(m, src)
+}
+func (m *GetLatestBlockRequest) XXX_Size() int {
+	return xxx_messageInfo_GetLatestBlockRequest.Size(m)
+}
+func (m *GetLatestBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetLatestBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetLatestBlockRequest proto.InternalMessageInfo
+
+type SubscribePulsesRequest struct {
+	// from_epoch blocks from epoch are replayed before new ones, only new blocks are sent if 0
+	FromEpoch            uint64   `protobuf:"varint,1,opt,name=from_epoch,json=fromEpoch,proto3" json:"from_epoch,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubscribePulsesRequest) Reset()         { *m = SubscribePulsesRequest{} }
+func (m *SubscribePulsesRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribePulsesRequest) ProtoMessage()    {}
+func (*SubscribePulsesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{19}
+}
+
+func (m *SubscribePulsesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubscribePulsesRequest.Unmarshal(m, b)
+}
+func (m *SubscribePulsesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubscribePulsesRequest.Marshal(b, m, deterministic)
+}
+func (m *SubscribePulsesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribePulsesRequest.Merge(m, src)
+}
+func (m *SubscribePulsesRequest) XXX_Size() int {
+	return xxx_messageInfo_SubscribePulsesRequest.Size(m)
+}
+func (m *SubscribePulsesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribePulsesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubscribePulsesRequest proto.InternalMessageInfo
+
+func (m *SubscribePulsesRequest) GetFromEpoch() uint64 {
+	if m != nil {
+		return m.FromEpoch
+	}
+	return 0
+}
+
+type SnapshotRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CommitPulseRequest) Reset()         { *m = CommitPulseRequest{} }
-func (m *CommitPulseRequest) String() string { return proto.CompactTextString(m) }
-func (*CommitPulseRequest) ProtoMessage()    {}
-func (*CommitPulseRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63585974d4c6a2c4, []int{2}
+func (m *SnapshotRequest) Reset()         { *m = SnapshotRequest{} }
+func (m *SnapshotRequest) String() string { return proto.CompactTextString(m) }
+func (*SnapshotRequest) ProtoMessage()    {}
+func (*SnapshotRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{20}
 }
 
-func (m *CommitPulseRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CommitPulseRequest.Unmarshal(m, b)
+func (m *SnapshotRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotRequest.Unmarshal(m, b)
 }
-func (m *CommitPulseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CommitPulseRequest.Marshal(b, m, deterministic)
+func (m *SnapshotRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotRequest.Marshal(b, m, deterministic)
 }
-func (m *CommitPulseRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CommitPulseRequest.Merge(m, src)
+func (m *SnapshotRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotRequest.Merge(m, src)
 }
-func (m *CommitPulseRequest) XXX_Size() int {
-	return xxx_messageInfo_CommitPulseRequest.Size(m)
+func (m *SnapshotRequest) XXX_Size() int {
+	return xxx_messageInfo_SnapshotRequest.Size(m)
 }
-func (m *CommitPulseRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_CommitPulseRequest.DiscardUnknown(m)
+func (m *SnapshotRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CommitPulseRequest proto.InternalMessageInfo
+var xxx_messageInfo_SnapshotRequest proto.InternalMessageInfo
 
-func (m *CommitPulseRequest) GetEntropy() []byte {
+// SnapshotChunk part of badger backup of ledger db taken at one read timestamp, chunks are streamed in order
+type SnapshotChunk struct {
+	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()    {}
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{21}
+}
+
+func (m *SnapshotChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotChunk.Unmarshal(m, b)
+}
+func (m *SnapshotChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotChunk.Marshal(b, m, deterministic)
+}
+func (m *SnapshotChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotChunk.Merge(m, src)
+}
+func (m *SnapshotChunk) XXX_Size() int {
+	return xxx_messageInfo_SnapshotChunk.Size(m)
+}
+func (m *SnapshotChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotChunk proto.InternalMessageInfo
+
+func (m *SnapshotChunk) GetData() []byte {
 	if m != nil {
-		return m.Entropy
+		return m.Data
 	}
 	return nil
 }
 
-type CommitPulseResponse struct {
-	Error                string   `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+// Anchor marks blocks between the previous retained block and checkpoint block as pruned,
+// it's written by ledger itself, so it isn't proof of pruned blocks
+type Anchor struct {
+	// epoch of checkpoint block
+	Epoch uint64 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	// from epoch of the previous retained block, 0 if blocks are pruned from genesis
+	From     uint64 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+	FromHash []byte `protobuf:"bytes,3,opt,name=from_hash,json=fromHash,proto3" json:"from_hash,omitempty"`
+	// prev_hash hash of the last pruned block checkpoint block is linked to
+	PrevHash             []byte   `protobuf:"bytes,4,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *CommitPulseResponse) Reset()         { *m = CommitPulseResponse{} }
-func (m *CommitPulseResponse) String() string { return proto.CompactTextString(m) }
-func (*CommitPulseResponse) ProtoMessage()    {}
-func (*CommitPulseResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_63585974d4c6a2c4, []int{3}
+func (m *Anchor) Reset()         { *m = Anchor{} }
+func (m *Anchor) String() string { return proto.CompactTextString(m) }
+func (*Anchor) ProtoMessage()    {}
+func (*Anchor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_63585974d4c6a2c4, []int{22}
 }
 
-func (m *CommitPulseResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_CommitPulseResponse.Unmarshal(m, b)
+func (m *Anchor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Anchor.Unmarshal(m, b)
 }
-func (m *CommitPulseResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_CommitPulseResponse.Marshal(b, m, deterministic)
+func (m *Anchor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Anchor.Marshal(b, m, deterministic)
 }
-func (m *CommitPulseResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_CommitPulseResponse.Merge(m, src)
+func (m *Anchor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Anchor.Merge(m, src)
 }
-func (m *CommitPulseResponse) XXX_Size() int {
-	return xxx_messageInfo_CommitPulseResponse.Size(m)
+func (m *Anchor) XXX_Size() int {
+	return xxx_messageInfo_Anchor.Size(m)
 }
-func (m *CommitPulseResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_CommitPulseResponse.DiscardUnknown(m)
+func (m *Anchor) XXX_DiscardUnknown() {
+	xxx_messageInfo_Anchor.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_CommitPulseResponse proto.InternalMessageInfo
+var xxx_messageInfo_Anchor proto.InternalMessageInfo
 
-func (m *CommitPulseResponse) GetError() string {
+func (m *Anchor) GetEpoch() uint64 {
 	if m != nil {
-		return m.Error
+		return m.Epoch
 	}
-	return ""
+	return 0
+}
+
+func (m *Anchor) GetFrom() uint64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *Anchor) GetFromHash() []byte {
+	if m != nil {
+		return m.FromHash
+	}
+	return nil
+}
+
+func (m *Anchor) GetPrevHash() []byte {
+	if m != nil {
+		return m.PrevHash
+	}
+	return nil
 }
 
 func init() {
 	proto.RegisterType((*LatestPNRequest)(nil), "ledger.LatestPNRequest")
 	proto.RegisterType((*LatestPNResponse)(nil), "ledger.LatestPNResponse")
+	proto.RegisterType((*Confirmation)(nil), "ledger.Confirmation")
+	proto.RegisterType((*QuorumCert)(nil), "ledger.QuorumCert")
+	proto.RegisterType((*VrfProof)(nil), "ledger.VrfProof")
+	proto.RegisterType((*Block)(nil), "ledger.Block")
 	proto.RegisterType((*CommitPulseRequest)(nil), "ledger.CommitPulseRequest")
 	proto.RegisterType((*CommitPulseResponse)(nil), "ledger.CommitPulseResponse")
+	proto.RegisterType((*SignedMessage)(nil), "ledger.SignedMessage")
+	proto.RegisterType((*Evidence)(nil), "ledger.Evidence")
+	proto.RegisterType((*CommitEvidenceRequest)(nil), "ledger.CommitEvidenceRequest")
+	proto.RegisterType((*CommitEvidenceResponse)(nil), "ledger.CommitEvidenceResponse")
+	proto.RegisterType((*GetEvidenceRequest)(nil), "ledger.GetEvidenceRequest")
+	proto.RegisterType((*GetEvidenceResponse)(nil), "ledger.GetEvidenceResponse")
+	proto.RegisterType((*GetBlockRequest)(nil), "ledger.GetBlockRequest")
+	proto.RegisterType((*GetBlockResponse)(nil), "ledger.GetBlockResponse")
+	proto.RegisterType((*GetBlockRangeRequest)(nil), "ledger.GetBlockRangeRequest")
+	proto.RegisterType((*GetBlockRangeResponse)(nil), "ledger.GetBlockRangeResponse")
+	proto.RegisterType((*GetLatestBlockRequest)(nil), "ledger.GetLatestBlockRequest")
+	proto.RegisterType((*SubscribePulsesRequest)(nil), "ledger.SubscribePulsesRequest")
+	proto.RegisterType((*SnapshotRequest)(nil), "ledger.SnapshotRequest")
+	proto.RegisterType((*SnapshotChunk)(nil), "ledger.SnapshotChunk")
+	proto.RegisterType((*Anchor)(nil), "ledger.Anchor")
 }
 
 func init() { proto.RegisterFile("ledger.proto", fileDescriptor_63585974d4c6a2c4) }
 
 var fileDescriptor_63585974d4c6a2c4 = []byte{
-	// 245 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x51, 0xc1, 0x4a, 0x03, 0x31,
-	0x10, 0x35, 0xa2, 0x11, 0xc7, 0x82, 0x9a, 0x0a, 0x0d, 0xeb, 0xa5, 0xe4, 0xb4, 0x20, 0xe4, 0xa0,
-	0x77, 0x0f, 0x5b, 0x44, 0x90, 0x22, 0x21, 0x7f, 0x50, 0xd7, 0xa1, 0x16, 0xb3, 0x9d, 0x98, 0xa4,
-	0xa0, 0x9f, 0xe2, 0xdf, 0x8a, 0xc9, 0x2e, 0x56, 0xeb, 0xf1, 0xcd, 0xbc, 0x79, 0x6f, 0xde, 0x0c,
-	0x8c, 0x1c, 0x3e, 0x2f, 0x31, 0x68, 0x1f, 0x28, 0x91, 0xe0, 0x05, 0xa9, 0x73, 0x38, 0x9d, 0x2f,
-	0x12, 0xc6, 0x64, 0x1e, 0x2d, 0xbe, 0x6d, 0x30, 0x26, 0x75, 0x0b, 0x67, 0x3f, 0xa5, 0xe8, 0x69,
-	0x1d, 0x51, 0x5c, 0xc0, 0x21, 0x7a, 0x6a, 0x5f, 0x24, 0x9b, 0xb2, 0xfa, 0xc0, 0x16, 0x90, 0xab,
-	0x21, 0x50, 0x90, 0xfb, 0x53, 0x56, 0x1f, 0xdb, 0x02, 0x94, 0x06, 0x31, 0xa3, 0xae, 0x5b, 0x25,
-	0xb3, 0x71, 0x11, 0x7b, 0x55, 0x21, 0xe1, 0x08, 0xd7, 0x29, 0x90, 0xff, 0xc8, 0xec, 0x91, 0x1d,
-	0xa0, 0xba, 0x82, 0xf1, 0x2f, 0xfe, 0x96, 0x65, 0x16, 0x67, 0x5b, 0xe2, 0xd7, 0x9f, 0x0c, 0xf8,
-	0x3c, 0xaf, 0x2e, 0x66, 0xc0, 0xcb, 0x9c, 0xa8, 0x74, 0x9f, 0x6d, 0xd7, 0xb7, 0xba, 0xfc, 0xb7,
-	0x57, 0x3c, 0xd4, 0x9e, 0x78, 0x80, 0xf1, 0x3d, 0xa6, 0x92, 0xb7, 0x71, 0xd4, 0xbe, 0xde, 0xe5,
-	0x64, 0x93, 0x61, 0xea, 0xcf, 0x71, 0x2a, 0xb9, 0xdb, 0x18, 0xb4, 0x9a, 0x1a, 0x26, 0x2b, 0xd2,
-	0xcb, 0xe0, 0x5b, 0x8d, 0xef, 0x8b, 0xce, 0x3b, 0x8c, 0x3d, 0xbb, 0x39, 0x29, 0x3b, 0x9b, 0xef,
-	0xdb, 0x1b, 0xf6, 0xc4, 0xf3, 0x13, 0x6e, 0xbe, 0x02, 0x00, 0x00, 0xff, 0xff, 0x55, 0xa6, 0x77,
-	0x19, 0x94, 0x01, 0x00, 0x00,
+	// 1034 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0xeb, 0x6e, 0xdb, 0xb6,
+	0x17, 0xaf, 0x2c, 0x4b, 0x91, 0x8e, 0xe3, 0x26, 0x7f, 0xe6, 0x26, 0x28, 0xcd, 0x1f, 0x01, 0x8b,
+	0x61, 0xc6, 0x2e, 0x41, 0x91, 0x0d, 0x18, 0xb0, 0x0f, 0x1d, 0x9a, 0x2c, 0x48, 0xb1, 0x5e, 0x90,
+	0x2a, 0xc0, 0x80, 0x7d, 0x0a, 0x14, 0x99, 0xb6, 0xb4, 0xda, 0xa2, 0x42, 0xd2, 0x59, 0xfb, 0x2a,
+	0xc3, 0x9e, 0x61, 0x8f, 0xb0, 0x47, 0xd9, 0xb3, 0x0c, 0x24, 0x45, 0xdd, 0xec, 0x18, 0xed, 0x37,
+	0x9d, 0x0b, 0xcf, 0xe5, 0xc7, 0xf3, 0xe3, 0x11, 0x6c, 0xce, 0xc8, 0x78, 0x4a, 0xd8, 0x49, 0xc1,
+	0xa8, 0xa0, 0xc8, 0xd5, 0x12, 0xfe, 0x1f, 0x6c, 0xbd, 0x8e, 0x05, 0xe1, 0xe2, 0xea, 0x6d, 0x44,
+	0xee, 0x16, 0x84, 0x0b, 0xfc, 0x1c, 0xb6, 0x6b, 0x15, 0x2f, 0x68, 0xce, 0x09, 0xda, 0x05, 0x87,
+	0x14, 0x34, 0x49, 0x03, 0xeb, 0xd8, 0x1a, 0xf5, 0x23, 0x2d, 0x28, 0x2d, 0x63, 0x94, 0x05, 0xbd,
+	0x63, 0x6b, 0xe4, 0x47, 0x5a, 0xc0, 0xbf, 0xc3, 0xe6, 0x39, 0xcd, 0x27, 0x19, 0x9b, 0xc7, 0x22,
+	0xa3, 0x39, 0x42, 0xd0, 0x9f, 0x30, 0x3a, 0x57, 0x47, 0xfd, 0x48, 0x7d, 0xa3, 0x27, 0xe0, 0xf3,
+	0x6c, 0x9a, 0xc7, 0x62, 0xc1, 0x88, 0x3a, 0xbd, 0x19, 0xd5, 0x0a, 0x19, 0x97, 0xa7, 0x31, 0x23,
+	0x81, 0xad, 0x2c, 0x5a, 0x90, 0x71, 0xee, 0x33, 0xf2, 0x47, 0xd0, 0x57, 0x25, 0xa8, 0x6f, 0xfc,
+	0xb7, 0x05, 0xf0, 0x6e, 0x41, 0xd9, 0x62, 0x7e, 0x4e, 0x98, 0x40, 0xfb, 0xe0, 0xa6, 0x24, 0x9b,
+	0xa6, 0xa2, 0xac, 0xb3, 0x94, 0xea, 0xf2, 0x7b, 0xcd, 0xf2, 0xb7, 0xc1, 0x66, 0x5c, 0xa8, 0x24,
+	0x76, 0x24, 0x3f, 0x51, 0x00, 0x1b, 0x24, 0x17, 0x8c, 0x16, 0x1f, 0x55, 0x16, 0x3f, 0x32, 0x22,
+	0xfa, 0x0a, 0x9c, 0x7b, 0x2a, 0x08, 0x0f, 0x9c, 0x63, 0x7b, 0x34, 0x38, 0xdd, 0x3d, 0x29, 0xd1,
+	0x6c, 0x76, 0x1a, 0x69, 0x17, 0x14, 0x82, 0x57, 0x30, 0x5a, 0x50, 0x4e, 0x58, 0xe0, 0xaa, 0x30,
+	0x95, 0x8c, 0xbf, 0x07, 0xef, 0x57, 0x36, 0xb9, 0x62, 0x94, 0x4e, 0x56, 0x02, 0xb3, 0x0b, 0x4e,
+	0x21, 0x8d, 0x25, 0x28, 0x5a, 0xc0, 0xff, 0xf6, 0xc0, 0x39, 0x9b, 0xd1, 0xe4, 0xfd, 0x03, 0x17,
+	0x71, 0x08, 0x7e, 0xc1, 0xc8, 0xfd, 0x4d, 0x1a, 0xf3, 0xb4, 0x3c, 0xe9, 0x49, 0xc5, 0xcb, 0x98,
+	0xa7, 0x32, 0x8d, 0xd2, 0x6b, 0x30, 0xd5, 0xb7, 0xc4, 0x5f, 0x64, 0x73, 0xc2, 0x45, 0x3c, 0x2f,
+	0x54, 0xab, 0x76, 0x54, 0x2b, 0x0c, 0x30, 0xce, 0x4a, 0x60, 0x5c, 0x15, 0xc6, 0x88, 0x32, 0x92,
+	0x69, 0x8e, 0x07, 0x1b, 0xc7, 0xf6, 0xc8, 0x8f, 0x6a, 0x05, 0xfa, 0x11, 0x86, 0x49, 0x03, 0x21,
+	0x1e, 0x78, 0x6b, 0xe0, 0x6b, 0xbb, 0x22, 0x0c, 0xbd, 0xbb, 0x24, 0xf0, 0x8f, 0xad, 0xd1, 0xe0,
+	0x14, 0x99, 0x03, 0xf5, 0x65, 0x47, 0xbd, 0xbb, 0x04, 0x8d, 0xc0, 0x55, 0x08, 0xf1, 0x00, 0x54,
+	0xe0, 0x6d, 0xe3, 0x67, 0x40, 0x8e, 0x4a, 0x7b, 0x7b, 0xe2, 0x06, 0x9d, 0x89, 0xc3, 0x7f, 0xf5,
+	0x00, 0x9d, 0xd3, 0xf9, 0x3c, 0x13, 0x57, 0x8b, 0x19, 0x27, 0x25, 0x15, 0x9a, 0x6d, 0xf7, 0xda,
+	0x6d, 0x2f, 0x35, 0x66, 0x7f, 0x6e, 0x63, 0xfd, 0x4f, 0x6c, 0xcc, 0xf9, 0x9c, 0xc6, 0xdc, 0x2e,
+	0x95, 0xca, 0xab, 0xdc, 0xa8, 0xaf, 0xb2, 0x75, 0x61, 0x5e, 0xf7, 0xc2, 0xaa, 0xf9, 0xf2, 0x1b,
+	0xf3, 0x85, 0x67, 0xb0, 0xd3, 0x42, 0xa7, 0xf1, 0x2a, 0x28, 0xfe, 0x5b, 0x0d, 0xfe, 0xa3, 0xa7,
+	0xe0, 0xdc, 0xca, 0x59, 0x55, 0x90, 0x0d, 0x4e, 0x87, 0xa6, 0x72, 0x35, 0xc0, 0x91, 0xb6, 0x49,
+	0x8e, 0x48, 0x50, 0x66, 0x59, 0xa2, 0x09, 0xe8, 0x45, 0x95, 0x8c, 0x5f, 0xc0, 0xf0, 0x3a, 0x9b,
+	0xe6, 0x64, 0xfc, 0x86, 0x70, 0x1e, 0x4f, 0x15, 0xf3, 0xc7, 0xb1, 0x88, 0x55, 0x9a, 0xcd, 0x48,
+	0x7d, 0xaf, 0x7f, 0x41, 0xf0, 0x3f, 0x16, 0x78, 0x17, 0xf7, 0xd9, 0x98, 0xe4, 0x09, 0x91, 0xb9,
+	0xe8, 0x64, 0x42, 0xf2, 0x31, 0x31, 0x95, 0x56, 0xb2, 0x0c, 0x2d, 0x3e, 0x16, 0x3a, 0x82, 0x13,
+	0xa9, 0xef, 0x1a, 0x03, 0x7b, 0xc5, 0x6b, 0xd1, 0xaf, 0x91, 0xfc, 0x1a, 0x9c, 0x49, 0x66, 0x88,
+	0x32, 0x38, 0xdd, 0x33, 0x8d, 0xb6, 0x8a, 0x8f, 0xb4, 0x0f, 0xfa, 0x16, 0x5c, 0x4e, 0x12, 0x9a,
+	0x8f, 0x03, 0x77, 0x9d, 0x77, 0xe9, 0x84, 0x2f, 0x60, 0x4f, 0x23, 0x6e, 0xba, 0x30, 0x23, 0xf9,
+	0x0d, 0x78, 0xa4, 0x54, 0xa9, 0x66, 0x1a, 0xa3, 0x51, 0xb9, 0x56, 0x1e, 0xf8, 0x04, 0xf6, 0xbb,
+	0x61, 0xd6, 0xdd, 0x1d, 0x7e, 0x06, 0xe8, 0x92, 0x2c, 0xe5, 0x5c, 0x03, 0x20, 0xfe, 0x0d, 0x76,
+	0x2e, 0xc9, 0x72, 0xf8, 0x76, 0x99, 0xf6, 0xfa, 0x32, 0x1f, 0x58, 0x24, 0x5f, 0xc2, 0xd6, 0x25,
+	0x11, 0x7a, 0x6c, 0xca, 0x4a, 0x56, 0x3e, 0x7f, 0xf8, 0x0d, 0x6c, 0xd7, 0x8e, 0x65, 0x01, 0xd5,
+	0x14, 0x5a, 0x6b, 0xa6, 0x70, 0x75, 0xde, 0x57, 0xb0, 0x5b, 0x85, 0x8b, 0xf3, 0x69, 0x05, 0xc3,
+	0x11, 0x80, 0x7c, 0xa3, 0x6f, 0x9a, 0x15, 0xf8, 0x52, 0x73, 0x61, 0xb6, 0xe1, 0x2c, 0x9b, 0x67,
+	0x42, 0x05, 0x1b, 0x46, 0x5a, 0xc0, 0x1c, 0xf6, 0x3a, 0xc1, 0xca, 0x02, 0xbf, 0x00, 0x57, 0x15,
+	0xc1, 0x4b, 0x7c, 0x3a, 0x15, 0x96, 0x46, 0x99, 0x34, 0x27, 0x1f, 0xc4, 0x4d, 0x73, 0x7f, 0xf9,
+	0x52, 0x73, 0xd1, 0x5e, 0xc1, 0x76, 0xb3, 0x83, 0x03, 0x95, 0x54, 0x6f, 0xf1, 0x26, 0x7e, 0xf8,
+	0x07, 0xd8, 0xbf, 0x5e, 0xdc, 0xf2, 0x84, 0x65, 0xb7, 0x44, 0x71, 0x99, 0x7f, 0x5a, 0x73, 0xf2,
+	0x3f, 0xe1, 0x3a, 0x8f, 0x0b, 0x9e, 0x52, 0x61, 0x62, 0x3d, 0x85, 0xa1, 0x51, 0x9d, 0xa7, 0x8b,
+	0xfc, 0xfd, 0x2a, 0x9a, 0xe2, 0x19, 0xb8, 0x2f, 0xf2, 0x24, 0xa5, 0xec, 0x81, 0xcd, 0x65, 0x76,
+	0xa0, 0x6e, 0x4c, 0x7d, 0xcb, 0x6d, 0xa6, 0x4a, 0x69, 0x6c, 0x2d, 0x4f, 0x2a, 0xd4, 0x36, 0x6b,
+	0xad, 0xba, 0x7e, 0x7b, 0xd5, 0x9d, 0xfe, 0xe9, 0x80, 0xfb, 0x5a, 0xa1, 0x88, 0xce, 0xc1, 0xd5,
+	0x93, 0x8f, 0xc2, 0xfa, 0x4d, 0xee, 0x3e, 0xf0, 0xe1, 0xe1, 0x4a, 0x9b, 0xbe, 0x21, 0xfc, 0x08,
+	0xfd, 0x02, 0x3b, 0x6d, 0x1c, 0x35, 0xe8, 0x07, 0xe6, 0x54, 0xe7, 0xd7, 0x29, 0x0c, 0x96, 0x0d,
+	0x55, 0xac, 0x77, 0xf0, 0xb8, 0x4d, 0x45, 0x74, 0xd4, 0x4e, 0xde, 0x61, 0x5d, 0xf8, 0xff, 0x87,
+	0xcc, 0x55, 0xc8, 0x97, 0x30, 0x68, 0x70, 0xaf, 0x6e, 0x74, 0x99, 0xc2, 0xe1, 0xe1, 0x4a, 0x5b,
+	0x15, 0xe9, 0x27, 0xf0, 0xcc, 0x94, 0xd6, 0xdd, 0x75, 0xc8, 0x17, 0x06, 0xcb, 0x86, 0x2a, 0xc0,
+	0x5b, 0x18, 0xb6, 0xc6, 0x1c, 0x3d, 0x59, 0x72, 0x6e, 0x50, 0x29, 0x3c, 0x7a, 0xc0, 0x5a, 0xc5,
+	0x7b, 0x05, 0x8f, 0xdb, 0xc8, 0xa3, 0xe6, 0x91, 0xe5, 0xc9, 0x5e, 0x5b, 0xdc, 0xcf, 0xb0, 0xd5,
+	0x99, 0x7a, 0x54, 0x81, 0xbb, 0x9a, 0x0e, 0x61, 0x9b, 0x8d, 0xf8, 0xd1, 0x33, 0x0b, 0x3d, 0x07,
+	0xcf, 0xcc, 0x7b, 0x8d, 0x51, 0x87, 0x14, 0xe1, 0x5e, 0xd7, 0xa0, 0xa8, 0x21, 0xcf, 0x9f, 0x8d,
+	0xe0, 0x20, 0xa3, 0x27, 0x53, 0x56, 0x24, 0x27, 0xe4, 0x43, 0x3c, 0x2f, 0x66, 0x84, 0x97, 0xce,
+	0x67, 0x03, 0x3d, 0xb4, 0x57, 0x8c, 0x0a, 0x7a, 0x65, 0xdd, 0xba, 0xea, 0x1f, 0xfd, 0xbb, 0xff,
+	0x06, 0x00, 0xda, 0x81, 0xc3, 0xa5, 0xb3, 0x0b, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -223,6 +1372,13 @@ const _ = grpc.SupportPackageIsVersion4
 type LedgerClient interface {
 	Commit(ctx context.Context, in *CommitPulseRequest, opts ...grpc.CallOption) (*CommitPulseResponse, error)
 	GetLatestBlockEpoch(ctx context.Context, in *LatestPNRequest, opts ...grpc.CallOption) (*LatestPNResponse, error)
+	CommitEvidence(ctx context.Context, in *CommitEvidenceRequest, opts ...grpc.CallOption) (*CommitEvidenceResponse, error)
+	GetEvidence(ctx context.Context, in *GetEvidenceRequest, opts ...grpc.CallOption) (*GetEvidenceResponse, error)
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error)
+	GetBlockRange(ctx context.Context, in *GetBlockRangeRequest, opts ...grpc.CallOption) (*GetBlockRangeResponse, error)
+	GetLatestBlock(ctx context.Context, in *GetLatestBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error)
+	SubscribePulses(ctx context.Context, in *SubscribePulsesRequest, opts ...grpc.CallOption) (Ledger_SubscribePulsesClient, error)
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (Ledger_SnapshotClient, error)
 }
 
 type ledgerClient struct {
@@ -251,10 +1407,126 @@ func (c *ledgerClient) GetLatestBlockEpoch(ctx context.Context, in *LatestPNRequ
 	return out, nil
 }
 
+func (c *ledgerClient) CommitEvidence(ctx context.Context, in *CommitEvidenceRequest, opts ...grpc.CallOption) (*CommitEvidenceResponse, error) {
+	out := new(CommitEvidenceResponse)
+	err := c.cc.Invoke(ctx, "/ledger.Ledger/CommitEvidence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetEvidence(ctx context.Context, in *GetEvidenceRequest, opts ...grpc.CallOption) (*GetEvidenceResponse, error) {
+	out := new(GetEvidenceResponse)
+	err := c.cc.Invoke(ctx, "/ledger.Ledger/GetEvidence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error) {
+	out := new(GetBlockResponse)
+	err := c.cc.Invoke(ctx, "/ledger.Ledger/GetBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetBlockRange(ctx context.Context, in *GetBlockRangeRequest, opts ...grpc.CallOption) (*GetBlockRangeResponse, error) {
+	out := new(GetBlockRangeResponse)
+	err := c.cc.Invoke(ctx, "/ledger.Ledger/GetBlockRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetLatestBlock(ctx context.Context, in *GetLatestBlockRequest, opts ...grpc.CallOption) (*GetBlockResponse, error) {
+	out := new(GetBlockResponse)
+	err := c.cc.Invoke(ctx, "/ledger.Ledger/GetLatestBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) SubscribePulses(ctx context.Context, in *SubscribePulsesRequest, opts ...grpc.CallOption) (Ledger_SubscribePulsesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ledger_serviceDesc.Streams[0], "/ledger.Ledger/SubscribePulses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ledgerSubscribePulsesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ledger_SubscribePulsesClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type ledgerSubscribePulsesClient struct {
+	grpc.ClientStream
+}
+
+func (x *ledgerSubscribePulsesClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ledgerClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (Ledger_SnapshotClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ledger_serviceDesc.Streams[1], "/ledger.Ledger/Snapshot", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ledgerSnapshotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ledger_SnapshotClient interface {
+	Recv() (*SnapshotChunk, error)
+	grpc.ClientStream
+}
+
+type ledgerSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *ledgerSnapshotClient) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // LedgerServer is the server API for Ledger service.
 type LedgerServer interface {
 	Commit(context.Context, *CommitPulseRequest) (*CommitPulseResponse, error)
 	GetLatestBlockEpoch(context.Context, *LatestPNRequest) (*LatestPNResponse, error)
+	CommitEvidence(context.Context, *CommitEvidenceRequest) (*CommitEvidenceResponse, error)
+	GetEvidence(context.Context, *GetEvidenceRequest) (*GetEvidenceResponse, error)
+	GetBlock(context.Context, *GetBlockRequest) (*GetBlockResponse, error)
+	GetBlockRange(context.Context, *GetBlockRangeRequest) (*GetBlockRangeResponse, error)
+	GetLatestBlock(context.Context, *GetLatestBlockRequest) (*GetBlockResponse, error)
+	SubscribePulses(*SubscribePulsesRequest, Ledger_SubscribePulsesServer) error
+	Snapshot(*SnapshotRequest, Ledger_SnapshotServer) error
 }
 
 // UnimplementedLedgerServer can be embedded to have forward compatible implementations.
@@ -267,6 +1539,27 @@ func (*UnimplementedLedgerServer) Commit(ctx context.Context, req *CommitPulseRe
 func (*UnimplementedLedgerServer) GetLatestBlockEpoch(ctx context.Context, req *LatestPNRequest) (*LatestPNResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLatestBlockEpoch not implemented")
 }
+func (*UnimplementedLedgerServer) CommitEvidence(ctx context.Context, req *CommitEvidenceRequest) (*CommitEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitEvidence not implemented")
+}
+func (*UnimplementedLedgerServer) GetEvidence(ctx context.Context, req *GetEvidenceRequest) (*GetEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvidence not implemented")
+}
+func (*UnimplementedLedgerServer) GetBlock(ctx context.Context, req *GetBlockRequest) (*GetBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (*UnimplementedLedgerServer) GetBlockRange(ctx context.Context, req *GetBlockRangeRequest) (*GetBlockRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockRange not implemented")
+}
+func (*UnimplementedLedgerServer) GetLatestBlock(ctx context.Context, req *GetLatestBlockRequest) (*GetBlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestBlock not implemented")
+}
+func (*UnimplementedLedgerServer) SubscribePulses(req *SubscribePulsesRequest, srv Ledger_SubscribePulsesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePulses not implemented")
+}
+func (*UnimplementedLedgerServer) Snapshot(req *SnapshotRequest, srv Ledger_SnapshotServer) error {
+	return status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
 
 func RegisterLedgerServer(s *grpc.Server, srv LedgerServer) {
 	s.RegisterService(&_Ledger_serviceDesc, srv)
@@ -308,6 +1601,138 @@ func _Ledger_GetLatestBlockEpoch_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Ledger_CommitEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).CommitEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.Ledger/CommitEvidence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).CommitEvidence(ctx, req.(*CommitEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.Ledger/GetEvidence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetEvidence(ctx, req.(*GetEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.Ledger/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetBlockRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetBlockRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.Ledger/GetBlockRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetBlockRange(ctx, req.(*GetBlockRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetLatestBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetLatestBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/ledger.Ledger/GetLatestBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetLatestBlock(ctx, req.(*GetLatestBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_SubscribePulses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePulsesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LedgerServer).SubscribePulses(m, &ledgerSubscribePulsesServer{stream})
+}
+
+type Ledger_SubscribePulsesServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type ledgerSubscribePulsesServer struct {
+	grpc.ServerStream
+}
+
+func (x *ledgerSubscribePulsesServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Ledger_Snapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SnapshotRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LedgerServer).Snapshot(m, &ledgerSnapshotServer{stream})
+}
+
+type Ledger_SnapshotServer interface {
+	Send(*SnapshotChunk) error
+	grpc.ServerStream
+}
+
+type ledgerSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *ledgerSnapshotServer) Send(m *SnapshotChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Ledger_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "ledger.Ledger",
 	HandlerType: (*LedgerServer)(nil),
@@ -320,7 +1745,38 @@ var _Ledger_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetLatestBlockEpoch",
 			Handler:    _Ledger_GetLatestBlockEpoch_Handler,
 		},
+		{
+			MethodName: "CommitEvidence",
+			Handler:    _Ledger_CommitEvidence_Handler,
+		},
+		{
+			MethodName: "GetEvidence",
+			Handler:    _Ledger_GetEvidence_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Ledger_GetBlock_Handler,
+		},
+		{
+			MethodName: "GetBlockRange",
+			Handler:    _Ledger_GetBlockRange_Handler,
+		},
+		{
+			MethodName: "GetLatestBlock",
+			Handler:    _Ledger_GetLatestBlock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePulses",
+			Handler:       _Ledger_SubscribePulses_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Snapshot",
+			Handler:       _Ledger_Snapshot_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "ledger.proto",
 }
@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	pb "rounds/ledger/pb"
+	"rounds/logger"
+	"rounds/node"
+	"sort"
+	"sync"
+)
+
+// ErrNoQuorum ledgers don't agree on block
+var ErrNoQuorum = errors.New("no quorum of ledgers agree on block")
+
+// QuorumClient reads blocks from ledgers of nodes, block is returned only if f+1 ledgers agree on it,
+// so at least one honest node has committed it
+type QuorumClient struct {
+	conns   []*grpc.ClientConn
+	clients []pb.LedgerClient
+	quorum  int
+
+	log *logger.Logger
+}
+
+// NewQuorumClient connects to ledgers of cluster which tolerates faulty nodes, unavailable ledgers are skipped on reads,
+// client must be closed when it's not used anymore
+func NewQuorumClient(addrs []string, faulty int) (*QuorumClient, error) {
+	m := &QuorumClient{
+		conns:   make([]*grpc.ClientConn, 0, len(addrs)),
+		clients: make([]pb.LedgerClient, 0, len(addrs)),
+		quorum:  faulty + 1,
+		log:     logger.NewLogger(),
+	}
+	for _, addr := range addrs {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m.conns = append(m.conns, conn)
+		m.clients = append(m.clients, pb.NewLedgerClient(conn))
+	}
+	return m, nil
+}
+
+// Close closes connections to ledgers
+func (m *QuorumClient) Close() error {
+	var err error
+	for _, conn := range m.conns {
+		if e := conn.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// GetBlock gets block of epoch f+1 ledgers agree on, nil if f+1 ledgers don't have it
+func (m *QuorumClient) GetBlock(ctx context.Context, epoch uint64) (*node.Block, error) {
+	return m.agree(func(c pb.LedgerClient) (*pb.GetBlockResponse, error) {
+		return c.GetBlock(ctx, &pb.GetBlockRequest{Epoch: epoch})
+	})
+}
+
+// GetLatestBlock gets block of the highest epoch at least f+1 ledgers have reached
+func (m *QuorumClient) GetLatestBlock(ctx context.Context) (*node.Block, error) {
+	var mu sync.Mutex
+	epochs := make([]uint64, 0, len(m.clients))
+	m.each(func(c pb.LedgerClient) {
+		resp, err := c.GetLatestBlockEpoch(ctx, &pb.LatestPNRequest{})
+		if err != nil || resp.Error != "" {
+			m.log.Infof("ledger is unavailable: %v %s", err, resp.GetError())
+			return
+		}
+		mu.Lock()
+		epochs = append(epochs, resp.Epoch)
+		mu.Unlock()
+	})
+	if len(epochs) < m.quorum {
+		return nil, ErrNoQuorum
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] > epochs[j] })
+	epoch := epochs[m.quorum-1]
+	if epoch == 0 {
+		return nil, nil
+	}
+	return m.GetBlock(ctx, epoch)
+}
+
+// agree requests block from every ledger, returns block most ledgers agree on if they are at least f+1
+func (m *QuorumClient) agree(get func(c pb.LedgerClient) (*pb.GetBlockResponse, error)) (*node.Block, error) {
+	var mu sync.Mutex
+	votes := make(map[string][]*node.Block)
+	missing := 0
+	m.each(func(c pb.LedgerClient) {
+		resp, err := get(c)
+		if err != nil || resp.Error != "" {
+			m.log.Infof("ledger is unavailable: %v %s", err, resp.GetError())
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if resp.Block == nil {
+			missing++
+			return
+		}
+		b := node.BlockFromPb(resp.Block)
+		key := string(b.AgreedHash())
+		votes[key] = append(votes[key], b)
+	})
+	var agreed []*node.Block
+	tie := false
+	for _, blocks := range votes {
+		switch {
+		case len(blocks) > len(agreed):
+			agreed, tie = blocks, false
+		case len(blocks) == len(agreed):
+			tie = true
+		}
+	}
+	if len(agreed) >= m.quorum && !tie {
+		return agreed[0], nil
+	}
+	if missing >= m.quorum && len(agreed) < m.quorum {
+		return nil, nil
+	}
+	return nil, ErrNoQuorum
+}
+
+// each calls f for every ledger concurrently
+func (m *QuorumClient) each(f func(c pb.LedgerClient)) {
+	var wg sync.WaitGroup
+	for _, c := range m.clients {
+		wg.Add(1)
+		go func(c pb.LedgerClient) {
+			defer wg.Done()
+			f(c)
+		}(c)
+	}
+	wg.Wait()
+}
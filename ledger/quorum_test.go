@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"net"
+	pb "rounds/ledger/pb"
+	"rounds/node"
+	"testing"
+)
+
+// testLedgers starts ledgers with memory stores on free ports
+func testLedgers(t *testing.T, count int) ([]*MemoryStore, []string, func()) {
+	stores := make([]*MemoryStore, 0, count)
+	addrs := make([]string, 0, count)
+	servers := make([]*grpc.Server, 0, count)
+	for i := 0; i < count; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		store := NewMemoryStore()
+		srv := grpc.NewServer()
+		pb.RegisterLedgerServer(srv, newServer(store, nil))
+		go srv.Serve(lis)
+		stores = append(stores, store)
+		addrs = append(addrs, lis.Addr().String())
+		servers = append(servers, srv)
+	}
+	return stores, addrs, func() {
+		for _, srv := range servers {
+			srv.Stop()
+		}
+	}
+}
+
+// commitEntropy appends block of entropy in the round of the next epoch
+func commitEntropy(t *testing.T, s Storer, timestamp int64, entropy string) {
+	latest, err := s.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	_, err = s.CommitPulse(&node.Block{Timestamp: timestamp, Rst: int64(1000 + latest + 1), WinnerEntropy: []byte(entropy)})
+	require.NoError(t, err)
+}
+
+func TestQuorumClientReadsAgreedBlocks(t *testing.T) {
+	stores, addrs, done := testLedgers(t, 4)
+	defer done()
+	c, err := NewQuorumClient(addrs, 1)
+	require.NoError(t, err)
+	defer c.Close()
+	ctx := context.Background()
+
+	latest, err := c.GetLatestBlock(ctx)
+	require.NoError(t, err)
+	require.Nil(t, latest)
+
+	// ledgers agree on entropy, but timestamps and so hashes differ, one ledger is faulty
+	for i, s := range stores[:3] {
+		commitEntropy(t, s, int64(i), "agreed")
+	}
+	commitEntropy(t, stores[3], 0, "forged")
+	b, err := c.GetBlock(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("agreed"), b.WinnerEntropy)
+
+	// block committed by one ledger only isn't returned
+	commitEntropy(t, stores[3], 0, "forged")
+	b, err = c.GetBlock(ctx, 2)
+	require.NoError(t, err)
+	require.Nil(t, b)
+	latest, err = c.GetLatestBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), latest.Epoch)
+
+	// ledgers split in halves don't make quorum
+	commitEntropy(t, stores[0], 0, "one")
+	commitEntropy(t, stores[1], 0, "one")
+	commitEntropy(t, stores[2], 0, "forged")
+	_, err = c.GetBlock(ctx, 2)
+	require.Equal(t, ErrNoQuorum, err)
+
+	// unavailable ledger is skipped
+	commitEntropy(t, stores[2], 0, "agreed")
+	commitEntropy(t, stores[3], 0, "agreed")
+	c, err = NewQuorumClient(append(addrs[2:], "127.0.0.1:1"), 1)
+	require.NoError(t, err)
+	defer c.Close()
+	latest, err = c.GetLatestBlock(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), latest.Epoch)
+	require.Equal(t, []byte("agreed"), latest.WinnerEntropy)
+}
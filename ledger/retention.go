@@ -0,0 +1,284 @@
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/dgraph-io/badger"
+	"github.com/golang/protobuf/proto"
+	pb "rounds/ledger/pb"
+	"rounds/node"
+	"time"
+)
+
+const (
+	// defaultPruneInterval interval between prunings if it's not configured
+	defaultPruneInterval = time.Minute
+	// pruneBatch max blocks deleted in one transaction
+	pruneBatch = 1000
+	// gcDiscardRatio value log file is rewritten if at least this ratio of it is stale
+	gcDiscardRatio = 0.5
+)
+
+// prunedEpochKey checkpoint chain is contiguous from, blocks before it are pruned but checkpoints
+var prunedEpochKey = metaKey("pruned_epoch")
+
+// Retention blocks ledger keeps, blocks retained by epochs or duration are kept,
+// all blocks are kept if neither is set, the latest block is never pruned
+type Retention struct {
+	// Epochs keeps blocks of the last epochs
+	Epochs uint64
+	// Duration keeps blocks committed within duration, e.g. 720h
+	Duration time.Duration
+	// Checkpoint every Checkpoint-th epoch block is kept with anchor marking blocks before it as pruned,
+	// the first retained block is the only checkpoint if not set
+	Checkpoint uint64
+	// Interval between prunings, a minute if not set
+	Interval time.Duration
+}
+
+func (r Retention) enabled() bool {
+	return r.Epochs != 0 || r.Duration != 0
+}
+
+// nextCheckpoint the first checkpoint after from up to epoch, 0 if there is none
+func (r Retention) nextCheckpoint(from uint64, upTo uint64) uint64 {
+	next := upTo
+	if r.Checkpoint != 0 {
+		next = (from/r.Checkpoint + 1) * r.Checkpoint
+	}
+	if next <= from || next > upTo {
+		return 0
+	}
+	return next
+}
+
+// Prune deletes blocks out of retention but checkpoints, chain is kept contiguous from the latest checkpoint
+// out of retention, anchor of every checkpoint marks pruned range before it, returns number of deleted blocks
+func (m *BadgerStore) Prune(r Retention, now time.Time) (int, error) {
+	if !r.enabled() {
+		return 0, nil
+	}
+	from, err := m.prunedEpoch()
+	if err != nil {
+		return 0, err
+	}
+	retained, err := m.retainedFrom(r, from, now)
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for checkpoint := r.nextCheckpoint(from, retained); checkpoint != 0; checkpoint = r.nextCheckpoint(from, retained) {
+		n, err := m.pruneBefore(from, checkpoint)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+		from = checkpoint
+	}
+	if deleted > 0 {
+		m.log.Infof("pruned blocks: %d, chain is retained from epoch %d", deleted, from)
+	}
+	return deleted, nil
+}
+
+// retainedFrom the first epoch retention keeps blocks from
+func (m *BadgerStore) retainedFrom(r Retention, from uint64, now time.Time) (uint64, error) {
+	latest, err := m.GetLatestBlockEpoch()
+	if err != nil || latest == 0 {
+		return 0, err
+	}
+	retained := latest
+	if r.Epochs != 0 && latest > r.Epochs {
+		retained = latest - r.Epochs + 1
+	} else if r.Epochs != 0 {
+		retained = 1
+	}
+	if r.Duration == 0 {
+		return retained, nil
+	}
+	since := now.Add(-r.Duration).Unix()
+	err = m.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(blockKey(from + 1)); it.ValidForPrefix(blockPrefix); it.Next() {
+			epoch := blockKeyEpoch(it.Item().Key())
+			if epoch >= retained {
+				return nil
+			}
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			b, err := decodeBlock(data)
+			if err != nil {
+				return err
+			}
+			if b.Timestamp >= since {
+				retained = epoch
+				return nil
+			}
+		}
+		return nil
+	})
+	return retained, err
+}
+
+// pruneBefore deletes blocks between retained block and checkpoint, anchor is written first,
+// so interrupted pruning is continued with the same anchor
+func (m *BadgerStore) pruneBefore(from uint64, checkpoint uint64) (int, error) {
+	if err := m.db.Update(func(txn *badger.Txn) error {
+		return m.anchor(txn, from, checkpoint)
+	}); err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for {
+		n := 0
+		err := m.db.Update(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchValues = false
+			it := txn.NewIterator(opts)
+			defer it.Close()
+			keys := make([][]byte, 0, pruneBatch)
+			for it.Seek(blockKey(from + 1)); it.ValidForPrefix(blockPrefix) && len(keys) < pruneBatch; it.Next() {
+				if blockKeyEpoch(it.Item().Key()) >= checkpoint {
+					break
+				}
+				keys = append(keys, it.Item().KeyCopy(nil))
+			}
+			for _, k := range keys {
+				if err := txn.Delete(k); err != nil {
+					return err
+				}
+			}
+			n = len(keys)
+			return nil
+		})
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+		if n < pruneBatch {
+			break
+		}
+	}
+	return deleted, m.db.Update(func(txn *badger.Txn) error {
+		var v [8]byte
+		binary.BigEndian.PutUint64(v[:], checkpoint)
+		return txn.Set(prunedEpochKey, v[:])
+	})
+}
+
+// anchor stores anchor of checkpoint marking blocks after retained block as pruned, if there are blocks between them,
+// hashes anchor keeps are copies of stored ones, so they tie marker to blocks around the range but don't authenticate it
+func (m *BadgerStore) anchor(txn *badger.Txn, from uint64, checkpoint uint64) error {
+	if checkpoint == from+1 {
+		return nil
+	}
+	existing, err := readAnchor(txn, checkpoint)
+	if err != nil || existing != nil {
+		return err
+	}
+	b, err := readBlock(txn, checkpoint)
+	if err != nil {
+		return err
+	}
+	prev, err := readBlock(txn, checkpoint-1)
+	if err != nil {
+		return err
+	}
+	if b == nil || prev == nil {
+		return node.ErrEpochMissing(checkpoint)
+	}
+	a := &pb.Anchor{Epoch: checkpoint, From: from, PrevHash: prev.Hash}
+	if from != 0 {
+		retained, err := readBlock(txn, from)
+		if err != nil {
+			return err
+		}
+		if retained == nil {
+			return node.ErrEpochMissing(from)
+		}
+		a.FromHash = retained.Hash
+	}
+	data, err := proto.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return txn.Set(anchorKey(checkpoint), data)
+}
+
+// readAnchor reads anchor of checkpoint, nil if blocks before checkpoint aren't pruned
+func readAnchor(txn *badger.Txn, epoch uint64) (*pb.Anchor, error) {
+	item, err := txn.Get(anchorKey(epoch))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	var a pb.Anchor
+	if err := proto.Unmarshal(data, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// prunedEpoch checkpoint chain is contiguous from, 0 if nothing is pruned
+func (m *BadgerStore) prunedEpoch() (uint64, error) {
+	var epoch uint64
+	err := m.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(prunedEpochKey)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v, err := item.Value()
+		if err != nil {
+			return err
+		}
+		if len(v) != 8 {
+			return fmt.Errorf("invalid pruned epoch record")
+		}
+		epoch = binary.BigEndian.Uint64(v)
+		return nil
+	})
+	return epoch, err
+}
+
+// collectGarbage rewrites value log files until there are no files with enough stale values
+func (m *BadgerStore) collectGarbage() {
+	for {
+		err := m.db.RunValueLogGC(gcDiscardRatio)
+		if err == badger.ErrNoRewrite {
+			return
+		}
+		if err != nil {
+			m.log.Errorf("value log gc failed: %v", err)
+			return
+		}
+	}
+}
+
+// pruneEvery prunes blocks out of retention and collects value log garbage on every interval
+func (m *BadgerStore) pruneEvery(r Retention) {
+	interval := r.Interval
+	if interval == 0 {
+		interval = defaultPruneInterval
+	}
+	for range time.Tick(interval) {
+		deleted, err := m.Prune(r, time.Now())
+		if err != nil {
+			m.log.Errorf("failed to prune blocks: %v", err)
+		}
+		if deleted > 0 {
+			m.collectGarbage()
+		}
+	}
+}
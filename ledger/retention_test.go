@@ -0,0 +1,92 @@
+package ledger
+
+import (
+	"crypto/ecdsa"
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/require"
+	"rounds/node"
+	"testing"
+	"time"
+)
+
+func TestPruneKeepsAnchoredCheckpoints(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	a := newTestSigner(t)
+	for epoch := uint64(1); epoch <= 25; epoch++ {
+		commitSigned(t, s, epoch, "entropy", a)
+	}
+	verifier := &ChainVerifier{Keys: map[string]*ecdsa.PublicKey{a.id: &a.priv.PublicKey}}
+	r := Retention{Epochs: 5, Checkpoint: 10}
+
+	deleted, err := s.Prune(r, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 18, deleted)
+	for _, epoch := range []uint64{1, 9, 11, 19} {
+		b, err := s.GetBlock(epoch)
+		require.NoError(t, err)
+		require.Nil(t, b)
+	}
+	for _, epoch := range []uint64{10, 20, 21, 25} {
+		b, err := s.GetBlock(epoch)
+		require.NoError(t, err)
+		require.NotNil(t, b)
+	}
+	issues, checked, err := s.VerifyChain(verifier)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	require.Equal(t, 7, checked)
+
+	deleted, err = s.Prune(r, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	for epoch := uint64(26); epoch <= 35; epoch++ {
+		commitSigned(t, s, epoch, "entropy", a)
+	}
+	deleted, err = s.Prune(r, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 9, deleted)
+	issues, _, err = s.VerifyChain(verifier)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	s.collectGarbage()
+
+	// gap without anchor is reported
+	require.NoError(t, s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(anchorKey(30))
+	}))
+	issues, _, err = s.VerifyChain(verifier)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.Equal(t, IssueGap, issues[0].Kind)
+}
+
+func TestPruneByDuration(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		entropy, err := node.EncodeEntropy("entropy")
+		require.NoError(t, err)
+		_, err = s.CommitPulse(&node.Block{Timestamp: start.Add(time.Duration(i) * time.Hour).Unix(), Rst: int64(1000 + i), WinnerEntropy: entropy})
+		require.NoError(t, err)
+	}
+
+	// blocks committed in the last 150 minutes are kept
+	deleted, err := s.Prune(Retention{Duration: 150 * time.Minute}, start.Add(5*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, deleted)
+	blocks, err := s.GetBlockRange(1, MaxBlockRange)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	require.Equal(t, uint64(4), blocks[0].Epoch)
+
+	// retention by epochs keeps more blocks
+	deleted, err = s.Prune(Retention{Epochs: 3, Duration: time.Minute}, start.Add(5*time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+	issues, _, err := s.VerifyChain(&ChainVerifier{})
+	require.NoError(t, err)
+	require.Empty(t, issues)
+}
@@ -0,0 +1,219 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/dgraph-io/badger"
+	"github.com/golang/protobuf/proto"
+	"log"
+	"math"
+	pb "rounds/ledger/pb"
+	"rounds/node"
+)
+
+// SchemaVersion version of key schema, databases of older versions are migrated when opened,
+// version 0 stored blocks by 64 byte epoch keys and evidence under "evidence/",
+// version 1 namespaces keys by prefix: blocks "b/", meta "m/", evidence "e/", anchors "a/" and contract state "c/"
+const SchemaVersion = 1
+
+// keys are namespaced by prefix
+var (
+	blockPrefix    = []byte("b/")
+	metaPrefix     = []byte("m/")
+	evidencePrefix = []byte("e/")
+	// anchorPrefix hash anchors of checkpoints blocks before them are pruned
+	anchorPrefix = []byte("a/")
+	// statePrefix reserved for contract state, so state keys never fall into block iteration
+	// and aren't taken for legacy records by migration
+	statePrefix = []byte("c/")
+
+	schemaVersionKey = metaKey("schema_version")
+)
+
+// prefixes every key of the current schema starts with
+var prefixes = [][]byte{blockPrefix, metaPrefix, evidencePrefix, anchorPrefix, statePrefix}
+
+const (
+	// legacyBlockKeyLen length of epoch keys before schema was versioned
+	legacyBlockKeyLen = 64
+)
+
+var legacyEvidencePrefix = []byte("evidence/")
+
+func prefixed(prefix []byte, key []byte) []byte {
+	k := make([]byte, 0, len(prefix)+len(key))
+	k = append(k, prefix...)
+	return append(k, key...)
+}
+
+func blockKey(epoch uint64) []byte {
+	var e [8]byte
+	binary.BigEndian.PutUint64(e[:], epoch)
+	return prefixed(blockPrefix, e[:])
+}
+
+// blockKeyEpoch epoch of block key
+func blockKeyEpoch(k []byte) uint64 {
+	return binary.BigEndian.Uint64(k[len(blockPrefix):])
+}
+
+// lastBlockKey key iteration in reverse starts from to find the latest block
+func lastBlockKey() []byte {
+	return blockKey(math.MaxUint64)
+}
+
+func anchorKey(epoch uint64) []byte {
+	var e [8]byte
+	binary.BigEndian.PutUint64(e[:], epoch)
+	return prefixed(anchorPrefix, e[:])
+}
+
+func metaKey(name string) []byte {
+	return prefixed(metaPrefix, []byte(name))
+}
+
+func evidenceKey(e *node.Evidence) []byte {
+	return prefixed(evidencePrefix, e.Key())
+}
+
+func stateKey(key []byte) []byte {
+	return prefixed(statePrefix, key)
+}
+
+// hasSchemaPrefix checks that key is namespaced by the current schema
+func hasSchemaPrefix(k []byte) bool {
+	for _, p := range prefixes {
+		if bytes.HasPrefix(k, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaVersion reads stored schema version, 0 if database predates versioning
+func schemaVersion(db *badger.DB) (uint64, error) {
+	var version uint64
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(schemaVersionKey)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v, err := item.Value()
+		if err != nil {
+			return err
+		}
+		if len(v) != 8 {
+			return fmt.Errorf("invalid schema version record")
+		}
+		version = binary.BigEndian.Uint64(v)
+		return nil
+	})
+	return version, err
+}
+
+func setSchemaVersion(txn *badger.Txn, version uint64) error {
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], version)
+	return txn.Set(schemaVersionKey, v[:])
+}
+
+// migrate upgrades database to the current schema version,
+// every record is moved in its own transaction, so interrupted migration is continued when database is opened again
+func migrate(db *badger.DB) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("schema version %d is newer than supported version %d", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+	log.Printf("migrating db schema from version %d to %d", version, SchemaVersion)
+	if err := migrateLegacy(db); err != nil {
+		return err
+	}
+	return db.Update(func(txn *badger.Txn) error {
+		return setSchemaVersion(txn, SchemaVersion)
+	})
+}
+
+// migrateLegacy moves blocks stored by 64 byte epoch keys and evidence to prefixed keys,
+// blocks stored as bare entropy are chained to the previous block
+func migrateLegacy(db *badger.DB) error {
+	var keys [][]byte
+	if err := db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			k := it.Item().Key()
+			if hasSchemaPrefix(k) {
+				continue
+			}
+			if bytes.HasPrefix(k, legacyEvidencePrefix) || len(k) == legacyBlockKeyLen {
+				keys = append(keys, it.Item().KeyCopy(nil))
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := db.Update(func(txn *badger.Txn) error {
+			return migrateKey(txn, k)
+		}); err != nil {
+			return fmt.Errorf("failed to migrate key %x: %v", k, err)
+		}
+	}
+	log.Printf("migrated %d records", len(keys))
+	return nil
+}
+
+func migrateKey(txn *badger.Txn, k []byte) error {
+	item, err := txn.Get(k)
+	if err != nil {
+		return err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(k, legacyEvidencePrefix) {
+		var e pb.Evidence
+		if err := proto.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		if err := txn.Set(evidenceKey(node.EvidenceFromPb(&e)), data); err != nil {
+			return err
+		}
+		return txn.Delete(k)
+	}
+	epoch := binary.BigEndian.Uint64(k)
+	b, err := decodeBlock(data)
+	if err != nil || b.Epoch != epoch || len(b.Hash) == 0 {
+		// block stored as bare entropy
+		prev, err := readBlock(txn, epoch-1)
+		if err != nil {
+			return err
+		}
+		b = &node.Block{WinnerEntropy: data}
+		b.Chain(prev)
+		b.Epoch = epoch
+		b.Hash = b.ComputeHash()
+		data, err = proto.Marshal(b.ToPb())
+		if err != nil {
+			return err
+		}
+	}
+	if err := txn.Set(blockKey(epoch), data); err != nil {
+		return err
+	}
+	return txn.Delete(k)
+}
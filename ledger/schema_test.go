@@ -0,0 +1,122 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/dgraph-io/badger"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"rounds/node"
+	"testing"
+)
+
+func legacyBlockKey(epoch uint64) []byte {
+	k := make([]byte, legacyBlockKeyLen)
+	binary.BigEndian.PutUint64(k, epoch)
+	return k
+}
+
+func TestMigrateLegacySchema(t *testing.T) {
+	// database written before keys were versioned
+	dir, err := ioutil.TempDir("", "ledger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	opts := badger.DefaultOptions
+	opts.Dir = dir
+	opts.ValueDir = dir
+	db, err := badger.Open(opts)
+	require.NoError(t, err)
+	bare, err := node.EncodeEntropy("first")
+	require.NoError(t, err)
+	second, err := node.EncodeEntropy("second")
+	require.NoError(t, err)
+	b := &node.Block{Epoch: 2, Timestamp: 1, WinnerEntropy: second}
+	b.Hash = b.ComputeHash()
+	block, err := proto.Marshal(b.ToPb())
+	require.NoError(t, err)
+	e := &node.Evidence{Offender: "offender", Epoch: 2}
+	evidence, err := proto.Marshal(e.ToPb())
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(legacyBlockKey(1), bare); err != nil {
+			return err
+		}
+		if err := txn.Set(legacyBlockKey(2), block); err != nil {
+			return err
+		}
+		// contract state key of legacy block key length isn't taken for block
+		if err := txn.Set(stateKey(make([]byte, legacyBlockKeyLen-len(statePrefix))), []byte("state")); err != nil {
+			return err
+		}
+		return txn.Set(append([]byte("evidence/offender/"), make([]byte, 20)...), evidence)
+	}))
+	require.NoError(t, db.Close())
+
+	c := &Config{}
+	c.DB.Path = dir
+	s := NewBadgerStore(c)
+	defer s.db.Close()
+	version, err := schemaVersion(s.db)
+	require.NoError(t, err)
+	require.Equal(t, uint64(SchemaVersion), version)
+
+	latest, err := s.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest)
+	first, err := s.GetBlock(1)
+	require.NoError(t, err)
+	require.Equal(t, bare, first.WinnerEntropy)
+	require.Equal(t, first.ComputeHash(), first.Hash)
+	migrated, err := s.GetBlock(2)
+	require.NoError(t, err)
+	require.Equal(t, b.Hash, migrated.Hash)
+	found, err := s.GetEvidence("offender")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	// contract state is kept and isn't iterated as blocks
+	require.NoError(t, s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(stateKey(lastBlockKey()), []byte("state"))
+	}))
+	require.NoError(t, s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(stateKey(make([]byte, legacyBlockKeyLen-len(statePrefix))))
+		require.NoError(t, err)
+		v, err := item.Value()
+		require.NoError(t, err)
+		require.Equal(t, []byte("state"), v)
+		return nil
+	}))
+	latest, err = s.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest)
+	blocks, err := s.GetBlockRange(1, 10)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+
+	// legacy keys are removed, migration isn't repeated
+	require.NoError(t, s.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(legacyBlockKey(1))
+		require.Equal(t, badger.ErrKeyNotFound, err)
+		return nil
+	}))
+	require.NoError(t, migrate(s.db))
+
+	require.NoError(t, s.db.Update(func(txn *badger.Txn) error {
+		return setSchemaVersion(txn, SchemaVersion+1)
+	}))
+	require.Error(t, migrate(s.db))
+}
+
+func TestSchemaPrefixesDontOverlap(t *testing.T) {
+	for i, p := range prefixes {
+		for j, other := range prefixes {
+			if i != j {
+				require.False(t, bytes.HasPrefix(p, other), "%s overlaps %s", p, other)
+			}
+		}
+	}
+	require.True(t, hasSchemaPrefix(stateKey([]byte("key"))))
+	require.False(t, hasSchemaPrefix(legacyBlockKey(1)))
+}
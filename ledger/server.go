@@ -4,7 +4,10 @@ package ledger
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"net"
 	pb "rounds/ledger/pb"
@@ -16,25 +19,47 @@ import (
 type server struct {
 	pb.UnimplementedLedgerServer
 	store Storer
+	feed  *pulseFeed
+	// keys public keys of peers by node id, evidence is accepted only against them
+	keys map[string]*ecdsa.PublicKey
 
 	log *logger.Logger
 }
 
 func (s *server) Commit(ctx context.Context, in *pb.CommitPulseRequest) (*pb.CommitPulseResponse, error) {
 	s.log.Infof("received pulse data: %s", in.GetEntropy())
-	latestEpoch, err := s.store.GetLatestBlockEpoch()
-	if err != nil {
-		return &pb.CommitPulseResponse{Error: "failed to get latest pulse number"}, nil
-	}
 	b := &node.Block{
-		Epoch:         uint64(latestEpoch + 1),
+		Epoch:         in.GetEpoch(),
 		Timestamp:     time.Now().Unix(),
+		Rst:           in.GetRst(),
 		WinnerEntropy: in.GetEntropy(),
+		Proposers:     in.GetProposers(),
+		Confirmations: node.ConfirmationsFromPb(in.GetConfirmations()),
+		QC:            node.QuorumCertFromPb(in.GetQc()),
+		Proofs:        node.VrfProofsFromPb(in.GetProofs()),
+		Signature:     in.GetSignature(),
 	}
-	if err := s.store.CommitPulse(b); err != nil {
+	stored, err := s.commit(b)
+	if _, ok := err.(*ConflictError); ok {
+		s.log.Infof("refused pulse: %s", err)
+		return &pb.CommitPulseResponse{Error: err.Error(), Block: stored.ToPb(), Conflict: true}, nil
+	}
+	if err != nil {
 		return &pb.CommitPulseResponse{Error: err.Error()}, nil
 	}
-	return &pb.CommitPulseResponse{}, nil
+	return &pb.CommitPulseResponse{Block: stored.ToPb()}, nil
+}
+
+// commit commits block to store and publishes it to subscribers, retried block is already published
+func (s *server) commit(b *node.Block) (*node.Block, error) {
+	stored, err := s.store.CommitPulse(b)
+	if err != nil {
+		return stored, err
+	}
+	if stored == b {
+		s.feed.publish(b)
+	}
+	return stored, nil
 }
 
 func (s *server) GetLatestBlockEpoch(ctx context.Context, in *pb.LatestPNRequest) (*pb.LatestPNResponse, error) {
@@ -46,19 +71,170 @@ func (s *server) GetLatestBlockEpoch(ctx context.Context, in *pb.LatestPNRequest
 	return &pb.LatestPNResponse{Epoch: epoch}, nil
 }
 
-func Serve(c *Config) {
-	host := c.Ledger.Host
+func (s *server) CommitEvidence(ctx context.Context, in *pb.CommitEvidenceRequest) (*pb.CommitEvidenceResponse, error) {
+	if in.GetEvidence() == nil {
+		return &pb.CommitEvidenceResponse{Error: "evidence is empty"}, nil
+	}
+	e := node.EvidenceFromPb(in.GetEvidence())
+	pub, ok := s.keys[e.Offender]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "evidence against unknown node %s", e.Offender)
+	}
+	if !e.Verify(pub) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid evidence: %s", e.String())
+	}
+	s.log.Infof("received evidence: %s", e.String())
+	if err := s.store.CommitEvidence(e); err != nil {
+		return &pb.CommitEvidenceResponse{Error: err.Error()}, nil
+	}
+	return &pb.CommitEvidenceResponse{}, nil
+}
+
+func (s *server) GetEvidence(ctx context.Context, in *pb.GetEvidenceRequest) (*pb.GetEvidenceResponse, error) {
+	evidence, err := s.store.GetEvidence(in.GetOffender())
+	if err != nil {
+		return &pb.GetEvidenceResponse{Error: err.Error()}, nil
+	}
+	resp := &pb.GetEvidenceResponse{}
+	for _, e := range evidence {
+		resp.Evidence = append(resp.Evidence, e.ToPb())
+	}
+	return resp, nil
+}
+
+func (s *server) GetBlock(ctx context.Context, in *pb.GetBlockRequest) (*pb.GetBlockResponse, error) {
+	b, err := s.store.GetBlock(in.GetEpoch())
+	if err != nil {
+		return &pb.GetBlockResponse{Error: err.Error()}, nil
+	}
+	if b == nil {
+		return &pb.GetBlockResponse{}, nil
+	}
+	return &pb.GetBlockResponse{Block: b.ToPb()}, nil
+}
+
+// GetBlockRange gets page of blocks from epoch, page size is limited by MaxBlockRange
+func (s *server) GetBlockRange(ctx context.Context, in *pb.GetBlockRangeRequest) (*pb.GetBlockRangeResponse, error) {
+	limit := int(in.GetLimit())
+	if limit == 0 || limit > MaxBlockRange {
+		limit = MaxBlockRange
+	}
+	blocks, err := s.store.GetBlockRange(in.GetFromEpoch(), limit)
+	if err != nil {
+		return &pb.GetBlockRangeResponse{Error: err.Error()}, nil
+	}
+	next, err := nextEpoch(s.store, blocks, limit)
+	if err != nil {
+		return &pb.GetBlockRangeResponse{Error: err.Error()}, nil
+	}
+	resp := &pb.GetBlockRangeResponse{NextEpoch: next}
+	for _, b := range blocks {
+		resp.Blocks = append(resp.Blocks, b.ToPb())
+	}
+	return resp, nil
+}
+
+func (s *server) GetLatestBlock(ctx context.Context, in *pb.GetLatestBlockRequest) (*pb.GetBlockResponse, error) {
+	b, err := s.store.GetLatestBlock()
+	if err != nil {
+		return &pb.GetBlockResponse{Error: err.Error()}, nil
+	}
+	if b == nil {
+		return &pb.GetBlockResponse{}, nil
+	}
+	return &pb.GetBlockResponse{Block: b.ToPb()}, nil
+}
+
+// SubscribePulses replays blocks from requested epoch and streams new blocks as they're committed,
+// subscriber which falls behind is caught up from store, so it gets every block once and in order
+func (s *server) SubscribePulses(in *pb.SubscribePulsesRequest, stream pb.Ledger_SubscribePulsesServer) error {
+	sub := s.feed.subscribe()
+	defer s.feed.unsubscribe(sub)
+	next := in.GetFromEpoch()
+	if next == 0 {
+		latest, err := s.store.GetLatestBlockEpoch()
+		if err != nil {
+			return err
+		}
+		next = latest + 1
+	}
+	next, err := s.replay(stream, next)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-sub.lagged:
+			s.log.Infof("subscriber lags, replaying from epoch %d", next)
+			if next, err = s.replay(stream, next); err != nil {
+				return err
+			}
+		case b := <-sub.blocks:
+			if b.Epoch < next {
+				continue
+			}
+			if b.Epoch > next {
+				if next, err = s.replay(stream, next); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := stream.Send(b.ToPb()); err != nil {
+				return err
+			}
+			next = b.Epoch + 1
+		}
+	}
+}
+
+// replay sends stored blocks from epoch page by page, returns epoch of the next block to send
+func (s *server) replay(stream pb.Ledger_SubscribePulsesServer, from uint64) (uint64, error) {
+	for {
+		blocks, err := s.store.GetBlockRange(from, MaxBlockRange)
+		if err != nil {
+			return from, err
+		}
+		for _, b := range blocks {
+			if err := stream.Send(b.ToPb()); err != nil {
+				return from, err
+			}
+			from = b.Epoch + 1
+		}
+		if len(blocks) < MaxBlockRange {
+			return from, nil
+		}
+	}
+}
+
+func newServer(store Storer, keys map[string]*ecdsa.PublicKey) *server {
+	return &server{
+		store: store,
+		feed:  newPulseFeed(),
+		keys:  keys,
+		log:   logger.NewLogger(),
+	}
+}
+
+// listen serves ledger api on host
+func (s *server) listen(host string) {
 	lis, err := net.Listen("tcp", host)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
-	pb.RegisterLedgerServer(s, &server{
-		store: NewBadgerStore(c),
-		log:   logger.NewLogger(),
-	})
+	srv := grpc.NewServer()
+	pb.RegisterLedgerServer(srv, s)
 	log.Printf("starting ledger: %s", host)
-	if err := s.Serve(lis); err != nil {
+	if err := srv.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
 	}
 }
+
+func Serve(c *Config) {
+	store := NewStore(c)
+	if c.HTTP.Host != "" {
+		go ServeBeacon(c, store)
+	}
+	newServer(store, peerKeys(c.Verify.Peers)).listen(c.Ledger.Host)
+}
@@ -0,0 +1,228 @@
+package ledger
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "rounds/ledger/pb"
+	"rounds/logger"
+	"rounds/node"
+	"testing"
+	"time"
+)
+
+func TestGetBlocks(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	srv := &server{store: s, feed: newPulseFeed(), log: logger.NewLogger()}
+	ctx := context.Background()
+
+	latest, err := srv.GetLatestBlock(ctx, &pb.GetLatestBlockRequest{})
+	require.NoError(t, err)
+	require.Empty(t, latest.Error)
+	require.Nil(t, latest.Block)
+
+	a := newTestSigner(t)
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		commitSigned(t, s, epoch, "entropy", a)
+	}
+
+	b, err := srv.GetBlock(ctx, &pb.GetBlockRequest{Epoch: 3})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), b.Block.Epoch)
+	require.Len(t, b.Block.Confirmations, 1)
+	missing, err := srv.GetBlock(ctx, &pb.GetBlockRequest{Epoch: 6})
+	require.NoError(t, err)
+	require.Empty(t, missing.Error)
+	require.Nil(t, missing.Block)
+
+	latest, err = srv.GetLatestBlock(ctx, &pb.GetLatestBlockRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), latest.Block.Epoch)
+
+	// pages are linked by next epoch
+	page, err := srv.GetBlockRange(ctx, &pb.GetBlockRangeRequest{FromEpoch: 2, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Blocks, 2)
+	require.Equal(t, uint64(2), page.Blocks[0].Epoch)
+	require.Equal(t, page.Blocks[0].Hash, page.Blocks[1].PrevHash)
+	require.Equal(t, uint64(4), page.NextEpoch)
+	// full page ending with the latest block is the last one
+	page, err = srv.GetBlockRange(ctx, &pb.GetBlockRangeRequest{FromEpoch: page.NextEpoch, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Blocks, 2)
+	require.Equal(t, uint64(0), page.NextEpoch)
+	page, err = srv.GetBlockRange(ctx, &pb.GetBlockRangeRequest{FromEpoch: 6, Limit: 2})
+	require.NoError(t, err)
+	require.Empty(t, page.Blocks)
+	require.Equal(t, uint64(0), page.NextEpoch)
+
+	// limit is capped by page size
+	all, err := srv.GetBlockRange(ctx, &pb.GetBlockRangeRequest{FromEpoch: 1})
+	require.NoError(t, err)
+	require.Len(t, all.Blocks, 5)
+	require.Equal(t, uint64(0), all.NextEpoch)
+}
+
+type blockStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pb.Block
+}
+
+func (m *blockStream) Context() context.Context {
+	return m.ctx
+}
+
+func (m *blockStream) Send(b *pb.Block) error {
+	select {
+	case m.sent <- b:
+		return nil
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	}
+}
+
+func subscribe(srv *server, from uint64) (*blockStream, context.CancelFunc, chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &blockStream{ctx: ctx, sent: make(chan *pb.Block)}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.SubscribePulses(&pb.SubscribePulsesRequest{FromEpoch: from}, stream)
+	}()
+	return stream, cancel, done
+}
+
+func commitPulses(t *testing.T, srv *server, count int) {
+	for i := 0; i < count; i++ {
+		latest, err := srv.store.GetLatestBlockEpoch()
+		require.NoError(t, err)
+		resp, err := srv.Commit(context.Background(), &pb.CommitPulseRequest{Rst: int64(1000 + latest + 1), Entropy: []byte("entropy")})
+		require.NoError(t, err)
+		require.Empty(t, resp.Error)
+	}
+}
+
+func requireEpochs(t *testing.T, stream *blockStream, from uint64, to uint64) {
+	for epoch := from; epoch <= to; epoch++ {
+		select {
+		case b := <-stream.sent:
+			require.Equal(t, epoch, b.Epoch)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block #%d is not received", epoch)
+		}
+	}
+}
+
+func TestSubscribePulsesReplaysAndStreams(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	srv := &server{store: s, feed: newPulseFeed(), log: logger.NewLogger()}
+	commitPulses(t, srv, 3)
+
+	history, cancelHistory, historyDone := subscribe(srv, 2)
+	requireEpochs(t, history, 2, 3)
+	latest, cancelLatest, latestDone := subscribe(srv, 0)
+	require.Eventually(t, func() bool { return srv.feed.subscribers() == 2 }, time.Second, 10*time.Millisecond)
+
+	commitPulses(t, srv, 1)
+	requireEpochs(t, history, 4, 4)
+	requireEpochs(t, latest, 4, 4)
+
+	// cancelled subscription is removed from feed
+	cancelLatest()
+	require.Equal(t, context.Canceled, <-latestDone)
+	require.Equal(t, 1, srv.feed.subscribers())
+	cancelHistory()
+	require.Equal(t, context.Canceled, <-historyDone)
+	require.Equal(t, 0, srv.feed.subscribers())
+}
+
+func TestSubscribePulsesSlowSubscriberCatchesUp(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	srv := &server{store: s, feed: newPulseFeed(), log: logger.NewLogger()}
+
+	slow, cancel, _ := subscribe(srv, 0)
+	defer cancel()
+	require.Eventually(t, func() bool { return srv.feed.subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+	// commits aren't blocked while subscriber doesn't read
+	count := subscriberBuffer * 2
+	commitPulses(t, srv, count)
+	requireEpochs(t, slow, 1, uint64(count))
+	commitPulses(t, srv, 1)
+	requireEpochs(t, slow, uint64(count+1), uint64(count+1))
+}
+
+func TestCommitIsIdempotentPerEpoch(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	srv := &server{store: s, feed: newPulseFeed(), log: logger.NewLogger()}
+	ctx := context.Background()
+	commitPulses(t, srv, 1)
+
+	first, err := srv.Commit(ctx, &pb.CommitPulseRequest{Epoch: 2, Entropy: []byte("entropy")})
+	require.NoError(t, err)
+	require.Empty(t, first.Error)
+	require.Equal(t, uint64(2), first.Block.Epoch)
+
+	// retry returns stored block and doesn't publish it again
+	sub := srv.feed.subscribe()
+	defer srv.feed.unsubscribe(sub)
+	retry, err := srv.Commit(ctx, &pb.CommitPulseRequest{Epoch: 2, Entropy: []byte("entropy")})
+	require.NoError(t, err)
+	require.Empty(t, retry.Error)
+	require.False(t, retry.Conflict)
+	require.Equal(t, first.Block.Hash, retry.Block.Hash)
+	require.Len(t, sub.blocks, 0)
+
+	conflict, err := srv.Commit(ctx, &pb.CommitPulseRequest{Epoch: 2, Entropy: []byte("another")})
+	require.NoError(t, err)
+	require.True(t, conflict.Conflict)
+	require.NotEmpty(t, conflict.Error)
+	require.Equal(t, first.Block.Hash, conflict.Block.Hash)
+
+	ahead, err := srv.Commit(ctx, &pb.CommitPulseRequest{Epoch: 4, Entropy: []byte("entropy")})
+	require.NoError(t, err)
+	require.NotEmpty(t, ahead.Error)
+	require.False(t, ahead.Conflict)
+
+	latest, err := s.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest)
+}
+
+func TestCommitEvidenceVerifiesOffender(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	a, b := newTestSigner(t), newTestSigner(t)
+	srv := newServer(s, map[string]*ecdsa.PublicKey{a.id: &a.priv.PublicKey})
+	ctx := context.Background()
+
+	e := node.NewEvidence(node.NewSignedMessage(a.pulse(t, 3, 1000, "first")), a.pulse(t, 3, 1000, "second"))
+	_, err := srv.CommitEvidence(ctx, &pb.CommitEvidenceRequest{Evidence: e.ToPb()})
+	require.NoError(t, err)
+
+	// evidence against node which isn't peer of ledger
+	unknown := node.NewEvidence(node.NewSignedMessage(b.pulse(t, 3, 1000, "first")), b.pulse(t, 3, 1000, "second"))
+	_, err = srv.CommitEvidence(ctx, &pb.CommitEvidenceRequest{Evidence: unknown.ToPb()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	// evidence forged by another node
+	forged := node.NewEvidence(node.NewSignedMessage(a.pulse(t, 4, 1000, "first")), b.pulse(t, 4, 1000, "second"))
+	forged.Offender = a.id
+	_, err = srv.CommitEvidence(ctx, &pb.CommitEvidenceRequest{Evidence: forged.ToPb()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	// messages of different rounds
+	rounds := node.NewEvidence(node.NewSignedMessage(a.pulse(t, 5, 1000, "first")), a.pulse(t, 5, 1001, "second"))
+	_, err = srv.CommitEvidence(ctx, &pb.CommitEvidenceRequest{Evidence: rounds.ToPb()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	evidence, err := s.GetEvidence("")
+	require.NoError(t, err)
+	require.Len(t, evidence, 1)
+	require.Equal(t, a.id, evidence[0].Offender)
+}
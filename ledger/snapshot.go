@@ -0,0 +1,178 @@
+package ledger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/dgraph-io/badger"
+	"google.golang.org/grpc"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	pb "rounds/ledger/pb"
+)
+
+// snapshotChunkSize max bytes of snapshot sent in one message of snapshot stream
+const snapshotChunkSize = 64 << 10
+
+var (
+	// ErrSnapshotUnsupported store can't take snapshots
+	ErrSnapshotUnsupported = errors.New("store doesn't support snapshots")
+	// ErrRestoreNotEmpty snapshot is restored only to an empty db
+	ErrRestoreNotEmpty = errors.New("db to restore snapshot to is not empty")
+)
+
+// Snapshotter store which can write consistent snapshot of its db
+type Snapshotter interface {
+	// Snapshot writes all keys of db as of one read timestamp
+	Snapshot(w io.Writer) error
+}
+
+// Snapshot writes badger backup of db, backup is taken in one read transaction, so commits don't change it
+func (m *BadgerStore) Snapshot(w io.Writer) error {
+	_, err := m.db.Backup(w, 0)
+	return err
+}
+
+// Restore loads snapshot into db by path, db must be empty, restored db is migrated to the current key schema
+func Restore(path string, r io.Reader) error {
+	db, err := openBadger(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	empty := true
+	err = db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return ErrRestoreNotEmpty
+	}
+	if err := db.Load(r); err != nil {
+		return err
+	}
+	return migrate(db)
+}
+
+// FetchSnapshot streams snapshot of running ledger to w
+func FetchSnapshot(ctx context.Context, c pb.LedgerClient, w io.Writer) error {
+	stream, err := c.Snapshot(ctx, &pb.SnapshotRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(chunk.GetData()); err != nil {
+			return err
+		}
+	}
+}
+
+// WriteSnapshot writes snapshot to file, of running ledger at addr, or of db by config path if addr is empty,
+// ledger must be stopped to snapshot its db by path, snapshot is written to temp file in the same directory
+// which replaces file only once it's complete, so failed snapshot doesn't truncate existing file
+func WriteSnapshot(c *Config, addr string, file string) error {
+	f, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := writeSnapshot(c, addr, f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), file); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// writeSnapshot writes snapshot to f and syncs it to disk
+func writeSnapshot(c *Config, addr string, f *os.File) error {
+	w := bufio.NewWriter(f)
+	if addr != "" {
+		conn, err := grpc.Dial(addr, grpc.WithInsecure())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if err := FetchSnapshot(context.Background(), pb.NewLedgerClient(conn), w); err != nil {
+			return err
+		}
+	} else {
+		store := NewBadgerStore(c)
+		defer store.db.Close()
+		if err := store.Snapshot(w); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// RestoreSnapshot restores snapshot file to db by config path
+func RestoreSnapshot(c *Config, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return Restore(c.DB.Path, bufio.NewReader(f))
+}
+
+// chunkWriter sends written bytes as snapshot chunks
+type chunkWriter struct {
+	stream pb.Ledger_SnapshotServer
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + snapshotChunkSize
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := w.stream.Send(&pb.SnapshotChunk{Data: p[written:end]}); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+// Snapshot streams consistent snapshot of ledger db, it can be restored with restore command
+func (s *server) Snapshot(in *pb.SnapshotRequest, stream pb.Ledger_SnapshotServer) error {
+	store, ok := s.store.(Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+	s.log.Info("streaming snapshot")
+	w := bufio.NewWriterSize(chunkWriter{stream}, snapshotChunkSize)
+	if err := store.Snapshot(w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
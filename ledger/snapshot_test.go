@@ -0,0 +1,109 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	pb "rounds/ledger/pb"
+	"rounds/node"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	a := newTestSigner(t)
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		commitSigned(t, s, epoch, "entropy", a)
+	}
+	require.NoError(t, s.CommitEvidence(&node.Evidence{Offender: "a", Epoch: 1}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	pb.RegisterLedgerServer(srv, newServer(s, nil))
+	go srv.Serve(lis)
+	defer srv.Stop()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var snapshot bytes.Buffer
+	require.NoError(t, FetchSnapshot(context.Background(), pb.NewLedgerClient(conn), &snapshot))
+	// block committed after snapshot is not in it
+	commitSigned(t, s, 6, "entropy", a)
+
+	dir, err := ioutil.TempDir("", "restore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, Restore(dir, bytes.NewReader(snapshot.Bytes())))
+	require.Equal(t, ErrRestoreNotEmpty, Restore(dir, bytes.NewReader(snapshot.Bytes())))
+
+	c := &Config{}
+	c.DB.Path = dir
+	restored := NewBadgerStore(c)
+	defer restored.db.Close()
+	epoch, err := restored.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), epoch)
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		want, err := s.GetBlock(epoch)
+		require.NoError(t, err)
+		got, err := restored.GetBlock(epoch)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	evidence, err := restored.GetEvidence("a")
+	require.NoError(t, err)
+	require.Len(t, evidence, 1)
+	issues, _, err := restored.VerifyChain(&ChainVerifier{Keys: map[string]*ecdsa.PublicKey{a.id: &a.priv.PublicKey}})
+	require.NoError(t, err)
+	require.Empty(t, issues)
+}
+
+func TestSnapshotUnsupported(t *testing.T) {
+	err := newServer(NewMemoryStore(), nil).Snapshot(&pb.SnapshotRequest{}, nil)
+	require.Equal(t, ErrSnapshotUnsupported, err)
+}
+
+func TestWriteSnapshotReplacesFileWhenComplete(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	commitSigned(t, s, 1, "entropy", newTestSigner(t))
+	addr, stop := serveStore(t, s)
+	defer stop()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "ledger.snapshot")
+	require.NoError(t, ioutil.WriteFile(file, []byte("previous"), 0644))
+
+	// failed fetch leaves previous snapshot and no temp file
+	require.Error(t, WriteSnapshot(&Config{}, "127.0.0.1:1", file))
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, []byte("previous"), data)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	require.NoError(t, WriteSnapshot(&Config{}, addr, file))
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	c := &Config{}
+	c.DB.Path = filepath.Join(dir, "db")
+	require.NoError(t, RestoreSnapshot(c, file))
+	restored := NewBadgerStore(c)
+	defer restored.db.Close()
+	epoch, err := restored.GetLatestBlockEpoch()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), epoch)
+}
@@ -1,16 +1,37 @@
 package ledger
 
 import (
-	"encoding/binary"
 	"github.com/dgraph-io/badger"
+	"github.com/golang/protobuf/proto"
 	"log"
+	pb "rounds/ledger/pb"
 	"rounds/logger"
 	"rounds/node"
 )
 
+const (
+	// MaxBlockRange max blocks returned in one page of range
+	MaxBlockRange = 100
+	// commitRetries retries of commit transaction conflicting with concurrent commit
+	commitRetries = 3
+)
+
 type Storer interface {
 	GetLatestBlockEpoch() (uint64, error)
-	CommitPulse(block *node.Block) error
+	// CommitPulse chains block to the latest one and stores it at its epoch, or the next one if epoch is not set,
+	// returns stored block, which is the existing one if block is already committed at the epoch,
+	// ConflictError is returned with the existing block if its entropy differs
+	CommitPulse(block *node.Block) (*node.Block, error)
+	// CommitEvidence stores evidence of peer misbehaviour
+	CommitEvidence(e *node.Evidence) error
+	// GetEvidence gets evidence against offender, all evidence if offender is empty
+	GetEvidence(offender string) ([]*node.Evidence, error)
+	// GetBlock gets block by epoch, nil if not found
+	GetBlock(epoch uint64) (*node.Block, error)
+	// GetBlockRange gets up to limit blocks in epoch order from epoch up to the latest one, both inclusive
+	GetBlockRange(from uint64, limit int) ([]*node.Block, error)
+	// GetLatestBlock gets block with the highest epoch, nil if there are no blocks
+	GetLatestBlock() (*node.Block, error)
 }
 
 type BadgerStore struct {
@@ -19,21 +40,173 @@ type BadgerStore struct {
 	log *logger.Logger
 }
 
-func (m *BadgerStore) CommitPulse(b *node.Block) error {
+// ConflictError block with another entropy is already committed at epoch, it wraps node.ErrConflict
+type ConflictError struct {
+	Epoch uint64
+}
+
+func (e *ConflictError) Error() string {
+	return node.ErrEpochConflict(e.Epoch).Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return node.ErrConflict
+}
+
+// CommitPulse chains block to the latest stored block and stores it as serialized record, one block per epoch,
+// latest block is read in the same transaction, so concurrent commits can't fork the chain,
+// transaction conflicting with concurrent commit is retried and sees the committed block
+func (m *BadgerStore) CommitPulse(b *node.Block) (*node.Block, error) {
+	for i := 0; ; i++ {
+		var stored *node.Block
+		err := m.db.Update(func(txn *badger.Txn) error {
+			var err error
+			stored, err = m.commitPulse(txn, b)
+			return err
+		})
+		if err == badger.ErrConflict && i < commitRetries {
+			continue
+		}
+		return stored, err
+	}
+}
+
+// committed returns block already committed at epoch of block, with ConflictError if its entropy differs
+func committed(existing *node.Block, b *node.Block) (*node.Block, error) {
+	if err := node.VerifyCommitted(existing, b); err != nil {
+		return existing, &ConflictError{existing.Epoch}
+	}
+	return existing, nil
+}
+
+func (m *BadgerStore) commitPulse(txn *badger.Txn, b *node.Block) (*node.Block, error) {
+	latest, err := latestBlock(txn)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := node.ChainBlock(b, latest, func(epoch uint64) (*node.Block, error) {
+		return readBlock(txn, epoch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if stored != b {
+		return committed(stored, b)
+	}
 	m.log.Infof("committing pulse: %s", b.String())
-	epoch := make([]byte, 64)
-	binary.BigEndian.PutUint64(epoch, b.Epoch)
-	if err := m.db.Update(func(txn *badger.Txn) error {
-		err := txn.Set(epoch, b.WinnerEntropy)
+	data, err := proto.Marshal(b.ToPb())
+	if err != nil {
+		return nil, err
+	}
+	return b, txn.Set(blockKey(b.Epoch), data)
+}
+
+// latestBlock gets block with the highest epoch, nil if there are no blocks
+func latestBlock(txn *badger.Txn) (*node.Block, error) {
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = true
+	it := txn.NewIterator(opts)
+	defer it.Close()
+	it.Seek(lastBlockKey())
+	if !it.ValidForPrefix(blockPrefix) {
+		return nil, nil
+	}
+	data, err := it.Item().ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBlock(data)
+}
+
+func (m *BadgerStore) GetBlock(epoch uint64) (*node.Block, error) {
+	var b *node.Block
+	if err := m.db.View(func(txn *badger.Txn) error {
+		var err error
+		b, err = readBlock(txn, epoch)
 		return err
 	}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readBlock reads block by epoch, nil if not found
+func readBlock(txn *badger.Txn, epoch uint64) (*node.Block, error) {
+	item, err := txn.Get(blockKey(epoch))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBlock(data)
+}
+
+func (m *BadgerStore) GetBlockRange(from uint64, limit int) ([]*node.Block, error) {
+	blocks := make([]*node.Block, 0)
+	if err := m.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(blockKey(from)); it.ValidForPrefix(blockPrefix) && len(blocks) < limit; it.Next() {
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			b, err := decodeBlock(data)
+			if err != nil {
+				return err
+			}
+			blocks = append(blocks, b)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// nextEpoch epoch page of blocks read up to limit is continued from, 0 if page ends with the latest block
+func nextEpoch(s Storer, blocks []*node.Block, limit int) (uint64, error) {
+	if len(blocks) < limit {
+		return 0, nil
+	}
+	latest, err := s.GetLatestBlockEpoch()
+	if err != nil {
+		return 0, err
+	}
+	last := blocks[len(blocks)-1].Epoch
+	if last >= latest {
+		return 0, nil
+	}
+	return last + 1, nil
+}
+
+func (m *BadgerStore) GetLatestBlock() (*node.Block, error) {
+	var b *node.Block
+	if err := m.db.View(func(txn *badger.Txn) error {
+		var err error
+		b, err = latestBlock(txn)
 		return err
+	}); err != nil {
+		return nil, err
 	}
-	return nil
+	return b, nil
+}
+
+func decodeBlock(data []byte) (*node.Block, error) {
+	var b pb.Block
+	if err := proto.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return node.BlockFromPb(&b), nil
 }
 
 func (m *BadgerStore) GetLatestBlockEpoch() (uint64, error) {
-	var latestPN []byte
+	var latest uint64
 	if err := m.db.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchValues = false
@@ -41,31 +214,97 @@ func (m *BadgerStore) GetLatestBlockEpoch() (uint64, error) {
 		opts.AllVersions = false
 		it := txn.NewIterator(opts)
 		defer it.Close()
-		it.Rewind()
-		if it.Valid() {
-			latestPN = it.Item().Key()
+		it.Seek(lastBlockKey())
+		if it.ValidForPrefix(blockPrefix) {
+			latest = blockKeyEpoch(it.Item().Key())
 		}
 		return nil
 	}); err != nil {
 		return 0, err
 	}
-	if len(latestPN) == 0 {
-		return 0, nil
+	return latest, nil
+}
+
+// CommitEvidence stores evidence once per offender, round and message type
+func (m *BadgerStore) CommitEvidence(e *node.Evidence) error {
+	m.log.Infof("committing evidence: %s", e.String())
+	data, err := proto.Marshal(e.ToPb())
+	if err != nil {
+		return err
+	}
+	return m.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(evidenceKey(e), data)
+	})
+}
+
+func (m *BadgerStore) GetEvidence(offender string) ([]*node.Evidence, error) {
+	prefix := evidencePrefix
+	if offender != "" {
+		prefix = prefixed(evidencePrefix, []byte(offender+"/"))
+	}
+	evidence := make([]*node.Evidence, 0)
+	if err := m.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			var e pb.Evidence
+			if err := proto.Unmarshal(data, &e); err != nil {
+				return err
+			}
+			evidence = append(evidence, node.EvidenceFromPb(&e))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-	l := binary.BigEndian.Uint64(latestPN)
-	return l, nil
+	return evidence, nil
+}
+
+const (
+	// BadgerDB blocks are stored in badger db at db path
+	BadgerDB = "badger"
+	// MemoryDB blocks are kept in memory and lost on restart
+	MemoryDB = "memory"
+)
+
+// NewStore store of config db type, badger if not set
+func NewStore(c *Config) Storer {
+	switch c.DB.Type {
+	case "", BadgerDB:
+		store := NewBadgerStore(c)
+		if c.DB.Retention.enabled() {
+			go store.pruneEvery(c.DB.Retention)
+		}
+		return store
+	case MemoryDB:
+		return NewMemoryStore()
+	}
+	log.Fatalf("unknown db type %s, supported types: %s, %s", c.DB.Type, BadgerDB, MemoryDB)
+	return nil
 }
 
 func NewBadgerStore(c *Config) *BadgerStore {
 	log.Printf("opening db by path: %s", c.DB.Path)
-	badger.DefaultOptions.Dir = c.DB.Path
-	badger.DefaultOptions.ValueDir = c.DB.Path
-	db, err := badger.Open(badger.DefaultOptions)
+	db, err := openBadger(c.DB.Path)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := migrate(db); err != nil {
+		log.Fatalf("failed to migrate db: %v", err)
+	}
 	return &BadgerStore{
 		db,
 		logger.NewLogger(),
 	}
 }
+
+func openBadger(path string) (*badger.DB, error) {
+	opts := badger.DefaultOptions
+	opts.Dir = path
+	opts.ValueDir = path
+	return badger.Open(opts)
+}
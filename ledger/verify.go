@@ -0,0 +1,199 @@
+package ledger
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"github.com/dgraph-io/badger"
+	"log"
+	pb "rounds/ledger/pb"
+	"rounds/node"
+)
+
+const (
+	IssueGap       = "gap"
+	IssueFork      = "fork"
+	IssueDuplicate = "duplicate epoch"
+	IssueHash      = "bad hash"
+	IssueSignature = "bad signature"
+	IssueRecord    = "malformed record"
+)
+
+// ChainIssue problem found in stored chain at epoch
+type ChainIssue struct {
+	Epoch  uint64
+	Kind   string
+	Detail string
+}
+
+func (i ChainIssue) String() string {
+	return fmt.Sprintf("epoch %d: %s: %s", i.Epoch, i.Kind, i.Detail)
+}
+
+// ChainVerifier keys blocks are verified against
+type ChainVerifier struct {
+	// Keys public keys of nodes by id, confirmations aren't checked if not set
+	Keys map[string]*ecdsa.PublicKey
+	// GroupKey threshold group public key, group signatures aren't checked if empty
+	GroupKey []byte
+}
+
+// VerifyChain walks blocks in epoch order, recomputes hashes and links to previous blocks and checks signatures,
+// pruned blocks aren't reported missing if checkpoint after them is anchored, but anchor isn't proof of pruned blocks,
+// so checkpoint isn't linked to the previous retained block and is verified by its own confirmations and signatures,
+// returns issues found and number of blocks checked
+func (m *BadgerStore) VerifyChain(v *ChainVerifier) ([]ChainIssue, int, error) {
+	issues := make([]ChainIssue, 0)
+	checked := 0
+	err := m.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		seen := make(map[uint64]bool)
+		var prev *node.Block
+		var prevKey uint64
+		for it.Seek(blockPrefix); it.ValidForPrefix(blockPrefix); it.Next() {
+			key := blockKeyEpoch(it.Item().Key())
+			data, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			checked++
+			b, err := decodeBlock(data)
+			if err != nil {
+				issues = append(issues, ChainIssue{key, IssueRecord, err.Error()})
+				prev = nil
+				prevKey = key
+				continue
+			}
+			if key != prevKey+1 {
+				a, err := readAnchor(txn, key)
+				if err != nil {
+					return err
+				}
+				if !anchored(a, prevKey, prev, b) {
+					issues = append(issues, ChainIssue{key, IssueGap, fmt.Sprintf("epochs %d-%d are missing", prevKey+1, key-1)})
+				}
+				prev = nil
+			}
+			prevKey = key
+			if b.Epoch != key {
+				kind := IssueRecord
+				if seen[b.Epoch] {
+					kind = IssueDuplicate
+				}
+				issues = append(issues, ChainIssue{key, kind, fmt.Sprintf("block of epoch %d stored as epoch %d", b.Epoch, key)})
+			}
+			seen[b.Epoch] = true
+			issues = append(issues, v.verifyBlock(key, prev, b)...)
+			prev = b
+		}
+		return nil
+	})
+	return issues, checked, err
+}
+
+// anchored checks that anchor marks range between the previous retained block and checkpoint block as pruned
+func anchored(a *pb.Anchor, from uint64, prev *node.Block, b *node.Block) bool {
+	if a == nil || a.GetFrom() != from || !bytes.Equal(a.GetPrevHash(), b.PrevHash) {
+		return false
+	}
+	var prevHash []byte
+	if prev != nil {
+		prevHash = prev.Hash
+	}
+	return bytes.Equal(a.GetFromHash(), prevHash)
+}
+
+// verifyBlock checks block against the previous one, prev is nil if previous block is missing or malformed
+func (v *ChainVerifier) verifyBlock(key uint64, prev *node.Block, b *node.Block) []ChainIssue {
+	issues := make([]ChainIssue, 0)
+	if !bytes.Equal(b.ComputeHash(), b.Hash) {
+		issues = append(issues, ChainIssue{key, IssueHash, fmt.Sprintf("stored %x, computed %x", b.Hash, b.ComputeHash())})
+	}
+	if prev != nil && !bytes.Equal(b.PrevHash, prev.Hash) {
+		issues = append(issues, ChainIssue{key, IssueFork, fmt.Sprintf("previous hash %x, previous block hash %x", b.PrevHash, prev.Hash)})
+	}
+	if prev == nil && key == 1 && len(b.PrevHash) != 0 {
+		issues = append(issues, ChainIssue{key, IssueFork, fmt.Sprintf("genesis block has previous hash %x", b.PrevHash)})
+	}
+	if len(v.Keys) != 0 {
+		if err := b.VerifyConfirmations(v.Keys); err != nil {
+			issues = append(issues, ChainIssue{key, IssueSignature, err.Error()})
+		}
+	}
+	if len(b.Proofs) != 0 && (prev != nil || key == 1) {
+		var prevEntropy []byte
+		if prev != nil {
+			prevEntropy = prev.WinnerEntropy
+		}
+		if err := verifyProofs(prevEntropy, b, v.Keys); err != nil {
+			issues = append(issues, ChainIssue{key, IssueSignature, err.Error()})
+		}
+	}
+	if len(v.GroupKey) != 0 {
+		if err := node.VerifyBlockSignature(v.GroupKey, b); err != nil {
+			issues = append(issues, ChainIssue{key, IssueSignature, fmt.Sprintf("group signature: %s", err)})
+		}
+	}
+	return issues
+}
+
+// verifyProofs recomputes pulse entropy from vrf proofs of contributions
+func verifyProofs(prevEntropy []byte, b *node.Block, keys map[string]*ecdsa.PublicKey) error {
+	entropy, err := node.DecodeEntropy(b.WinnerEntropy)
+	if err != nil {
+		return err
+	}
+	recomputed, err := node.PulseEntropy(prevEntropy, b.Epoch, b.Proofs, keys)
+	if err != nil {
+		return err
+	}
+	if recomputed != entropy {
+		return fmt.Errorf("entropy %s doesn't match vrf proofs, recomputed %s", entropy, recomputed)
+	}
+	return nil
+}
+
+// peerKeys loads public keys of peers by node id
+func peerKeys(peers []node.Peer) map[string]*ecdsa.PublicKey {
+	keys := make(map[string]*ecdsa.PublicKey)
+	for _, p := range peers {
+		pub := node.LoadPublicKey(p.PubKeyDir)
+		id, err := node.NodeID(pub)
+		if err != nil {
+			log.Fatal(err)
+		}
+		keys[id] = pub
+	}
+	return keys
+}
+
+// NewChainVerifier loads peer public keys and group key from config
+func NewChainVerifier(c *Config) *ChainVerifier {
+	keys := peerKeys(c.Verify.Peers)
+	var groupKey []byte
+	if c.Verify.GroupKeyDir != "" {
+		k, err := node.ReadGroupKey(c.Verify.GroupKeyDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groupKey = k
+	}
+	return &ChainVerifier{keys, groupKey}
+}
+
+// Verify verifies ledger chain offline and reports issues, ledger must be stopped as db is opened exclusively,
+// returns false if any issue is found
+func Verify(c *Config) bool {
+	store := NewBadgerStore(c)
+	defer store.db.Close()
+	issues, checked, err := store.VerifyChain(NewChainVerifier(c))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, i := range issues {
+		log.Printf("%s", i)
+	}
+	log.Printf("blocks checked: %d, issues found: %d", checked, len(issues))
+	return len(issues) == 0
+}
@@ -0,0 +1,182 @@
+package ledger
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"github.com/dgraph-io/badger"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"rounds/node"
+	"testing"
+)
+
+type testSigner struct {
+	id   string
+	priv *ecdsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	id, err := node.NodeID(&priv.PublicKey)
+	require.NoError(t, err)
+	return &testSigner{id, priv}
+}
+
+func (s *testSigner) confirm(t *testing.T, epoch uint64, rst int64, entropy string) *node.Confirmation {
+	cm := node.NewPulseConfirmMessage(s.id, epoch, rst, entropy)
+	digest := sha256.Sum256(cm.Payload.SigningData())
+	sig, err := s.priv.Sign(rand.Reader, digest[:], nil)
+	require.NoError(t, err)
+	return &node.Confirmation{From: s.id, Signature: sig}
+}
+
+// pulse signs collect message of signer with commitment
+func (s *testSigner) pulse(t *testing.T, epoch uint64, rst int64, commitment string) node.PulseMessagePayload {
+	pm := node.NewPulseMessage(s.id, epoch, rst, commitment)
+	digest := sha256.Sum256(pm.Payload.SigningData())
+	sig, err := s.priv.Sign(rand.Reader, digest[:], nil)
+	require.NoError(t, err)
+	pm.Payload.Signature = sig
+	return pm.Payload
+}
+
+func testStore(t *testing.T) (*BadgerStore, func()) {
+	dir, err := ioutil.TempDir("", "ledger")
+	require.NoError(t, err)
+	c := &Config{}
+	c.DB.Path = dir
+	s := NewBadgerStore(c)
+	return s, func() {
+		s.db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// commitSigned commits block of the next epoch confirmed by signers
+func commitSigned(t *testing.T, s *BadgerStore, epoch uint64, entropy string, signers ...*testSigner) {
+	winnerEntropy, err := node.EncodeEntropy(entropy)
+	require.NoError(t, err)
+	rst := int64(1000 + epoch)
+	b := &node.Block{Timestamp: 1, Rst: rst, WinnerEntropy: winnerEntropy}
+	for _, signer := range signers {
+		b.Confirmations = append(b.Confirmations, signer.confirm(t, epoch-1, rst, entropy))
+	}
+	stored, err := s.CommitPulse(b)
+	require.NoError(t, err)
+	require.Equal(t, epoch, stored.Epoch)
+}
+
+func putBlock(t *testing.T, s *BadgerStore, key uint64, b *node.Block) {
+	data, err := proto.Marshal(b.ToPb())
+	require.NoError(t, err)
+	require.NoError(t, s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(blockKey(key), data)
+	}))
+}
+
+func getBlock(t *testing.T, s *BadgerStore, key uint64) *node.Block {
+	var b *node.Block
+	require.NoError(t, s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(blockKey(key))
+		if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		b, err = decodeBlock(data)
+		return err
+	}))
+	return b
+}
+
+func issueKinds(issues []ChainIssue) map[uint64][]string {
+	kinds := make(map[uint64][]string)
+	for _, i := range issues {
+		kinds[i.Epoch] = append(kinds[i.Epoch], i.Kind)
+	}
+	return kinds
+}
+
+func TestVerifyChainValid(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	a, b := newTestSigner(t), newTestSigner(t)
+	v := &ChainVerifier{Keys: map[string]*ecdsa.PublicKey{a.id: &a.priv.PublicKey, b.id: &b.priv.PublicKey}}
+	for epoch := uint64(1); epoch <= 3; epoch++ {
+		commitSigned(t, s, epoch, "entropy", a, b)
+	}
+
+	issues, checked, err := s.VerifyChain(v)
+	require.NoError(t, err)
+	require.Equal(t, 3, checked)
+	require.Empty(t, issues)
+	require.Equal(t, getBlock(t, s, 2).Hash, getBlock(t, s, 3).PrevHash)
+}
+
+func TestVerifyChainReportsIssues(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	a, b := newTestSigner(t), newTestSigner(t)
+	v := &ChainVerifier{Keys: map[string]*ecdsa.PublicKey{a.id: &a.priv.PublicKey}}
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		commitSigned(t, s, epoch, "entropy", a)
+	}
+	// confirmation of unknown node
+	commitSigned(t, s, 6, "entropy", a, b)
+
+	// entropy replaced, hash is recomputed but the next block links to the original one
+	forked := getBlock(t, s, 2)
+	forked.WinnerEntropy, _ = node.EncodeEntropy("forged")
+	forked.Hash = forked.ComputeHash()
+	putBlock(t, s, 2, forked)
+	// block changed without hash
+	tampered := getBlock(t, s, 4)
+	tampered.Rst = 2000
+	putBlock(t, s, 4, tampered)
+	// block of epoch 1 stored again as epoch 8 after a gap
+	putBlock(t, s, 8, getBlock(t, s, 1))
+
+	issues, checked, err := s.VerifyChain(v)
+	require.NoError(t, err)
+	require.Equal(t, 7, checked)
+	kinds := issueKinds(issues)
+	require.Equal(t, []string{IssueSignature}, kinds[2])
+	require.Equal(t, []string{IssueFork}, kinds[3])
+	require.Equal(t, []string{IssueHash, IssueSignature}, kinds[4])
+	require.Equal(t, []string{IssueSignature}, kinds[6])
+	require.Equal(t, []string{IssueGap, IssueDuplicate}, kinds[8])
+	require.Len(t, kinds, 5)
+}
+
+func TestVerifyChainReportsMissingQuorum(t *testing.T) {
+	s, done := testStore(t)
+	defer done()
+	signers := []*testSigner{newTestSigner(t), newTestSigner(t), newTestSigner(t), newTestSigner(t)}
+	v := &ChainVerifier{Keys: make(map[string]*ecdsa.PublicKey)}
+	for _, signer := range signers {
+		v.Keys[signer.id] = &signer.priv.PublicKey
+	}
+	commitSigned(t, s, 1, "entropy", signers[:3]...)
+	// not confirmed
+	commitSigned(t, s, 2, "entropy")
+	// confirmed by f+1 nodes only
+	commitSigned(t, s, 3, "entropy", signers[:2]...)
+	// one node confirmed twice
+	commitSigned(t, s, 4, "entropy", signers[0], signers[1], signers[1])
+
+	issues, checked, err := s.VerifyChain(v)
+	require.NoError(t, err)
+	require.Equal(t, 4, checked)
+	kinds := issueKinds(issues)
+	for epoch := uint64(2); epoch <= 4; epoch++ {
+		require.Equal(t, []string{IssueSignature}, kinds[epoch])
+	}
+	require.Len(t, kinds, 3)
+}
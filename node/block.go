@@ -1,23 +1,352 @@
 package node
 
-import "fmt"
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/gob"
+	"fmt"
+	pb "rounds/ledger/pb"
+	"time"
+)
+
+// Confirmation signature of a node on confirm message for committed entropy,
+// with threshold signature share of the block if threshold signing is enabled
+type Confirmation struct {
+	From      string
+	Signature []byte
+	Share     []byte
+	// View confirmation is signed in, only for engines which change views within a round
+	View uint64
+}
 
 type BlockData struct {
-	Timestamp     int64
+	// Epoch block is committed at, ledger refuses block if another one is committed at the epoch
+	Epoch     uint64
+	Timestamp int64
+	// Rst start time of the round block is committed in
+	Rst           int64
 	WinnerEntropy []byte
+	// Proposers nodes whose proposals the winner entropy is made of
+	Proposers []string
+	// Confirmations quorum of nodes confirmed winner entropy
+	Confirmations []*Confirmation
+	// QC quorum certificate of entropy, only for engines which certify proposals
+	QC *QuorumCert
+	// Proofs VRF proofs of entropies combined into the pulse, only for engines which combine node entropies
+	Proofs []*VrfProof
+	// Signature threshold group signature of block epoch and winner entropy, empty if threshold signing is disabled
+	Signature []byte
 }
 
+// Block committed pulse, blocks are chained by hash of the previous block
 type Block struct {
 	Epoch         uint64
+	PrevHash      []byte
+	Hash          []byte
 	Timestamp     int64
+	Rst           int64
 	WinnerEntropy []byte
+	Proposers     []string
+	Confirmations []*Confirmation
+	QC            *QuorumCert
+	Proofs        []*VrfProof
+	Signature     []byte
+}
+
+// NewBlockData encodes winner entropy proposed by proposers in round rst for commit at epoch with confirmations collected for it
+func NewBlockData(epoch uint64, rst int64, proposers []string, entropy string, confirmations []*Confirmation) (BlockData, error) {
+	winnerEntropy, err := EncodeEntropy(entropy)
+	if err != nil {
+		return BlockData{}, err
+	}
+	return BlockData{
+		epoch,
+		time.Now().Unix(),
+		rst,
+		winnerEntropy,
+		proposers,
+		confirmations,
+		nil,
+		nil,
+		nil,
+	}, nil
+}
+
+// NewBlock block of data to be chained at data epoch
+func NewBlock(d BlockData) *Block {
+	return &Block{
+		Epoch:         d.Epoch,
+		Timestamp:     d.Timestamp,
+		Rst:           d.Rst,
+		WinnerEntropy: d.WinnerEntropy,
+		Proposers:     d.Proposers,
+		Confirmations: d.Confirmations,
+		QC:            d.QC,
+		Proofs:        d.Proofs,
+		Signature:     d.Signature,
+	}
+}
+
+// Data block data to commit block to another chain
+func (b *Block) Data() BlockData {
+	return BlockData{
+		b.Epoch,
+		b.Timestamp,
+		b.Rst,
+		b.WinnerEntropy,
+		b.Proposers,
+		b.Confirmations,
+		b.QC,
+		b.Proofs,
+		b.Signature,
+	}
+}
+
+// ChainBlock links block to the latest one if block epoch is the next epoch, not set epoch is the next one,
+// block of already committed epoch isn't linked, committed block get returns for the epoch is returned instead,
+// block without epoch of the latest block round and entropy is a retried append, so the latest block is returned
+func ChainBlock(b *Block, latest *Block, get func(epoch uint64) (*Block, error)) (*Block, error) {
+	var latestEpoch uint64
+	if latest != nil {
+		latestEpoch = latest.Epoch
+	}
+	if b.Epoch == 0 {
+		if latest != nil && latest.Rst == b.Rst && bytes.Equal(latest.WinnerEntropy, b.WinnerEntropy) {
+			return latest, nil
+		}
+		b.Epoch = latestEpoch + 1
+	}
+	if b.Epoch > latestEpoch+1 {
+		return nil, ErrEpochAhead(b.Epoch, latestEpoch)
+	}
+	if b.Epoch <= latestEpoch {
+		existing, err := get(b.Epoch)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, ErrEpochMissing(b.Epoch)
+		}
+		return existing, nil
+	}
+	b.Chain(latest)
+	return b, nil
+}
+
+// VerifyCommitted checks block ChainBlock didn't link has the entropy of existing block committed at its epoch,
+// so commit of the same entropy is a no-op and commit of another one is ErrEpochConflict
+func VerifyCommitted(existing *Block, b *Block) error {
+	if !bytes.Equal(existing.WinnerEntropy, b.WinnerEntropy) {
+		return ErrEpochConflict(existing.Epoch)
+	}
+	return nil
+}
+
+// DecodeEntropy decodes entropy from block winner entropy
+func DecodeEntropy(winnerEntropy []byte) (string, error) {
+	var entropy string
+	if err := gob.NewDecoder(bytes.NewReader(winnerEntropy)).Decode(&entropy); err != nil {
+		return "", err
+	}
+	return entropy, nil
+}
+
+// VerifyConfirmations checks block is confirmed by 2f+1 distinct nodes of known keys for block entropy,
+// confirmations are sent in the round of the block on top of the previous epoch, or with certified proposal rst and epoch
+func (b *Block) VerifyConfirmations(keys map[string]*ecdsa.PublicKey) error {
+	entropy, err := DecodeEntropy(b.WinnerEntropy)
+	if err != nil {
+		return err
+	}
+	rst, epoch := b.Rst, b.Epoch-1
+	if b.QC != nil {
+		rst, epoch = b.QC.Rst, b.QC.Epoch
+	}
+	confirmed := make(map[string]bool, len(b.Confirmations))
+	for _, c := range b.Confirmations {
+		pub, ok := keys[c.From]
+		if !ok {
+			return ErrUnknownPeer(c.From)
+		}
+		if confirmed[c.From] {
+			return ErrDuplicateConfirmation(c.From)
+		}
+		confirm := PulseConfirmPayload{c.Signature, rst, epoch, c.From, entropy, c.Share, c.View}
+		if !VerifySignature(pub, confirm.SigningData(), c.Signature) {
+			return ErrInvalidConfirmation(c.From)
+		}
+		confirmed[c.From] = true
+	}
+	if quorum := ConfirmationQuorum(len(keys)); len(confirmed) < quorum {
+		return ErrNotEnoughConfirmations(b.Epoch, len(confirmed), quorum)
+	}
+	return nil
+}
+
+// ConfirmationQuorum 2f+1 distinct confirmations block of cluster of total nodes needs
+func ConfirmationQuorum(totalNodes int) int {
+	return 2*FaultTolerance(totalNodes) + 1
+}
+
+// ComputeHash sha256 of canonical encoding of all block fields but hash
+func (b *Block) ComputeHash() []byte {
+	e := newCanonicalEncoder(blockHashDomain)
+	e.Uint64(b.Epoch)
+	e.Blob(b.PrevHash)
+	e.Int64(b.Timestamp)
+	e.Int64(b.Rst)
+	e.Blob(b.WinnerEntropy)
+	e.Strings(b.Proposers)
+	e.Confirmations(b.Confirmations)
+	e.Uint64(uint64(len(b.Proofs)))
+	for _, p := range b.Proofs {
+		e.String(p.From)
+		e.Blob(p.Proof)
+	}
+	e.QuorumCert(b.QC)
+	e.Blob(b.Signature)
+	h := sha256.Sum256(e.Bytes())
+	return h[:]
+}
+
+// AgreedHash sha256 of block content nodes agree on: epoch, round, winner entropy and group signature,
+// links, timestamps, proposers and confirmations may differ between ledgers of nodes
+func (b *Block) AgreedHash() []byte {
+	e := newCanonicalEncoder(agreedHashDomain)
+	e.Uint64(b.Epoch)
+	e.Int64(b.Rst)
+	e.Blob(b.WinnerEntropy)
+	e.Blob(b.Signature)
+	h := sha256.Sum256(e.Bytes())
+	return h[:]
+}
+
+// Chain links block to the previous one, genesis block has no previous block and gets epoch 1
+func (b *Block) Chain(prev *Block) {
+	b.Epoch = 1
+	b.PrevHash = nil
+	if prev != nil {
+		b.Epoch = prev.Epoch + 1
+		b.PrevHash = prev.Hash
+	}
+	b.Hash = b.ComputeHash()
+}
+
+func (b *Block) ToPb() *pb.Block {
+	return &pb.Block{
+		Epoch:         b.Epoch,
+		PrevHash:      b.PrevHash,
+		Hash:          b.Hash,
+		Timestamp:     b.Timestamp,
+		Rst:           b.Rst,
+		Entropy:       b.WinnerEntropy,
+		Proposers:     b.Proposers,
+		Confirmations: ConfirmationsToPb(b.Confirmations),
+		Qc:            b.QC.ToPb(),
+		Proofs:        VrfProofsToPb(b.Proofs),
+		Signature:     b.Signature,
+	}
+}
+
+func BlockFromPb(b *pb.Block) *Block {
+	return &Block{
+		b.GetEpoch(),
+		b.GetPrevHash(),
+		b.GetHash(),
+		b.GetTimestamp(),
+		b.GetRst(),
+		b.GetEntropy(),
+		b.GetProposers(),
+		ConfirmationsFromPb(b.GetConfirmations()),
+		QuorumCertFromPb(b.GetQc()),
+		VrfProofsFromPb(b.GetProofs()),
+		b.GetSignature(),
+	}
+}
+
+// EncodeEntropy encodes entropy as block winner entropy
+func EncodeEntropy(entropy string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(entropy); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 func (b *Block) String() string {
 	return fmt.Sprintf(
-		"[epoch: %d, ts: %d, winner_entropy: %s]",
+		"[epoch: %d, hash: %x, prev: %x, ts: %d, winner_entropy: %s, confirmations: %d]",
 		b.Epoch,
+		b.Hash,
+		b.PrevHash,
 		b.Timestamp,
 		b.WinnerEntropy,
+		len(b.Confirmations),
 	)
 }
+
+func (c *Confirmation) ToPb() *pb.Confirmation {
+	return &pb.Confirmation{
+		From:      c.From,
+		Signature: c.Signature,
+		Share:     c.Share,
+		View:      c.View,
+	}
+}
+
+func ConfirmationFromPb(c *pb.Confirmation) *Confirmation {
+	return &Confirmation{
+		c.GetFrom(),
+		c.GetSignature(),
+		c.GetShare(),
+		c.GetView(),
+	}
+}
+
+func ConfirmationsToPb(cs []*Confirmation) []*pb.Confirmation {
+	res := make([]*pb.Confirmation, 0, len(cs))
+	for _, c := range cs {
+		res = append(res, c.ToPb())
+	}
+	return res
+}
+
+func ConfirmationsFromPb(cs []*pb.Confirmation) []*Confirmation {
+	res := make([]*Confirmation, 0, len(cs))
+	for _, c := range cs {
+		res = append(res, ConfirmationFromPb(c))
+	}
+	return res
+}
+
+func (p *VrfProof) ToPb() *pb.VrfProof {
+	return &pb.VrfProof{
+		From:  p.From,
+		Proof: p.Proof,
+	}
+}
+
+func VrfProofFromPb(p *pb.VrfProof) *VrfProof {
+	return &VrfProof{
+		p.GetFrom(),
+		p.GetProof(),
+	}
+}
+
+func VrfProofsToPb(ps []*VrfProof) []*pb.VrfProof {
+	res := make([]*pb.VrfProof, 0, len(ps))
+	for _, p := range ps {
+		res = append(res, p.ToPb())
+	}
+	return res
+}
+
+func VrfProofsFromPb(ps []*pb.VrfProof) []*VrfProof {
+	res := make([]*VrfProof, 0, len(ps))
+	for _, p := range ps {
+		res = append(res, VrfProofFromPb(p))
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+package node
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testBlock(t *testing.T, entropy string) *Block {
+	winnerEntropy, err := EncodeEntropy(entropy)
+	require.NoError(t, err)
+	return &Block{
+		Timestamp:     1000,
+		Rst:           1000,
+		WinnerEntropy: winnerEntropy,
+		Proposers:     []string{"a", "b"},
+		Confirmations: []*Confirmation{{"a", []byte("sig-a"), nil, 0}, {"b", []byte("sig-b"), []byte("share-b"), 1}},
+		Proofs:        []*VrfProof{{"a", []byte("proof-a")}},
+	}
+}
+
+func TestBlockChain(t *testing.T) {
+	genesis := testBlock(t, "first")
+	genesis.Chain(nil)
+	require.Equal(t, uint64(1), genesis.Epoch)
+	require.Empty(t, genesis.PrevHash)
+	require.Equal(t, genesis.ComputeHash(), genesis.Hash)
+
+	next := testBlock(t, "second")
+	next.Chain(genesis)
+	require.Equal(t, uint64(2), next.Epoch)
+	require.Equal(t, genesis.Hash, next.PrevHash)
+	require.NotEqual(t, genesis.Hash, next.Hash)
+
+	// any change of block data changes its hash
+	tampered := testBlock(t, "second")
+	tampered.Chain(genesis)
+	require.Equal(t, next.Hash, tampered.Hash)
+	tampered.Confirmations[1].Share = []byte("other")
+	require.NotEqual(t, next.Hash, tampered.ComputeHash())
+	tampered = testBlock(t, "second")
+	tampered.Proposers = []string{"a"}
+	tampered.Chain(genesis)
+	require.NotEqual(t, next.Hash, tampered.Hash)
+}
+
+func TestBlockPbRoundTrip(t *testing.T) {
+	b := testBlock(t, "entropy")
+	b.QC = &QuorumCert{1, 0, 1000, "entropy", []*Confirmation{{"a", []byte("sig-a"), nil, 0}}, "a"}
+	b.Signature = []byte("group")
+	b.Chain(nil)
+	decoded := BlockFromPb(b.ToPb())
+	require.Equal(t, b.Hash, decoded.ComputeHash())
+	require.Equal(t, b.Proposers, decoded.Proposers)
+	require.Equal(t, b.QC, decoded.QC)
+}
+
+func TestBlockVerifyConfirmations(t *testing.T) {
+	nodes := []*Node{signingNode(t), signingNode(t), signingNode(t), signingNode(t)}
+	trust(t, nodes[0], nodes[1:]...)
+	keys := nodes[0].publicKeys()
+	confirmed := func(confirmers ...*Node) *Block {
+		b := testBlock(t, "entropy")
+		b.Epoch = 1
+		b.Confirmations = nil
+		for _, n := range confirmers {
+			b.Confirmations = append(b.Confirmations, signedConfirm(n, 0, 1000, "entropy").GetPayload().(*Confirmation))
+		}
+		return b
+	}
+
+	require.NoError(t, confirmed(nodes[0], nodes[1], nodes[2]).VerifyConfirmations(keys))
+	require.NoError(t, confirmed(nodes...).VerifyConfirmations(keys))
+	require.Equal(t, ErrNotEnoughConfirmations(1, 0, 3).Error(), confirmed().VerifyConfirmations(keys).Error())
+	require.Equal(t, ErrNotEnoughConfirmations(1, 2, 3).Error(), confirmed(nodes[0], nodes[1]).VerifyConfirmations(keys).Error())
+	// confirmation repeated doesn't count for another node
+	require.Equal(t, ErrDuplicateConfirmation(nodes[1].ID).Error(), confirmed(nodes[0], nodes[1], nodes[1]).VerifyConfirmations(keys).Error())
+	// node out of cluster doesn't count
+	delete(keys, nodes[3].ID)
+	require.Equal(t, ErrUnknownPeer(nodes[3].ID).Error(), confirmed(nodes[0], nodes[1], nodes[3]).VerifyConfirmations(keys).Error())
+}
@@ -0,0 +1,181 @@
+package node
+
+import (
+	"context"
+	"encoding/binary"
+	"github.com/golang/protobuf/proto"
+	ceteerrors "github.com/mosuka/cete/errors"
+	"github.com/mosuka/cete/kvs"
+	cetepb "github.com/mosuka/cete/protobuf/kvs"
+	"github.com/pkg/errors"
+	"log"
+	testBadgerPb "rounds/ledger/pb"
+	"rounds/logger"
+	"sync"
+)
+
+var (
+	ceteBlockPrefix    = []byte("b/")
+	ceteEvidencePrefix = []byte("e/")
+	// ceteLatestEpochKey epoch of the latest block, maintained on commit because cete has no iterator
+	ceteLatestEpochKey = []byte("m/latest_epoch")
+)
+
+func ceteBlockKey(epoch uint64) []byte {
+	k := make([]byte, len(ceteBlockPrefix)+8)
+	copy(k, ceteBlockPrefix)
+	binary.BigEndian.PutUint64(k[len(ceteBlockPrefix):], epoch)
+	return k
+}
+
+// CeteStorage stores blocks in cete cluster, block is put before the latest epoch key,
+// so the latest epoch always points to a stored block,
+// cete has no transactions nor conditional puts, so blocks must be committed by a single writer,
+// commits of the writer are serialized and block is never put over an existing one
+type CeteStorage struct {
+	client *kvs.GRPCClient
+	// mu serializes commits
+	mu sync.Mutex
+
+	log *logger.Logger
+}
+
+func (m *CeteStorage) get(key []byte) ([]byte, error) {
+	kvp, err := m.client.Get(&cetepb.KeyValuePair{Key: key})
+	if err == ceteerrors.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return kvp.Value, nil
+}
+
+func (m *CeteStorage) put(key []byte, value []byte) error {
+	return m.client.Put(&cetepb.KeyValuePair{Key: key, Value: value})
+}
+
+func (m *CeteStorage) latestEpoch() (uint64, error) {
+	v, err := m.get(ceteLatestEpochKey)
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	if len(v) != 8 {
+		return 0, errors.New("invalid latest epoch record")
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+func (m *CeteStorage) GetLatestBlockEpoch() uint64 {
+	epoch, err := m.latestEpoch()
+	if err != nil {
+		m.log.Error(err)
+	}
+	m.log.Infof("latest pulse number received: %d", epoch)
+	return epoch
+}
+
+func (m *CeteStorage) GetBlock(epoch uint64) (*Block, error) {
+	data, err := m.get(ceteBlockKey(epoch))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var b testBadgerPb.Block
+	if err := proto.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return BlockFromPb(&b), nil
+}
+
+func (m *CeteStorage) GetLatestBlock() (*Block, error) {
+	epoch, err := m.latestEpoch()
+	if err != nil || epoch == 0 {
+		return nil, err
+	}
+	return m.GetBlock(epoch)
+}
+
+// GetBlockRange gets blocks one by one up to the latest epoch
+func (m *CeteStorage) GetBlockRange(from uint64, limit int) ([]*Block, uint64, error) {
+	latest, err := m.latestEpoch()
+	if err != nil {
+		return nil, 0, err
+	}
+	if from == 0 {
+		from = 1
+	}
+	blocks := make([]*Block, 0)
+	epoch := from
+	for ; epoch <= latest && len(blocks) < limit; epoch++ {
+		b, err := m.GetBlock(epoch)
+		if err != nil {
+			return nil, 0, err
+		}
+		if b != nil {
+			blocks = append(blocks, b)
+		}
+	}
+	if epoch > latest {
+		return blocks, 0, nil
+	}
+	return blocks, epoch, nil
+}
+
+// Commit chains block to the previous epoch block, block key is checked right before put,
+// so block put by another writer after the latest epoch was read isn't overwritten
+func (m *CeteStorage) Commit(ctx context.Context, d BlockData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	latest, err := m.GetLatestBlock()
+	if err != nil {
+		return err
+	}
+	b := NewBlock(d)
+	stored, err := ChainBlock(b, latest, m.GetBlock)
+	if err != nil {
+		return err
+	}
+	if stored != b {
+		return VerifyCommitted(stored, b)
+	}
+	existing, err := m.GetBlock(b.Epoch)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return VerifyCommitted(existing, b)
+	}
+	m.log.Infof("committing pulse: %s", b.String())
+	data, err := proto.Marshal(b.ToPb())
+	if err != nil {
+		return err
+	}
+	if err := m.put(ceteBlockKey(b.Epoch), data); err != nil {
+		return err
+	}
+	var epoch [8]byte
+	binary.BigEndian.PutUint64(epoch[:], b.Epoch)
+	return m.put(ceteLatestEpochKey, epoch[:])
+}
+
+func (m *CeteStorage) CommitEvidence(ctx context.Context, e *Evidence) error {
+	data, err := proto.Marshal(e.ToPb())
+	if err != nil {
+		return err
+	}
+	return m.put(append(append([]byte{}, ceteEvidencePrefix...), e.Key()...), data)
+}
+
+func NewCeteStorage(host string) *CeteStorage {
+	s, err := kvs.NewGRPCClient(host)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return &CeteStorage{
+		client: s,
+		log:    logger.NewLogger(),
+	}
+}
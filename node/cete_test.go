@@ -0,0 +1,108 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/protobuf/proto"
+	"github.com/mosuka/cete/kvs"
+	cetepb "github.com/mosuka/cete/protobuf/kvs"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+// testCete starts single node cete cluster in process and waits until it's leader
+func testCete(t *testing.T) (*CeteStorage, func()) {
+	dir, err := ioutil.TempDir("", "cete")
+	require.NoError(t, err)
+	l := log.New(ioutil.Discard, "", 0)
+	grpcAddr := freeAddr(t)
+	srv, err := kvs.NewServer("node1", freeAddr(t), grpcAddr, freeAddr(t), dir, "", l, l)
+	require.NoError(t, err)
+	srv.Start()
+	s := NewCeteStorage(grpcAddr)
+	require.Eventually(t, func() bool {
+		return s.put([]byte("m/probe"), []byte{1}) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+	return s, func() {
+		s.client.Close()
+		srv.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestCeteStorage(t *testing.T) {
+	s, done := testCete(t)
+	defer done()
+	ctx := context.Background()
+
+	latest, err := s.GetLatestBlock()
+	require.NoError(t, err)
+	require.Nil(t, latest)
+	require.Equal(t, uint64(0), s.GetLatestBlockEpoch())
+
+	for epoch := uint64(1); epoch <= 3; epoch++ {
+		d, err := NewBlockData(epoch, 1000, []string{"N0"}, "entropy", nil)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit(ctx, d))
+	}
+	require.Equal(t, uint64(3), s.GetLatestBlockEpoch())
+	latest, err = s.GetLatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), latest.Epoch)
+	prev, err := s.GetBlock(2)
+	require.NoError(t, err)
+	require.Equal(t, prev.Hash, latest.PrevHash)
+
+	// retry is a no-op, another entropy at committed epoch conflicts
+	d, err := NewBlockData(3, 1000, []string{"N0"}, "entropy", nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Commit(ctx, d))
+	d, err = NewBlockData(3, 1000, []string{"N0"}, "another", nil)
+	require.NoError(t, err)
+	require.True(t, errors.Is(s.Commit(ctx, d), ErrConflict))
+	d, err = NewBlockData(5, 1000, []string{"N0"}, "entropy", nil)
+	require.NoError(t, err)
+	require.Error(t, s.Commit(ctx, d))
+	require.Equal(t, uint64(3), s.GetLatestBlockEpoch())
+
+	// block put by another writer before it updated the latest epoch isn't overwritten
+	d, err = NewBlockData(4, 1000, []string{"N0"}, "other writer", nil)
+	require.NoError(t, err)
+	data, err := proto.Marshal(NewBlock(d).ToPb())
+	require.NoError(t, err)
+	require.NoError(t, s.put(ceteBlockKey(4), data))
+	d, err = NewBlockData(4, 1000, []string{"N0"}, "entropy", nil)
+	require.NoError(t, err)
+	require.True(t, errors.Is(s.Commit(ctx, d), ErrConflict))
+	require.Equal(t, uint64(3), s.GetLatestBlockEpoch())
+	require.NoError(t, s.client.Delete(&cetepb.KeyValuePair{Key: ceteBlockKey(4)}))
+
+	blocks, next, err := s.GetBlockRange(1, 2)
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.Equal(t, uint64(3), next)
+	blocks, next, err = s.GetBlockRange(next, 2)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, uint64(0), next)
+
+	require.NoError(t, s.CommitEvidence(ctx, &Evidence{Offender: "N1", Epoch: 2}))
+}
+
+func TestCeteStorageBlockRange(t *testing.T) {
+	s, done := testCete(t)
+	defer done()
+	testBlockRange(t, s)
+}
@@ -18,6 +18,8 @@ import (
 type Clienter interface {
 	// Broadcast sends a message to all peers
 	Broadcast(context.Context, interface{}) error
+	// Send sends a message to peer by address
+	Send(ctx context.Context, addr string, msg interface{}) error
 }
 
 type TCPClient struct {
@@ -102,6 +104,22 @@ func (m *TCPClient) Broadcast(ctx context.Context, msg interface{}) error {
 	return nil
 }
 
+// Send sends message to peer by address, connection is dropped on failure so it's reconnected later
+func (m *TCPClient) Send(ctx context.Context, addr string, msg interface{}) error {
+	c := m.Conns[addr]
+	if c == nil {
+		return ErrPeerNotConnected(addr)
+	}
+	if err := json.NewEncoder(c).Encode(msg); err != nil {
+		if err := c.Close(); err != nil {
+			m.log.Errorf("failed to close peer connection: %s", c.LocalAddr())
+		}
+		m.Conns[addr] = nil
+		return err
+	}
+	return nil
+}
+
 type UDPClient struct {
 	cfg   *Config
 	Addr  string
@@ -187,6 +205,15 @@ func (m *UDPClient) Broadcast(ctx context.Context, msg interface{}) error {
 	return nil
 }
 
+// Send sends message to peer by address
+func (m *UDPClient) Send(ctx context.Context, addr string, msg interface{}) error {
+	c := m.Conns[addr]
+	if c == nil {
+		return ErrPeerNotConnected(addr)
+	}
+	return json.NewEncoder(c).Encode(msg)
+}
+
 func tlsContexts() (*tls.Config, *tls.Config) {
 	certClient, err := tls.LoadX509KeyPair("certs/client.pem", "certs/client.key")
 	if err != nil {
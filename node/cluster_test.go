@@ -0,0 +1,80 @@
+package node
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func freeUDPAddr(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.LocalAddr().String()
+}
+
+// testClusterConfigs configs of cluster nodes with keys written to temp dirs
+func testClusterConfigs(t *testing.T, size int) ([]*Config, func()) {
+	dirs := make([]string, 0, size)
+	addrs := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		dir, err := ioutil.TempDir("", "keys")
+		require.NoError(t, err)
+		priv, pub := generateNewKeyPair()
+		privPem, pubPem := EncodeKeyPair(priv, pub)
+		require.NoError(t, ioutil.WriteFile(path.Join(dir, privKeyFile), []byte(privPem), 0600))
+		require.NoError(t, ioutil.WriteFile(path.Join(dir, pubKeyFile), []byte(pubPem), 0600))
+		dirs = append(dirs, dir)
+		addrs = append(addrs, freeUDPAddr(t))
+	}
+	configs := make([]*Config, 0, size)
+	for i := 0; i < size; i++ {
+		c := &Config{}
+		c.Node.Keyspath = dirs[i]
+		c.Node.Addr = addrs[i]
+		for j := 0; j < size; j++ {
+			if j != i {
+				c.Node.Peers = append(c.Node.Peers, Peer{Addr: addrs[j], PubKeyDir: dirs[j]})
+			}
+		}
+		c.Node.Rounds.PaceMs = 1000
+		c.Node.Rounds.Collect.MaxMessages = 500
+		c.Node.Rounds.Collect.Duration = 200
+		c.Node.Rounds.Exchange.MaxMessages = 500
+		c.Node.Rounds.Exchange.Duration = 200
+		c.Node.Reconnect = 1
+		c.Node.Transport = "udp"
+		c.Store.Type = MemoryStorageKind
+		configs = append(configs, c)
+	}
+	return configs, func() {
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+func TestClusterCommitsToMemoryStorage(t *testing.T) {
+	configs, done := testClusterConfigs(t, 4)
+	defer done()
+	store := NewMemoryStorage()
+	for _, c := range configs {
+		priv, pub, pubPem := LoadKeyPair(c)
+		n := NewNode(c, priv, pub, pubPem, WithStorage(store))
+		go n.StartTransport()
+		go n.Schedule(c)
+		go n.Processing()
+	}
+
+	require.Eventually(t, func() bool { return store.GetLatestBlockEpoch() >= 2 }, 20*time.Second, 100*time.Millisecond)
+	first, err := store.GetBlock(1)
+	require.NoError(t, err)
+	second, err := store.GetBlock(2)
+	require.NoError(t, err)
+	require.Equal(t, first.Hash, second.PrevHash)
+	require.NotEmpty(t, second.Confirmations)
+}
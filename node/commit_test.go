@@ -1,59 +1,115 @@
 package node
 
 import (
-	"github.com/spf13/viper"
+	"fmt"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
 
 func basicCons() *PulseConsensus {
-	viper.SetDefault("logging.level", "debug")
-	viper.SetDefault("logging.encoding", "console")
+	return clusterCons(4)
+}
+
+func clusterCons(totalNodes int) *PulseConsensus {
 	return NewPulseConsensus(
+		totalNodes,
 		500,
 		500,
 		200,
 		200,
+		0,
 	)
 }
 
+// onlineVectors vectors of online nodes, every online node has seen proposals of all online nodes
+func onlineVectors(online int) []*PulseVector {
+	proposals := make([]*PulseProposal, 0)
+	for i := 0; i < online; i++ {
+		proposals = append(proposals, &PulseProposal{From: fmt.Sprintf("N%d", i), Entropy: fmt.Sprintf("%d", i)})
+	}
+	vectors := make([]*PulseVector, 0)
+	for i := 0; i < online; i++ {
+		vectors = append(vectors, &PulseVector{From: fmt.Sprintf("N%d", i), Vector: proposals})
+	}
+	return vectors
+}
+
+func TestClusterThresholds(t *testing.T) {
+	for _, c := range []struct {
+		nodes, faulty, agree, confirm int
+	}{
+		{4, 1, 2, 3},
+		{7, 2, 4, 5},
+		{10, 3, 6, 7},
+	} {
+		cons := clusterCons(c.nodes)
+		require.Equal(t, c.faulty, cons.Faulty)
+		require.Equal(t, c.agree, cons.AgreeThreshold())
+		require.Equal(t, c.confirm, cons.ConfirmQuorum())
+		require.NoError(t, CheckClusterSize(c.nodes))
+	}
+}
+
+func TestDecideWinnerToleratesFaultyNodes(t *testing.T) {
+	for _, nodes := range []int{4, 7, 10} {
+		cons := clusterCons(nodes)
+		cons.PulseVectors = onlineVectors(nodes - cons.Faulty)
+		require.NotEqual(t, NoConsensusStatus, cons.DecideWinner(), "cluster of %d nodes", nodes)
+		require.Len(t, cons.MajorityData, nodes-cons.Faulty)
+	}
+}
+
+func TestDecideWinnerTooManyFaultyNodesNoConsensus(t *testing.T) {
+	for _, nodes := range []int{4, 7, 10} {
+		cons := clusterCons(nodes)
+		cons.PulseVectors = onlineVectors(nodes - cons.Faulty - 1)
+		require.Equal(t, NoConsensusStatus, cons.DecideWinner(), "cluster of %d nodes", nodes)
+	}
+}
+
+func TestClusterTooSmallRefused(t *testing.T) {
+	for _, nodes := range []int{1, 2, 3} {
+		require.Error(t, CheckClusterSize(nodes))
+	}
+}
+
 func TestDecideWinnerFound(t *testing.T) {
 	cons := basicCons()
 	versions := []*PulseVector{
 		{
 			From: "A",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 		{
 			From: "B",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 		{
 			From: "C",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 		{
 			From: "D",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 	}
@@ -68,28 +124,28 @@ func TestDecideWinnerStrictMajority(t *testing.T) {
 		{
 			From: "A",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 		{
 			From: "B",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 		{
 			From: "C",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
 			},
 		},
 	}
@@ -104,15 +160,15 @@ func TestDecideWinnerNotEnoughNodesNoConsensus(t *testing.T) {
 		{
 			From: "A",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
 			},
 		},
 		{
 			From: "B",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
 			},
 		},
 	}
@@ -127,18 +183,18 @@ func TestDecideWinnerAdditionalDataNoConsensus(t *testing.T) {
 		{
 			From: "A",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
-				{"C", "3"},
-				{"D", "4"},
-				{"E", "5"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
+				{From: "C", Entropy: "3"},
+				{From: "D", Entropy: "4"},
+				{From: "E", Entropy: "5"},
 			},
 		},
 		{
 			From: "B",
 			Vector: []*PulseProposal{
-				{"A", "1"},
-				{"B", "2"},
+				{From: "A", Entropy: "1"},
+				{From: "B", Entropy: "2"},
 			},
 		},
 	}
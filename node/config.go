@@ -14,9 +14,13 @@ type Config struct {
 		Keyspath string `validate:"required"`
 		Addr     string `validate:"required"`
 		Peers    []Peer `validate:"required"`
-		Rounds   struct {
-			PaceMs  int `yaml:"paceMs"`
-			Collect struct {
+		// Consensus engine name, pulse if not set
+		Consensus string
+		Rounds    struct {
+			PaceMs int `yaml:"paceMs"`
+			// RstTolerance how many seconds round start time of peer message may differ from local one
+			RstTolerance int64 `yaml:"rstTolerance"`
+			Collect      struct {
 				MaxMessages int `json:"max_messages"`
 				Duration    int `json:"duration"`
 			} `validate:"required"`
@@ -27,10 +31,19 @@ type Config struct {
 		}
 		Reconnect int    `json:"reconnect" validate:"required"`
 		Transport string `json:"transport" validate:"required"`
+		// ExcludeEquivocators ignores peer messages after it's caught sending conflicting messages
+		ExcludeEquivocators bool `yaml:"excludeEquivocators"`
+		// Threshold signs committed blocks with group key, shares are generated by keygen dkg into keyspath
+		Threshold bool
 	}
 	Opencensus telemetry.OpencensusConfig
 	Store      struct {
-		Host string `validate:"required"`
+		// Type storage backend, ledger, cete, memory or embedded, ledger if not set
+		Type string
+		// Host storage address, embedded ledger serves ledger api on it if set, not used by memory storage
+		Host string
+		// Path db path of embedded ledger
+		Path string
 	} `validate:"required"`
 	Logging struct {
 		Level string
@@ -38,6 +51,8 @@ type Config struct {
 }
 
 type Peer struct {
+	// ID optional node id, pins peer to public key fingerprint
+	ID        string `json:"id"`
 	Addr      string
 	Port      string
 	PubKeyDir string `json:"pubkeydir"`
@@ -75,3 +90,15 @@ func ValidateConfig(cfg interface{}) {
 		log.Fatal(err)
 	}
 }
+
+// ClusterSize configured peers plus self
+func (c *Config) ClusterSize() int {
+	return len(c.Node.Peers) + 1
+}
+
+// ValidateCluster refuses cluster configurations that can't tolerate a faulty node
+func ValidateCluster(c *Config) {
+	if err := CheckClusterSize(c.ClusterSize()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+package node
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type stubClient struct {
+	sent []interface{}
+}
+
+func (m *stubClient) Broadcast(ctx context.Context, msg interface{}) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *stubClient) Send(ctx context.Context, addr string, msg interface{}) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func signedConfirm(n *Node, epoch uint64, rst int64, entropy string) PulseConfirmPayload {
+	cm := NewPulseConfirmMessage(n.ID, epoch, rst, entropy)
+	cm.Payload.Signature = n.Sign(cm.Payload.SigningData())
+	return cm.Payload
+}
+
+// winningRound prepares consensus where self proposal wins among 4 nodes, only self commitment is revealed,
+// returns entropy of the pulse
+func winningRound(t *testing.T) (*PulseConsensus, *Node, []*Node, string) {
+	cons := basicCons()
+	self := signingNode(t)
+	self.store = newStubStorage()
+	self.client = &stubClient{}
+	peers := []*Node{signingNode(t), signingNode(t), signingNode(t)}
+	trust(t, self, peers...)
+	cons.SetRoundStartTime(1000)
+
+	// only self proposal is seen by all peers
+	cons.Alpha = VrfInput(nil, 1)
+	beta, proof := self.ProveVrf(cons.Alpha)
+	cons.SelfReveal = VrfEntropy(beta)
+	commitment := Commitment(self.ID, cons.SelfReveal)
+	cons.SelfProposal = NewPulseProposal(self.ID, commitment)
+	require.True(t, cons.AddProof(commitment, &VrfProof{self.ID, proof}, self))
+	vector := []*PulseProposal{cons.SelfProposal}
+	for _, p := range peers {
+		vector = append(vector, NewPulseProposal(p.ID, "entropy-"+p.ID))
+		cons.PulseVectors = append(cons.PulseVectors, &PulseVector{p.ID, []*PulseProposal{cons.SelfProposal}})
+	}
+	cons.PulseVectors = append(cons.PulseVectors, &PulseVector{self.ID, vector})
+	require.Equal(t, commitment, cons.DecideWinner())
+	return cons, self, peers, CombineReveals(cons.MajorityData, cons.Reveals)
+}
+
+func TestCommitAfterConfirmationsQuorum(t *testing.T) {
+	cons, self, peers, entropy := winningRound(t)
+	require.Equal(t, 3, cons.ConfirmQuorum())
+
+	cons.ConfirmChan <- signedConfirm(peers[0], 0, 1000, entropy)
+	// confirmation of other entropy doesn't count
+	cons.ConfirmChan <- signedConfirm(peers[1], 0, 1000, "other")
+	cons.ConfirmChan <- signedConfirm(peers[2], 0, 1000, entropy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cons.Commit(ctx, self)
+
+	// self proposal won, so self proposes reveal set
+	sent := self.client.(*stubClient).sent
+	require.Len(t, sent, 2)
+	require.Len(t, sent[0].(*RevealSetMessage).Payload.Proofs, 1)
+	require.Equal(t, entropy, sent[1].(*PulseConfirmMessage).Payload.Entropy)
+
+	b := <-self.store.(*stubStorage).commits
+	require.Len(t, b.Confirmations, 3)
+	require.Len(t, b.Proofs, 1)
+	require.Equal(t, self.ID, b.Proofs[0].From)
+	require.Equal(t, self.ID, b.Confirmations[0].From)
+	require.Equal(t, peers[0].ID, b.Confirmations[1].From)
+	require.Equal(t, peers[2].ID, b.Confirmations[2].From)
+	for i, c := range b.Confirmations {
+		signer := append([]*Node{self}, peers[0], peers[2])[i]
+		confirm := PulseConfirmPayload{c.Signature, 1000, 0, c.From, entropy, c.Share, c.View}
+		require.True(t, VerifySignature(signer.publicKey, confirm.SigningData(), c.Signature))
+	}
+}
+
+func TestNoCommitWithoutConfirmationsQuorum(t *testing.T) {
+	cons, self, peers, entropy := winningRound(t)
+
+	cons.ConfirmChan <- signedConfirm(peers[0], 0, 1000, entropy)
+	// the same confirmation delivered twice is counted once
+	cons.ConfirmChan <- signedConfirm(peers[0], 0, 1000, entropy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cons.Commit(ctx, self)
+
+	require.Len(t, cons.Confirmations, 2)
+	require.Len(t, self.store.(*stubStorage).commits, 0)
+}
+
+// provenProposal proposal of node committed to its VRF output, returns the proposal, revealed entropy and its proof
+func provenProposal(cons *PulseConsensus, n *Node) (*PulseProposal, string, *VrfProof) {
+	beta, proof := n.ProveVrf(cons.Alpha)
+	reveal := VrfEntropy(beta)
+	p := NewPulseProposal(n.ID, Commitment(n.ID, reveal))
+	return p, reveal, &VrfProof{n.ID, proof}
+}
+
+func TestRevealSetAgreedWhenRevealReachedSomeNodes(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	self.client = &stubClient{}
+	peers := []*Node{signingNode(t), signingNode(t), signingNode(t)}
+	trust(t, self, peers...)
+	for _, p := range peers {
+		trust(t, p, self)
+	}
+	cons.SetRoundStartTime(1000)
+	cons.Alpha = VrfInput(nil, 1)
+
+	proposer, proposerReveal, proposerProof := provenProposal(cons, peers[0])
+	// reveal of peers[2] reached the proposer but not self
+	partial, partialReveal, partialProof := provenProposal(cons, peers[2])
+	cons.MajorityData = []string{proposer.Entropy, partial.Entropy}
+	require.True(t, cons.AddProof(proposer.Entropy, proposerProof, self))
+	local := CombineReveals(cons.MajorityData, cons.Reveals)
+	all := CombineReveals(cons.MajorityData, map[string]string{proposer.Entropy: proposerReveal, partial.Entropy: partialReveal})
+	require.NotEqual(t, local, all)
+
+	// set must have every reveal node has, once per commitment, and only agreed commitments
+	require.Empty(t, cons.VerifyRevealSet(self, []*VrfProof{partialProof}))
+	require.Empty(t, cons.VerifyRevealSet(self, []*VrfProof{proposerProof, partialProof, partialProof}))
+	_, proof := peers[1].ProveVrf(cons.Alpha)
+	require.Empty(t, cons.VerifyRevealSet(self, []*VrfProof{proposerProof, {peers[1].ID, proof}}))
+	require.Len(t, cons.Reveals, 1)
+
+	// confirmation received before reveal set is kept for confirm phase
+	cons.ConfirmChan <- signedConfirm(peers[1], 0, 1000, all)
+	// reveal set of another node is ignored
+	cons.ConfirmChan <- NewSignedRevealSetMessage(peers[1], 1000, []*VrfProof{proposerProof}).Payload
+	cons.ConfirmChan <- NewSignedRevealSetMessage(peers[0], 1000, []*VrfProof{proposerProof, partialProof}).Payload
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	entropy, proofs := cons.AgreeReveals(ctx, self, proposer.Entropy)
+	require.Equal(t, all, entropy)
+	require.Len(t, proofs, 2)
+	require.Equal(t, partialReveal, cons.Reveals[partial.Entropy])
+	require.Len(t, cons.PendingConfirms, 1)
+
+	cons.SendConfirm(ctx, self, entropy)
+	require.False(t, cons.ReceiveConfirms(ctx, self, entropy))
+	require.Len(t, cons.Confirmations, 2)
+}
@@ -1,11 +1,9 @@
 package node
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
+	"encoding/json"
 	"github.com/prometheus/common/log"
-	"rounds/logger"
 	"sort"
 	"strings"
 	"time"
@@ -17,57 +15,45 @@ const (
 
 // Consensus describes abstract rounds of consensus
 type Consensus interface {
-	// Flushes pulses and vectors data
-	FlushData()
-	//GetRoundStartTime
-	GetRoundStartTime() int64
-	//SetRoundStartTime
-	SetRoundStartTime(rst int64)
-	// SendPulses sends pulse data proposals
-	SendPulses(ctx context.Context, n Noder)
-	// SendVectors sends acquired pulse vector
-	SendVectors(ctx context.Context, n Noder)
-	// ReceivePulses collects data
-	ReceivePulses(ctx context.Context, n Noder)
-	// ReceiveVectors receives all vectors from peers
-	ReceiveVectors(ctx context.Context, n Noder)
-	// Commit commits matching set of consensus
-	Commit(ctx context.Context, n Noder)
-	// GetCollectDuration collect round duration
-	GetCollectDuration() int
-	// GetExchangeDuration exchange round duration
-	GetExchangeDuration() int
+	// Name engine name consensus is registered with
+	Name() string
 	// GetStartChan round start channel
 	GetStartChan() chan int64
-	// GetPulsesChan pulse messages
-	GetPulsesChan() chan Messager
-	// GetVectorsChan vector messages
-	GetVectorsChan() chan Messager
+	// Round runs all phases of a round started at round start time
+	Round(rst int64, n Noder)
+	// Receive decodes peer message payload of engine message type and passes it to round
+	Receive(t MsgType, payload json.RawMessage) error
 }
 
 type PulseConsensus struct {
-	Receiving        bool
-	TotalNodes       int
-	CollectDuration  int
-	ExchangeDuration int
-	RoundStartTime   int64
-	StartChan        chan int64
-	PulsesChan       chan Messager
-	VectorChan       chan Messager
-	SelfProposal     *PulseProposal
-	PulseProposals   []*PulseProposal
-	PulseVectors     []*PulseVector
-	MajorityData     []string
-
-	log *logger.Logger
-}
-
-func (r *PulseConsensus) GetRoundStartTime() int64 {
-	return r.RoundStartTime
-}
-
-func (r *PulseConsensus) SetRoundStartTime(rst int64) {
-	r.RoundStartTime = rst
+	RoundBase
+	Receiving  bool
+	TotalNodes int
+	// Faulty max number of byzantine nodes cluster tolerates
+	Faulty         int
+	PulsesChan     chan Messager
+	VectorChan     chan Messager
+	ConfirmChan    chan Messager
+	SelfProposal   *PulseProposal
+	PulseProposals []*PulseProposal
+	PulseVectors   []*PulseVector
+	MajorityData   []string
+	// Confirmations of winner entropy received in commit round, self included
+	Confirmations []*Confirmation
+	// DirectProposals collect messages received from proposers in current round, by sender
+	DirectProposals map[string]PulseMessagePayload
+	// SelfReveal entropy self proposal commits to
+	SelfReveal string
+	// SelfProof VRF proof of self entropy, it's revealed in vector
+	SelfProof []byte
+	// Reveals entropies revealed in vectors which match commitments of their senders, by commitment
+	Reveals map[string]string
+	// Alpha VRF input of the round, previous pulse and epoch of the pulse
+	Alpha []byte
+	// Proofs verified VRF proofs of committed entropies, by commitment
+	Proofs map[string]*VrfProof
+	// PendingConfirms confirm messages received before reveal set is agreed
+	PendingConfirms []Messager
 }
 
 type PulseVector struct {
@@ -83,48 +69,104 @@ func (p *PulseVector) String() string {
 	return strings.Join(vecs, " ")
 }
 
-func NewPulseConsensus(collectDuration int, exchangeDuration int, maxPulsesChan int, maxVectorsChan int) *PulseConsensus {
+func NewPulseConsensus(totalNodes int, collectDuration int, exchangeDuration int, maxPulsesChan int, maxVectorsChan int, rstTolerance int64) *PulseConsensus {
 	return &PulseConsensus{
+		NewRoundBase(collectDuration, exchangeDuration, rstTolerance),
 		false,
-		4,
-		collectDuration,
-		exchangeDuration,
-		0,
-		make(chan int64),
+		totalNodes,
+		FaultTolerance(totalNodes),
 		make(chan Messager, maxPulsesChan),
 		make(chan Messager, maxVectorsChan),
+		make(chan Messager, maxPulsesChan),
 		nil,
 		make([]*PulseProposal, 0),
 		make([]*PulseVector, 0),
 		make([]string, 0),
-		logger.NewLogger(),
+		make([]*Confirmation, 0),
+		make(map[string]PulseMessagePayload),
+		"",
+		nil,
+		make(map[string]string),
+		nil,
+		make(map[string]*VrfProof),
+		make([]Messager, 0),
 	}
 }
 
-func (r *PulseConsensus) GetStartChan() chan int64 {
-	return r.StartChan
+func (r *PulseConsensus) Name() string {
+	return PulseEngine
 }
 
-func (r *PulseConsensus) GetPulsesChan() chan Messager {
-	return r.PulsesChan
-}
+// Round runs collect, exchange and commit phases
+func (r *PulseConsensus) Round(rst int64, n Noder) {
+	r.FlushData()
+	r.SetRoundStartTime(rst)
 
-func (r *PulseConsensus) GetVectorsChan() chan Messager {
-	return r.VectorChan
-}
+	// Send pulses to all
+	ctx, cancel1 := context.WithTimeout(context.Background(), time.Duration(r.GetCollectDuration())*time.Millisecond)
+	defer cancel1()
+	r.SendPulses(ctx, n)
+	r.ReceivePulses(ctx, n)
+
+	// After timeout send vectors to all
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Duration(r.GetExchangeDuration())*time.Millisecond)
+	defer cancel2()
+	r.SendVectors(ctx2, n)
+	r.ReceiveVectors(ctx2, n)
 
-func (r *PulseConsensus) GetCollectDuration() int {
-	return r.CollectDuration
+	// Calculate approved data set
+	ctx3, cancel3 := context.WithTimeout(context.Background(), time.Duration(r.GetCollectDuration())*time.Millisecond)
+	defer cancel3()
+	r.Commit(ctx3, n)
 }
 
-func (r *PulseConsensus) GetExchangeDuration() int {
-	return r.ExchangeDuration
+// Receive decodes collect, vector, reveal set and confirm messages
+func (r *PulseConsensus) Receive(t MsgType, payload json.RawMessage) error {
+	switch t {
+	case Collect:
+		var pulsePayload = PulseMessagePayload{}
+		if err := json.Unmarshal(payload, &pulsePayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), pulsePayload.String())
+		r.PulsesChan <- pulsePayload
+	case Vector:
+		var vectorPayload = PulseVectorPayload{}
+		if err := json.Unmarshal(payload, &vectorPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), vectorPayload.String())
+		r.VectorChan <- vectorPayload
+	case Confirm:
+		var confirmPayload = PulseConfirmPayload{}
+		if err := json.Unmarshal(payload, &confirmPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), confirmPayload.String())
+		r.ConfirmChan <- confirmPayload
+	case RevealSet:
+		var revealSetPayload = RevealSetPayload{}
+		if err := json.Unmarshal(payload, &revealSetPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), revealSetPayload.String())
+		r.ConfirmChan <- revealSetPayload
+	default:
+		return ErrUnexpectedMsgType(t, r.Name())
+	}
+	return nil
 }
 
 func (r *PulseConsensus) SendPulses(ctx context.Context, n Noder) {
 	r.log.Infof("collect round started")
-	s := n.Sign(DummyHashData)
-	pm := NewPulseMessage(n.GetAddr(), s, n.GetPulseNumber(), r.GetRoundStartTime())
+	r.Alpha = r.VrfInput(n)
+	beta, proof := n.ProveVrf(r.Alpha)
+	r.SelfReveal = VrfEntropy(beta)
+	r.SelfProof = proof
+	// only commitment is sent, proof reveals VRF output, so it's sent in vector after collect phase
+	pm := NewPulseMessage(n.GetID(), n.GetPulseNumber(), r.GetRoundStartTime(), Commitment(n.GetID(), r.SelfReveal))
+	pm.Payload.Signature = n.Sign(pm.Payload.SigningData())
+	pm.Payload.PulseProposal.Signature = pm.Payload.Signature
 	// add self entropy too
 	selfProposal := pm.Payload.PulseProposal
 	r.PulseProposals = append(r.PulseProposals, selfProposal)
@@ -139,6 +181,28 @@ func (r *PulseConsensus) FlushData() {
 	r.PulseProposals = make([]*PulseProposal, 0)
 	r.log.Debugf("flushing vector data")
 	r.PulseVectors = make([]*PulseVector, 0)
+	r.log.Debugf("flushing confirmations")
+	r.Confirmations = make([]*Confirmation, 0)
+	r.Guard.Flush()
+	r.DirectProposals = make(map[string]PulseMessagePayload)
+	r.SelfReveal = ""
+	r.SelfProof = nil
+	r.Reveals = make(map[string]string)
+	r.Alpha = nil
+	r.Proofs = make(map[string]*VrfProof)
+	r.PendingConfirms = make([]Messager, 0)
+}
+
+// VrfInput VRF input of the pulse, built on entropy of the latest pulse in ledger
+func (r *PulseConsensus) VrfInput(n Noder) []byte {
+	var prev []byte
+	b, err := n.GetLatestPulse()
+	if err != nil {
+		r.log.Error(ErrStorageConnection(err))
+	} else if b != nil {
+		prev = b.WinnerEntropy
+	}
+	return VrfInput(prev, n.GetPulseNumber()+1)
 }
 
 func (r *PulseConsensus) ReceivePulses(ctx context.Context, n Noder) {
@@ -149,28 +213,26 @@ func (r *PulseConsensus) ReceivePulses(ctx context.Context, n Noder) {
 			r.log.Debugf("proposals for round #%d: %s", n.GetPulseNumber(), r.PulseProposals)
 			return
 		case msg := <-r.PulsesChan:
-			signature := msg.GetSignature()
-			if n.VerifyMessageTrusted(signature) {
-				r.log.Debugf("message verified: %s", msg)
-				if msg.(PulseMessagePayload).Rst != r.GetRoundStartTime() {
-					r.log.Infof("skipping message from another round: %s", msg)
-				}
-				r.PulseProposals = append(r.PulseProposals, msg.GetPayload().(*PulseProposal))
+			if !r.accept(msg, n) {
 				continue
 			}
-			r.log.Error("message verification failed, signature is not from known public keys")
+			r.DirectProposals[msg.GetFrom()] = msg.(PulseMessagePayload)
+			r.PulseProposals = append(r.PulseProposals, msg.GetPayload().(*PulseProposal))
 		}
 	}
 }
 
 func (r *PulseConsensus) SendVectors(ctx context.Context, n Noder) {
 	r.log.Infof("exchange round started")
-	s := n.Sign(DummyHashData)
 	// add self vectors too
-	pv := &PulseVector{n.GetAddr(), r.PulseProposals}
-	r.PulseVectors = append(r.PulseVectors, &PulseVector{n.GetAddr(), r.PulseProposals})
+	pv := &PulseVector{n.GetID(), r.PulseProposals}
+	r.PulseVectors = append(r.PulseVectors, &PulseVector{n.GetID(), r.PulseProposals})
 	log.Debugf("pulse proposals: %s", r.PulseProposals)
-	if err := n.GetClient().Broadcast(ctx, NewPulseVectorMessage(n.GetAddr(), s, n.GetPulseNumber(), r.GetRoundStartTime(), pv)); err != nil {
+	r.Reveals[r.SelfProposal.Entropy] = r.SelfReveal
+	r.Proofs[r.SelfProposal.Entropy] = &VrfProof{n.GetID(), r.SelfProof}
+	vm := NewPulseVectorMessage(n.GetID(), n.GetPulseNumber(), r.GetRoundStartTime(), pv, r.SelfProof)
+	vm.Payload.Signature = n.Sign(vm.Payload.SigningData())
+	if err := n.GetClient().Broadcast(ctx, vm); err != nil {
 		r.log.Error(err)
 	}
 }
@@ -182,53 +244,295 @@ func (r *PulseConsensus) ReceiveVectors(ctx context.Context, n Noder) {
 		case <-ctx.Done():
 			r.log.Infof("exchange round #%d ended", n.GetPulseNumber())
 			r.log.Debugf("vectors for round #%d: %s", n.GetPulseNumber(), r.PulseVectors)
+			r.log.Debugf("rejected messages: %v", r.Guard.Rejected())
 			return
 		case msg := <-r.VectorChan:
-			signature := msg.GetSignature()
-			if n.VerifyMessageTrusted(signature) {
-				r.log.Debugf("message verified: %s", msg)
-				if msg.(PulseVectorPayload).Rst != r.GetRoundStartTime() {
-					r.log.Infof("skipping message from another round: %s", msg)
-				}
-				r.PulseVectors = append(r.PulseVectors, msg.GetPayload().(*PulseVector))
+			if !r.accept(msg, n) {
+				continue
+			}
+			if !r.AddReveal(msg.(PulseVectorPayload), n) {
+				r.log.Errorf("vrf proof of vector doesn't prove sender commitment: %s", msg)
+				r.reject(msg, RejectVrf)
 				continue
 			}
-			r.log.Error("message verification failed, signature is not from known public keys")
+			r.CheckRelayedProposals(msg.GetPayload().(*PulseVector), n)
+			r.PulseVectors = append(r.PulseVectors, msg.GetPayload().(*PulseVector))
+		}
+	}
+}
+
+// AddProof verifies that commitment is made to VRF output of proposer for the round,
+// remembers the proof and the output as revealed entropy of the commitment
+func (r *PulseConsensus) AddProof(commitment string, p *VrfProof, n Noder) bool {
+	beta, ok := n.VerifyVrf(p.From, r.Alpha, p.Proof)
+	if !ok {
+		return false
+	}
+	reveal := VrfEntropy(beta)
+	if Commitment(p.From, reveal) != commitment {
+		return false
+	}
+	r.Reveals[commitment] = reveal
+	r.Proofs[commitment] = p
+	return true
+}
+
+// Contributions agreed commitments with verified proof and reveal, their entropies are combined into the pulse
+func (r *PulseConsensus) Contributions() ([]string, []*VrfProof) {
+	commitments := make([]string, 0)
+	proofs := make([]*VrfProof, 0)
+	for _, c := range r.MajorityData {
+		proof, ok := r.Proofs[c]
+		if !ok {
+			continue
+		}
+		if _, ok := r.Reveals[c]; !ok {
+			continue
+		}
+		commitments = append(commitments, c)
+		proofs = append(proofs, proof)
+	}
+	return commitments, proofs
+}
+
+// AddReveal accepts entropy revealed by VRF proof of vector sender if sender commitment is made to it,
+// commitment received directly is preferred to the one sender relays in its own vector,
+// returns false if proof doesn't prove sender commitment
+func (r *PulseConsensus) AddReveal(v PulseVectorPayload, n Noder) bool {
+	commitment := ""
+	if direct, ok := r.DirectProposals[v.From]; ok {
+		commitment = direct.PulseProposal.Entropy
+	} else if v.EntropiesVector != nil {
+		for _, p := range v.EntropiesVector.Vector {
+			if p != nil && p.From == v.From {
+				commitment = p.Entropy
+				break
+			}
+		}
+	}
+	if commitment == "" {
+		r.log.Infof("no commitment of %s to check its reveal against", v.From)
+		return false
+	}
+	return r.AddProof(commitment, &VrfProof{v.From, v.Proof}, n)
+}
+
+// CheckRelayedProposals compares proposals relayed in a vector with ones received from proposers directly,
+// proposal signed by proposer with other entropy proves proposer sent different proposals to different peers
+func (r *PulseConsensus) CheckRelayedProposals(v *PulseVector, n Noder) {
+	for _, p := range v.Vector {
+		if p == nil {
+			continue
 		}
+		direct, ok := r.DirectProposals[p.From]
+		if !ok || direct.PulseProposal.Entropy == p.Entropy {
+			continue
+		}
+		relayed := PulseMessagePayload{
+			Signature:     p.Signature,
+			Rst:           direct.Rst,
+			Epoch:         direct.Epoch,
+			From:          p.From,
+			PulseProposal: p,
+		}
+		if !n.VerifyMessageTrusted(relayed) {
+			r.log.Infof("relayed proposal from %s is not signed by proposer, relayed by %s", p.From, v.From)
+			continue
+		}
+		n.ReportEquivocation(NewEvidence(NewSignedMessage(direct), relayed))
 	}
 }
 
+// Commit combines agreed reveal set into pulse entropy, confirms it with peers
+// and commits block to node ledger if quorum of confirmations is collected before commit round ends
 func (r *PulseConsensus) Commit(ctx context.Context, n Noder) {
 	r.log.Infof("committing consensus data round #%d", n.GetPulseNumber())
+	winner := r.DecideWinner()
+	r.log.Infof("winner: %s, me: %s", winner, r.SelfProposal.Entropy)
+	if winner == NoConsensusStatus {
+		return
+	}
+	entropy, proofs := r.AgreeReveals(ctx, n, winner)
+	if entropy == "" {
+		r.log.Infof("no reveal set is agreed")
+		return
+	}
+	r.SendConfirm(ctx, n, entropy)
+	if !r.ReceiveConfirms(ctx, n, entropy) {
+		r.log.Infof("no quorum of confirmations for entropy: %s", entropy)
+		return
+	}
+	r.log.Infof("committing agreed pulse")
+	proposers := make([]string, 0, len(proofs))
+	for _, p := range proofs {
+		proposers = append(proposers, p.From)
+	}
+	b, err := NewBlockData(n.GetPulseNumber()+1, r.GetRoundStartTime(), proposers, entropy, r.Confirmations)
+	if err != nil {
+		log.Errorf("failed to encode pulse proposals: %s", err)
+		return
+	}
+	b.Proofs = proofs
+	if err := SignBlock(n, &b); err != nil {
+		r.log.Errorf("failed to sign pulse: %s", err)
+		return
+	}
+	r.log.Debugf("committing pulse: %v", b)
+	if err := n.Commit(context.Background(), b); err != nil {
+		r.log.Error(ErrStorageConnection(err))
+	}
+}
+
+// AgreeReveals agrees on reveals combined into pulse entropy, proposer of the winning commitment broadcasts
+// reveal set of agreed commitments it has reveals and proofs of, other nodes wait for the set and verify it,
+// returns entropy combined of the set and proofs of its reveals, empty if no valid set is agreed
+func (r *PulseConsensus) AgreeReveals(ctx context.Context, n Noder, winner string) (string, []*VrfProof) {
+	proposer := r.proposerOf(winner)
+	if proposer == n.GetID() {
+		_, proofs := r.Contributions()
+		if len(proofs) == 0 {
+			return "", nil
+		}
+		rm := NewSignedRevealSetMessage(n, r.GetRoundStartTime(), proofs)
+		if err := n.GetClient().Broadcast(ctx, rm); err != nil {
+			r.log.Error(err)
+		}
+		return r.VerifyRevealSet(n, proofs), proofs
+	}
 	for {
 		select {
 		case <-ctx.Done():
-			r.log.Infof("commit round ended")
-			return
-		default:
-			winner := r.DecideWinner()
-			r.log.Infof("winner: %s, me: %s", winner, r.SelfProposal.Entropy)
-			if winner == r.SelfProposal.Entropy {
-				r.log.Infof("committing winner pulse")
-				var buf bytes.Buffer
-				enc := gob.NewEncoder(&buf)
-				if err := enc.Encode(winner); err != nil {
-					log.Errorf("failed to encode pulse proposals: %s", err)
-					continue
-				}
-				b := BlockData{
-					time.Now().Unix(),
-					buf.Bytes(),
-				}
-				r.log.Debugf("committing pulse: %v", b)
-				if err := n.Commit(context.Background(), b); err != nil {
-					r.log.Error(ErrStorageConnection(err))
-				}
-				return
+			r.log.Infof("no reveal set from proposer %s in round #%d", proposer, n.GetPulseNumber())
+			return "", nil
+		case msg := <-r.ConfirmChan:
+			if msg.GetType() != RevealSet {
+				r.PendingConfirms = append(r.PendingConfirms, msg)
+				continue
 			}
-			return
+			if msg.GetFrom() != proposer {
+				r.log.Infof("skipping reveal set not from proposer %s: %s", proposer, msg)
+				continue
+			}
+			if !r.accept(msg, n) {
+				continue
+			}
+			proofs := msg.(RevealSetPayload).Proofs
+			if entropy := r.VerifyRevealSet(n, proofs); entropy != "" {
+				return entropy, proofs
+			}
+			r.log.Errorf("invalid reveal set: %s", msg)
+		}
+	}
+}
+
+// VerifyRevealSet checks that every reveal of the set is proven for a distinct agreed commitment and that the set
+// has every commitment this node would combine, verified reveals are accepted,
+// returns entropy combined of the set, empty if the set is invalid
+func (r *PulseConsensus) VerifyRevealSet(n Noder, proofs []*VrfProof) string {
+	agreed := make(map[string]bool)
+	for _, c := range r.MajorityData {
+		agreed[c] = true
+	}
+	reveals := make(map[string]string)
+	verified := make(map[string]*VrfProof)
+	for _, p := range proofs {
+		beta, ok := n.VerifyVrf(p.From, r.Alpha, p.Proof)
+		if !ok {
+			return ""
 		}
+		reveal := VrfEntropy(beta)
+		c := Commitment(p.From, reveal)
+		if _, ok := reveals[c]; ok || !agreed[c] {
+			return ""
+		}
+		reveals[c] = reveal
+		verified[c] = p
 	}
+	commitments, _ := r.Contributions()
+	for _, c := range commitments {
+		if _, ok := reveals[c]; !ok {
+			r.log.Infof("reveal set misses revealed commitment: %s", c)
+			return ""
+		}
+	}
+	combined := make([]string, 0, len(reveals))
+	for c, reveal := range reveals {
+		r.Reveals[c] = reveal
+		r.Proofs[c] = verified[c]
+		combined = append(combined, c)
+	}
+	return CombineReveals(combined, reveals)
+}
+
+// proposerOf gets node which proposed commitment, empty if commitment wasn't seen
+func (r *PulseConsensus) proposerOf(commitment string) string {
+	if p, ok := r.Proofs[commitment]; ok {
+		return p.From
+	}
+	for _, v := range r.PulseVectors {
+		for _, p := range v.Vector {
+			if p != nil && p.Entropy == commitment {
+				return p.From
+			}
+		}
+	}
+	return ""
+}
+
+// SendConfirm broadcasts signed confirmation of pulse entropy
+func (r *PulseConsensus) SendConfirm(ctx context.Context, n Noder, entropy string) {
+	cm := NewSignedConfirmMessage(n, r.GetRoundStartTime(), entropy)
+	r.Confirmations = append(r.Confirmations, cm.Payload.GetPayload().(*Confirmation))
+	if err := n.GetClient().Broadcast(ctx, cm); err != nil {
+		r.log.Error(err)
+	}
+}
+
+// ReceiveConfirms collects confirmations of pulse entropy until quorum is reached or round ends,
+// confirmations received while reveal set was agreed are counted first
+func (r *PulseConsensus) ReceiveConfirms(ctx context.Context, n Noder, entropy string) bool {
+	quorum := r.ConfirmQuorum()
+	pending := r.PendingConfirms
+	r.PendingConfirms = make([]Messager, 0)
+	for {
+		if len(r.Confirmations) >= quorum {
+			r.log.Infof("confirmations quorum reached: %d/%d", len(r.Confirmations), quorum)
+			return true
+		}
+		var msg Messager
+		if len(pending) > 0 {
+			msg, pending = pending[0], pending[1:]
+		} else {
+			select {
+			case <-ctx.Done():
+				r.log.Infof("commit round #%d ended, confirmations: %d/%d", n.GetPulseNumber(), len(r.Confirmations), quorum)
+				return false
+			case msg = <-r.ConfirmChan:
+			}
+		}
+		if msg.GetType() != Confirm {
+			continue
+		}
+		if !r.accept(msg, n) {
+			continue
+		}
+		if msg.(PulseConfirmPayload).Entropy != entropy {
+			r.log.Infof("confirmation of another entropy: %s", msg)
+			continue
+		}
+		r.Confirmations = append(r.Confirmations, msg.GetPayload().(*Confirmation))
+	}
+}
+
+// ConfirmQuorum 2f+1 confirmations needed to commit
+func (r *PulseConsensus) ConfirmQuorum() int {
+	return 2*r.Faulty + 1
+}
+
+// AgreeThreshold 2f votes from vectors of other nodes needed for entropy to be agreed,
+// with proposer itself that's 2f+1 nodes that have seen the entropy
+func (r *PulseConsensus) AgreeThreshold() int {
+	return 2 * r.Faulty
 }
 
 // DecideWinner counts BFT data versions and select random pulsar as a winner
@@ -236,11 +540,17 @@ func (r *PulseConsensus) Commit(ctx context.Context, n Noder) {
 func (r *PulseConsensus) DecideWinner() string {
 	versions := make(map[string]int)
 	for _, ver := range r.PulseVectors {
+		// every proposer is counted once per vector, so repeated proposals can't skew the votes
+		counted := make(map[string]bool)
 		for _, proposal := range ver.Vector {
 			// skip version of node we are counting gossips for
 			if strings.Contains(proposal.String(), ver.From) {
 				continue
 			}
+			if counted[proposal.From] {
+				continue
+			}
+			counted[proposal.From] = true
 			versions[proposal.Entropy] += 1
 		}
 	}
@@ -255,11 +565,12 @@ func (r *PulseConsensus) DecideWinner() string {
 	}
 }
 
-// AgreeSet forms set from pulses if 2/3 of nodes agree on data
+// AgreeSet forms set from pulses if 2f+1 nodes agree on data
 func (r *PulseConsensus) AgreeSet(versions map[string]int) []string {
 	majorityData := make([]string, 0)
+	threshold := r.AgreeThreshold()
 	for entropy, versionCount := range versions {
-		if versionCount >= (r.TotalNodes-1)*2/3 {
+		if versionCount >= threshold {
 			majorityData = append(majorityData, entropy)
 		}
 	}
@@ -267,6 +578,22 @@ func (r *PulseConsensus) AgreeSet(versions map[string]int) []string {
 	return majorityData
 }
 
+// FaultTolerance max number of byzantine nodes f cluster of n nodes tolerates, n >= 3f+1
+func FaultTolerance(totalNodes int) int {
+	if totalNodes < 1 {
+		return 0
+	}
+	return (totalNodes - 1) / 3
+}
+
+// CheckClusterSize checks that cluster tolerates at least one faulty node
+func CheckClusterSize(totalNodes int) error {
+	if FaultTolerance(totalNodes) < 1 {
+		return ErrClusterTooSmall(totalNodes)
+	}
+	return nil
+}
+
 // Winner select random winning entropy, random is the same across all nodes
 func (r *PulseConsensus) Winner(ents []string) string {
 	return ents[hashFnv64(ents)%uint64(len(ents))]
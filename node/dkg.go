@@ -0,0 +1,305 @@
+package node
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+)
+
+// Offline distributed key generation of threshold keys, Pedersen's joint Feldman scheme: every participant deals
+// shares of its own random polynomial to all participants, shares are encrypted to participant node keys and the deal
+// is signed by dealer, so deal files can be handed around openly. Participant adds its shares of all deals up into
+// its key share and dealt commitments into group polynomial, group secret is the sum of dealt secrets,
+// it's never computed, so nobody knows it unless threshold of participants collude
+
+const dkgDealPemType = "DKG DEAL"
+
+var errDkgDeal = errors.New("dkg: malformed deal")
+
+// DkgParticipant node taking part in key generation
+type DkgParticipant struct {
+	ID        string
+	PublicKey *ecdsa.PublicKey
+}
+
+// NewDkgParticipants orders participants by node id, key share of participant is indexed by its position
+func NewDkgParticipants(keys []*ecdsa.PublicKey) ([]DkgParticipant, error) {
+	participants := make([]DkgParticipant, 0, len(keys))
+	for _, k := range keys {
+		id, err := NodeID(k)
+		if err != nil {
+			return nil, err
+		}
+		participants = append(participants, DkgParticipant{id, k})
+	}
+	sort.Slice(participants, func(i, j int) bool {
+		return participants[i].ID < participants[j].ID
+	})
+	return participants, nil
+}
+
+// dkgIndex gets index of participant, -1 if node doesn't take part
+func dkgIndex(participants []DkgParticipant, id string) int {
+	for i, p := range participants {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// DkgDeal commitment of dealer polynomial and its shares encrypted to every participant, signed by dealer
+type DkgDeal struct {
+	Dealer string
+	// Commits commitments of polynomial coefficients, their number is threshold
+	Commits []kyber.Point
+	// Shares encrypted shares by participant index
+	Shares    [][]byte
+	Signature []byte
+}
+
+// NewDkgDeal deals shares of new random polynomial of degree t-1 to participants, dealer is the node of private key
+func NewDkgDeal(priv *ecdsa.PrivateKey, t int, participants []DkgParticipant) (*DkgDeal, error) {
+	dealer, err := NodeID(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if dkgIndex(participants, dealer) < 0 {
+		return nil, ErrDkgParticipant(dealer)
+	}
+	if t < 1 || t > len(participants) {
+		return nil, ErrDkgInvalidDeal(dealer, "threshold is out of participants range")
+	}
+	g := thresholdSuite.G2()
+	poly := share.NewPriPoly(g, t, g.Scalar().Pick(thresholdSuite.RandomStream()), thresholdSuite.RandomStream())
+	_, commits := poly.Commit(g.Point().Base()).Info()
+	d := &DkgDeal{Dealer: dealer, Commits: commits, Shares: make([][]byte, len(participants))}
+	for i, s := range poly.Shares(len(participants)) {
+		sealed, err := sealShare(participants[i].PublicKey, s.V, dkgShareData(dealer, i))
+		if err != nil {
+			return nil, err
+		}
+		d.Shares[i] = sealed
+	}
+	data, err := d.SigningData()
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	d.Signature, err = priv.Sign(rand.Reader, digest[:], nil)
+	return d, err
+}
+
+// SigningData encodes dealer, commitments and encrypted shares
+func (d *DkgDeal) SigningData() ([]byte, error) {
+	e := newCanonicalEncoder(dkgDealDomain)
+	e.String(d.Dealer)
+	e.Uint64(uint64(len(d.Commits)))
+	for _, c := range d.Commits {
+		b, err := c.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		e.Blob(b)
+	}
+	e.Uint64(uint64(len(d.Shares)))
+	for _, s := range d.Shares {
+		e.Blob(s)
+	}
+	return e.Bytes(), nil
+}
+
+// Verify checks that deal is signed by participant and has a share for every participant
+func (d *DkgDeal) Verify(participants []DkgParticipant) error {
+	i := dkgIndex(participants, d.Dealer)
+	if i < 0 {
+		return ErrDkgParticipant(d.Dealer)
+	}
+	if len(d.Commits) == 0 || len(d.Commits) > len(participants) || len(d.Shares) != len(participants) {
+		return ErrDkgInvalidDeal(d.Dealer, "shares don't match participants")
+	}
+	data, err := d.SigningData()
+	if err != nil {
+		return err
+	}
+	if !VerifySignature(participants[i].PublicKey, data, d.Signature) {
+		return ErrDkgInvalidDeal(d.Dealer, "signature is not from dealer")
+	}
+	return nil
+}
+
+// CombineDkgDeals verifies deals of all participants, decrypts shares dealt to the node of private key
+// and checks them against dealt commitments, shares are added up into node key share and commitments into group polynomial,
+// every participant must deal with the same threshold, key generation is run again if any deal is missing or invalid
+func CombineDkgDeals(priv *ecdsa.PrivateKey, participants []DkgParticipant, deals []*DkgDeal) (*Threshold, error) {
+	id, err := NodeID(&priv.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	index := dkgIndex(participants, id)
+	if index < 0 {
+		return nil, ErrDkgParticipant(id)
+	}
+	g := thresholdSuite.G2()
+	secret := g.Scalar().Zero()
+	var group *share.PubPoly
+	dealt := make(map[string]bool)
+	for _, d := range deals {
+		if dealt[d.Dealer] {
+			return nil, ErrDkgInvalidDeal(d.Dealer, "dealer dealt twice")
+		}
+		if err := d.Verify(participants); err != nil {
+			return nil, err
+		}
+		if group != nil && len(d.Commits) != group.Threshold() {
+			return nil, ErrDkgInvalidDeal(d.Dealer, "threshold differs from other deals")
+		}
+		v, err := openShare(priv, d.Shares[index], dkgShareData(d.Dealer, index))
+		if err != nil {
+			return nil, ErrDkgInvalidDeal(d.Dealer, err.Error())
+		}
+		poly := share.NewPubPoly(g, g.Point().Base(), d.Commits)
+		if !poly.Check(&share.PriShare{I: index, V: v}) {
+			return nil, ErrDkgInvalidDeal(d.Dealer, "share doesn't match commitments")
+		}
+		dealt[d.Dealer] = true
+		secret = secret.Add(secret, v)
+		if group == nil {
+			group = poly
+		} else if group, err = group.Add(poly); err != nil {
+			return nil, err
+		}
+	}
+	if len(dealt) != len(participants) {
+		return nil, ErrDkgMissingDeals(len(dealt), len(participants))
+	}
+	return &Threshold{&share.PriShare{I: index, V: secret}, group, len(participants)}, nil
+}
+
+// dkgShareData authenticated with encrypted share, so share can't be moved to another dealer or index
+func dkgShareData(dealer string, index int) []byte {
+	e := newCanonicalEncoder(dkgShareDomain)
+	e.String(dealer)
+	e.Uint64(uint64(index))
+	return e.Bytes()
+}
+
+// sealShare encrypts share to public key with ephemeral ECDH key, ciphertext is prefixed with ephemeral public key
+func sealShare(pub *ecdsa.PublicKey, v kyber.Scalar, ad []byte) ([]byte, error) {
+	ephemeral, err := ecdsa.GenerateKey(pub.Curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	x, _ := pub.Curve.ScalarMult(pub.X, pub.Y, ephemeral.D.Bytes())
+	aead, err := shareCipher(x)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := v.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	// key is derived from ephemeral key used once, so zero nonce isn't reused
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(elliptic.Marshal(pub.Curve, ephemeral.X, ephemeral.Y), nonce, plain, ad), nil
+}
+
+// openShare decrypts share sealed to public key of private key
+func openShare(priv *ecdsa.PrivateKey, sealed []byte, ad []byte) (kyber.Scalar, error) {
+	pointLen := 1 + 2*((priv.Curve.Params().BitSize+7)/8)
+	if len(sealed) < pointLen {
+		return nil, errDkgDeal
+	}
+	ex, ey := elliptic.Unmarshal(priv.Curve, sealed[:pointLen])
+	if ex == nil {
+		return nil, errDkgDeal
+	}
+	x, _ := priv.Curve.ScalarMult(ex, ey, priv.D.Bytes())
+	aead, err := shareCipher(x)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, make([]byte, aead.NonceSize()), sealed[pointLen:], ad)
+	if err != nil {
+		return nil, err
+	}
+	v := thresholdSuite.G2().Scalar()
+	if err := v.UnmarshalBinary(plain); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// shareCipher AES-GCM keyed by hash of ECDH shared secret
+func shareCipher(x *big.Int) (cipher.AEAD, error) {
+	e := newCanonicalEncoder(dkgShareDomain)
+	e.Blob(x.Bytes())
+	key := sha256.Sum256(e.Bytes())
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WriteDkgDeal writes deal to file handed to every participant
+func WriteDkgDeal(file string, d *DkgDeal) error {
+	data, err := d.SigningData()
+	if err != nil {
+		return err
+	}
+	e := &canonicalEncoder{}
+	e.Blob(data)
+	e.Blob(d.Signature)
+	return ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: dkgDealPemType, Bytes: e.Bytes()}), os.ModePerm)
+}
+
+// ReadDkgDeal reads deal written by WriteDkgDeal, deal must be verified before its shares are used
+func ReadDkgDeal(file string) (*DkgDeal, error) {
+	dealPem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	b, _ := pem.Decode(dealPem)
+	if b == nil || b.Type != dkgDealPemType {
+		return nil, errDkgDeal
+	}
+	outer := &canonicalDecoder{data: b.Bytes}
+	data := outer.Blob()
+	signature := outer.Blob()
+	if outer.err != nil || len(outer.data) != 0 {
+		return nil, errDkgDeal
+	}
+	dec := &canonicalDecoder{data: data}
+	if MsgType(dec.Uint64()) != dkgDealDomain {
+		return nil, errDkgDeal
+	}
+	d := &DkgDeal{Dealer: dec.String(), Signature: signature}
+	commits := dec.Uint64()
+	for i := uint64(0); i < commits && dec.err == nil; i++ {
+		p := thresholdSuite.G2().Point()
+		if err := p.UnmarshalBinary(dec.Blob()); err != nil {
+			return nil, errDkgDeal
+		}
+		d.Commits = append(d.Commits, p)
+	}
+	shares := dec.Uint64()
+	for i := uint64(0); i < shares && dec.err == nil; i++ {
+		d.Shares = append(d.Shares, dec.Blob())
+	}
+	if dec.err != nil || len(dec.data) != 0 {
+		return nil, errDkgDeal
+	}
+	return d, nil
+}
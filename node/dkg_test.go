@@ -0,0 +1,141 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/share"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+// dkgNodes generates node keys of n participants, keys are in participants order
+func dkgNodes(t *testing.T, n int) ([]*ecdsa.PrivateKey, []DkgParticipant) {
+	privs := make(map[string]*ecdsa.PrivateKey)
+	pubs := make([]*ecdsa.PublicKey, 0, n)
+	for i := 0; i < n; i++ {
+		priv, pub := generateNewKeyPair()
+		id, err := NodeID(pub)
+		require.NoError(t, err)
+		privs[id] = priv
+		pubs = append(pubs, pub)
+	}
+	participants, err := NewDkgParticipants(pubs)
+	require.NoError(t, err)
+	ordered := make([]*ecdsa.PrivateKey, 0, n)
+	for _, p := range participants {
+		ordered = append(ordered, privs[p.ID])
+	}
+	return ordered, participants
+}
+
+// resign signs changed deal with dealer key
+func resign(t *testing.T, priv *ecdsa.PrivateKey, d *DkgDeal) {
+	data, err := d.SigningData()
+	require.NoError(t, err)
+	digest := sha256.Sum256(data)
+	d.Signature, err = priv.Sign(rand.Reader, digest[:], nil)
+	require.NoError(t, err)
+}
+
+func TestDkgKeysShareGroupSecret(t *testing.T) {
+	keys := thresholdKeys(t, 2, 4)
+	shares := make([]*share.PriShare, 0, len(keys))
+	for i, k := range keys {
+		require.Equal(t, i, k.Share.I)
+		require.Equal(t, 4, k.N)
+		require.Equal(t, keys[0].GroupKey(), k.GroupKey())
+		require.True(t, k.Group.Check(k.Share))
+		shares = append(shares, k.Share)
+	}
+
+	// secret of any threshold of shares is the one group key commits to, no single share recovers it
+	g := thresholdSuite.G2()
+	for _, pair := range [][]*share.PriShare{shares[:2], shares[2:], {shares[0], shares[3]}} {
+		secret, err := share.RecoverSecret(g, pair, 2, 4)
+		require.NoError(t, err)
+		require.True(t, g.Point().Mul(secret, nil).Equal(keys[0].Group.Commit()))
+	}
+	_, err := share.RecoverSecret(g, shares[:1], 2, 4)
+	require.Error(t, err)
+}
+
+func TestDkgRejectsInvalidDeals(t *testing.T) {
+	privs, participants := dkgNodes(t, 4)
+	deals := make([]*DkgDeal, 0, len(privs))
+	for _, priv := range privs {
+		d, err := NewDkgDeal(priv, 2, participants)
+		require.NoError(t, err)
+		require.NoError(t, d.Verify(participants))
+		deals = append(deals, d)
+	}
+	_, err := CombineDkgDeals(privs[0], participants, deals)
+	require.NoError(t, err)
+
+	// deal of every participant is required once
+	_, err = CombineDkgDeals(privs[0], participants, deals[:3])
+	require.Error(t, err)
+	_, err = CombineDkgDeals(privs[0], participants, append(deals[:3:3], deals[0]))
+	require.Error(t, err)
+	// node which isn't participant neither deals nor combines
+	outsider, _ := generateNewKeyPair()
+	_, err = NewDkgDeal(outsider, 2, participants)
+	require.Error(t, err)
+	_, err = CombineDkgDeals(outsider, participants, deals)
+	require.Error(t, err)
+
+	replace := func(i int, d *DkgDeal) []*DkgDeal {
+		changed := append([]*DkgDeal{}, deals...)
+		changed[i] = d
+		return changed
+	}
+	// deal changed by anyone but dealer
+	forged := *deals[1]
+	forged.Shares = append([][]byte{}, deals[1].Shares...)
+	forged.Shares[0], forged.Shares[2] = forged.Shares[2], forged.Shares[0]
+	_, err = CombineDkgDeals(privs[0], participants, replace(1, &forged))
+	require.Error(t, err)
+	// share of another participant
+	resign(t, privs[1], &forged)
+	require.NoError(t, forged.Verify(participants))
+	_, err = CombineDkgDeals(privs[0], participants, replace(1, &forged))
+	require.Error(t, err)
+	// share which doesn't match dealt commitments
+	wrong := *deals[1]
+	wrong.Shares = append([][]byte{}, deals[1].Shares...)
+	wrong.Shares[0], err = sealShare(participants[0].PublicKey, thresholdSuite.G2().Scalar().Pick(thresholdSuite.RandomStream()), dkgShareData(wrong.Dealer, 0))
+	require.NoError(t, err)
+	resign(t, privs[1], &wrong)
+	_, err = CombineDkgDeals(privs[0], participants, replace(1, &wrong))
+	require.Error(t, err)
+	// deal of another threshold
+	other, err := NewDkgDeal(privs[1], 3, participants)
+	require.NoError(t, err)
+	_, err = CombineDkgDeals(privs[0], participants, replace(1, other))
+	require.Error(t, err)
+}
+
+func TestDkgDealWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dkg")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	privs, participants := dkgNodes(t, 4)
+	d, err := NewDkgDeal(privs[2], 2, participants)
+	require.NoError(t, err)
+
+	file := path.Join(dir, "deal.pem")
+	require.NoError(t, WriteDkgDeal(file, d))
+	read, err := ReadDkgDeal(file)
+	require.NoError(t, err)
+	require.NoError(t, read.Verify(participants))
+	require.Equal(t, d.Dealer, read.Dealer)
+	require.Equal(t, d.Shares, read.Shares)
+	require.Len(t, read.Commits, 2)
+
+	require.NoError(t, ioutil.WriteFile(file, []byte("-----BEGIN DKG DEAL-----\nAAAA\n-----END DKG DEAL-----\n"), 0600))
+	_, err = ReadDkgDeal(file)
+	require.Error(t, err)
+}
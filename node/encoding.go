@@ -0,0 +1,154 @@
+package node
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// canonicalEncoder writes message fields in a fixed order with explicit lengths,
+// so the same payload always produces the same bytes to hash and sign
+type canonicalEncoder struct {
+	buf bytes.Buffer
+}
+
+// Domains of hashed and signed data which isn't a message, they are out of message types range
+const (
+	blockHashDomain MsgType = 1<<16 + iota
+	agreedHashDomain
+	blockSignatureDomain
+	dkgDealDomain
+	dkgShareDomain
+)
+
+// newCanonicalEncoder starts encoding with message type or domain as a domain separator,
+// signature or hash over one message type can't be reused for another
+func newCanonicalEncoder(t MsgType) *canonicalEncoder {
+	e := &canonicalEncoder{}
+	e.Uint64(uint64(t))
+	return e
+}
+
+// newMessageEncoder starts encoding of round message with header of fields message is identified by in a round:
+// type, rst, epoch, sender and view, so header of signed data is checked by decodeMsgKey
+func newMessageEncoder(msg Messager) *canonicalEncoder {
+	e := newCanonicalEncoder(msg.GetType())
+	e.Int64(msg.GetRst())
+	e.Uint64(msg.GetEpoch())
+	e.String(msg.GetFrom())
+	e.Uint64(msgView(msg))
+	return e
+}
+
+// errTruncated canonical data ends before field
+var errTruncated = errors.New("canonical data is truncated")
+
+// decodeMsgKey decodes header of round message signed data written by newMessageEncoder
+func decodeMsgKey(data []byte) (msgKey, error) {
+	d := &canonicalDecoder{data: data}
+	var k msgKey
+	k.Type = MsgType(d.Uint64())
+	k.Rst = int64(d.Uint64())
+	k.Epoch = d.Uint64()
+	k.From = d.String()
+	k.View = d.Uint64()
+	return k, d.err
+}
+
+// canonicalDecoder reads fields written by canonicalEncoder, the first error is kept and later reads are zero
+type canonicalDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *canonicalDecoder) Uint64() uint64 {
+	if d.err != nil || len(d.data) < 8 {
+		d.err = errTruncated
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.data[:8])
+	d.data = d.data[8:]
+	return v
+}
+
+func (d *canonicalDecoder) String() string {
+	n := d.Uint64()
+	if d.err != nil || uint64(len(d.data)) < n {
+		d.err = errTruncated
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *canonicalDecoder) Blob() []byte {
+	return []byte(d.String())
+}
+
+func (e *canonicalEncoder) Uint64(v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *canonicalEncoder) Int64(v int64) {
+	e.Uint64(uint64(v))
+}
+
+func (e *canonicalEncoder) String(s string) {
+	e.Uint64(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+// Blob writes length prefixed raw bytes
+func (e *canonicalEncoder) Blob(b []byte) {
+	e.Uint64(uint64(len(b)))
+	e.buf.Write(b)
+}
+
+func (e *canonicalEncoder) Proposal(p *PulseProposal) {
+	if p == nil {
+		e.String("")
+		e.String("")
+		return
+	}
+	e.String(p.From)
+	e.String(p.Entropy)
+}
+
+// QuorumCert writes certified proposal and votes, nil certificate is written as zero height without votes
+func (e *canonicalEncoder) QuorumCert(q *QuorumCert) {
+	if q == nil {
+		q = &QuorumCert{}
+	}
+	e.Uint64(q.Height)
+	e.Uint64(q.Epoch)
+	e.Int64(q.Rst)
+	e.String(q.Entropy)
+	e.Confirmations(q.Votes)
+	e.String(q.Proposer)
+}
+
+// Confirmations writes confirmations with signature shares
+func (e *canonicalEncoder) Confirmations(cs []*Confirmation) {
+	e.Uint64(uint64(len(cs)))
+	for _, c := range cs {
+		e.String(c.From)
+		e.Blob(c.Signature)
+		e.Blob(c.Share)
+		e.Uint64(c.View)
+	}
+}
+
+// Strings writes length prefixed list of strings
+func (e *canonicalEncoder) Strings(ss []string) {
+	e.Uint64(uint64(len(ss)))
+	for _, s := range ss {
+		e.String(s)
+	}
+}
+
+func (e *canonicalEncoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
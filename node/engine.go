@@ -0,0 +1,160 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"rounds/logger"
+	"sort"
+	"sync"
+)
+
+const (
+	PulseEngine = "pulse"
+)
+
+// EngineFactory creates consensus engine from node config
+type EngineFactory func(c *Config) Consensus
+
+var (
+	enginesMu sync.RWMutex
+	engines   = make(map[string]EngineFactory)
+)
+
+func init() {
+	RegisterEngine(PulseEngine, func(c *Config) Consensus {
+		return NewPulseConsensus(
+			c.ClusterSize(),
+			c.Node.Rounds.Collect.Duration,
+			c.Node.Rounds.Exchange.Duration,
+			c.Node.Rounds.Collect.MaxMessages,
+			c.Node.Rounds.Exchange.MaxMessages,
+			c.Node.Rounds.RstTolerance,
+		)
+	})
+}
+
+// RegisterEngine makes consensus engine selectable by name in node config
+func RegisterEngine(name string, f EngineFactory) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if _, ok := engines[name]; ok {
+		panic(fmt.Sprintf("consensus engine already registered: %s", name))
+	}
+	engines[name] = f
+}
+
+// Engines names of registered consensus engines
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewConsensus creates consensus engine selected in node config, pulse engine if not set
+func NewConsensus(c *Config) (Consensus, error) {
+	name := c.Node.Consensus
+	if name == "" {
+		name = PulseEngine
+	}
+	enginesMu.RLock()
+	f, ok := engines[name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownEngine(name, Engines())
+	}
+	return f(c), nil
+}
+
+// RoundBase round timings and peer messages filtering shared by consensus engines
+type RoundBase struct {
+	CollectDuration  int
+	ExchangeDuration int
+	RoundStartTime   int64
+	StartChan        chan int64
+	Guard            *ReplayGuard
+
+	log *logger.Logger
+}
+
+func NewRoundBase(collectDuration int, exchangeDuration int, rstTolerance int64) RoundBase {
+	return RoundBase{
+		collectDuration,
+		exchangeDuration,
+		0,
+		make(chan int64),
+		NewReplayGuard(rstTolerance),
+		logger.NewLogger(),
+	}
+}
+
+func (r *RoundBase) GetRoundStartTime() int64 {
+	return r.RoundStartTime
+}
+
+func (r *RoundBase) SetRoundStartTime(rst int64) {
+	r.RoundStartTime = rst
+}
+
+func (r *RoundBase) GetStartChan() chan int64 {
+	return r.StartChan
+}
+
+func (r *RoundBase) GetCollectDuration() int {
+	return r.CollectDuration
+}
+
+func (r *RoundBase) GetExchangeDuration() int {
+	return r.ExchangeDuration
+}
+
+// accept checks message round, signature and that it's the first message of a sender in this round
+func (r *RoundBase) accept(msg Messager, n Noder) bool {
+	if reason := r.Guard.CheckRound(msg, n.GetPulseNumber(), r.GetRoundStartTime()); reason != "" {
+		r.log.Infof("skipping message from another round (%s): %s", reason, msg)
+		r.reject(msg, reason)
+		return false
+	}
+	if n.IsExcluded(msg.GetFrom()) {
+		r.log.Debugf("skipping message from excluded peer: %s", msg.GetFrom())
+		r.reject(msg, RejectExcluded)
+		return false
+	}
+	if !n.VerifyMessageTrusted(msg) {
+		r.log.Errorf("message verification failed, signature is not from %s", msg.GetFrom())
+		r.reject(msg, RejectSignature)
+		return false
+	}
+	reason, evidence := r.Guard.Mark(msg, n.GetPulseNumber(), r.GetRoundStartTime())
+	if evidence != nil {
+		n.ReportEquivocation(evidence)
+	}
+	if reason != "" {
+		r.log.Infof("skipping repeated message (%s): %s", reason, msg)
+		r.reject(msg, reason)
+		return false
+	}
+	r.log.Debugf("message verified: %s", msg)
+	return true
+}
+
+// reject counts rejected message
+func (r *RoundBase) reject(msg Messager, reason string) {
+	r.Guard.Reject(reason)
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(KeyReason, reason),
+		tag.Insert(KeyMsgType, msg.GetType().String()),
+	)
+	if err != nil {
+		r.log.Error(err)
+		return
+	}
+	stats.Record(ctx, RejectedMessages.M(1))
+}
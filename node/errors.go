@@ -1,7 +1,105 @@
 package node
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+// ErrConflict block with another entropy is already committed at the epoch, commit errors wrap it
+var ErrConflict = errors.New("block with another entropy is already committed")
 
 func ErrStorageConnection(e error) error {
 	return errors.Wrap(e, "ledger connection failed")
 }
+
+func ErrPeerIDMismatch(configured string, derived string) error {
+	return errors.Errorf("peer id %s doesn't match public key fingerprint %s", configured, derived)
+}
+
+func ErrClusterTooSmall(totalNodes int) error {
+	return errors.Errorf("cluster of %d nodes can't tolerate a faulty node, at least 4 nodes required", totalNodes)
+}
+
+func ErrUnknownEngine(name string, registered []string) error {
+	return errors.Errorf("unknown consensus engine %s, registered engines: %v", name, registered)
+}
+
+func ErrUnknownStore(name string, registered []string) error {
+	return errors.Errorf("unknown store type %s, registered types: %v", name, registered)
+}
+
+func ErrStoreHostRequired(name string) error {
+	return errors.Errorf("store host is required for store type %q", name)
+}
+
+func ErrUnknownPeer(id string) error {
+	return errors.Errorf("unknown peer: %s", id)
+}
+
+func ErrInvalidVrfProof(from string, e error) error {
+	return errors.Wrapf(e, "invalid vrf proof of %s", from)
+}
+
+func ErrUnexpectedMsgType(t MsgType, engine string) error {
+	return errors.Errorf("message type %s is not handled by %s engine", t, engine)
+}
+
+func ErrInvalidConfirmation(from string) error {
+	return errors.Errorf("invalid confirmation signature of %s", from)
+}
+
+func ErrEpochConflict(epoch uint64) error {
+	return fmt.Errorf("%w at epoch %d", ErrConflict, epoch)
+}
+
+func ErrEpochAhead(epoch uint64, latest uint64) error {
+	return errors.Errorf("epoch %d is ahead of the latest epoch %d", epoch, latest)
+}
+
+func ErrEpochMissing(epoch uint64) error {
+	return errors.Errorf("epoch %d is missing in the chain", epoch)
+}
+
+func ErrNotEnoughShares(valid int, threshold int) error {
+	return errors.Errorf("not enough valid signature shares: %d/%d", valid, threshold)
+}
+
+func ErrPeerNotConnected(addr string) error {
+	return errors.Errorf("peer is not connected: %s", addr)
+}
+
+func ErrNoSyncResponse(epoch uint64) error {
+	return errors.Errorf("no peer responded to sync request from epoch %d", epoch)
+}
+
+func ErrSyncMismatch(epoch uint64) error {
+	return errors.Errorf("synced block of epoch %d does not match local block", epoch)
+}
+
+func ErrBrokenChain(epoch uint64) error {
+	return errors.Errorf("synced block of epoch %d is not linked to the previous one", epoch)
+}
+
+func ErrNotEnoughConfirmations(epoch uint64, valid int, quorum int) error {
+	return errors.Errorf("block of epoch %d has not enough confirmations: %d/%d", epoch, valid, quorum)
+}
+
+func ErrDuplicateConfirmation(from string) error {
+	return errors.Errorf("duplicate confirmation of %s", from)
+}
+
+func ErrInvalidSignature(from string) error {
+	return errors.Errorf("invalid message signature of %s", from)
+}
+
+func ErrDkgParticipant(id string) error {
+	return errors.Errorf("dkg: %s is not a participant of key generation", id)
+}
+
+func ErrDkgInvalidDeal(dealer string, reason string) error {
+	return errors.Errorf("dkg: invalid deal of %s: %s", dealer, reason)
+}
+
+func ErrDkgMissingDeals(dealt int, participants int) error {
+	return errors.Errorf("dkg: deal of every participant is required: %d/%d", dealt, participants)
+}
@@ -0,0 +1,119 @@
+package node
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	pb "rounds/ledger/pb"
+)
+
+// SignedMessage canonical message data with signature of the sender
+type SignedMessage struct {
+	Data      []byte
+	Signature []byte
+}
+
+func NewSignedMessage(msg Messager) SignedMessage {
+	return SignedMessage{
+		msg.SigningData(),
+		msg.GetSignature(),
+	}
+}
+
+// Evidence two conflicting messages signed by the same sender for the same round,
+// anyone with sender public key can check it
+type Evidence struct {
+	Offender string
+	Type     MsgType
+	Epoch    uint64
+	Rst      int64
+	First    SignedMessage
+	Second   SignedMessage
+}
+
+func NewEvidence(first SignedMessage, second Messager) *Evidence {
+	return &Evidence{
+		Offender: second.GetFrom(),
+		Type:     second.GetType(),
+		Epoch:    second.GetEpoch(),
+		Rst:      second.GetRst(),
+		First:    first,
+		Second:   NewSignedMessage(second),
+	}
+}
+
+// Verify checks that messages are different, both are sent by offender as the same message of the round of evidence,
+// so they have the same type, epoch, rst and view, and both are signed with offender key
+func (e *Evidence) Verify(pub *ecdsa.PublicKey) bool {
+	if bytes.Equal(e.First.Data, e.Second.Data) {
+		return false
+	}
+	first, err := decodeMsgKey(e.First.Data)
+	if err != nil {
+		return false
+	}
+	second, err := decodeMsgKey(e.Second.Data)
+	if err != nil || first != second {
+		return false
+	}
+	if first.From != e.Offender || first.Type != e.Type || first.Epoch != e.Epoch || first.Rst != e.Rst {
+		return false
+	}
+	return VerifySignature(pub, e.First.Data, e.First.Signature) &&
+		VerifySignature(pub, e.Second.Data, e.Second.Signature)
+}
+
+// Key storage key of evidence, unique per offender, round, message type and conflicting messages,
+// keys of offender start with offender id and '/'
+func (e *Evidence) Key() []byte {
+	k := append([]byte(e.Offender), '/')
+	var round [20]byte
+	binary.BigEndian.PutUint64(round[:8], e.Epoch)
+	binary.BigEndian.PutUint64(round[8:16], uint64(e.Rst))
+	binary.BigEndian.PutUint32(round[16:], uint32(e.Type))
+	k = append(k, round[:]...)
+	// the same pair of messages is stored once whichever of them was received first
+	first, second := e.First, e.Second
+	if bytes.Compare(first.Data, second.Data) > 0 {
+		first, second = second, first
+	}
+	enc := newCanonicalEncoder(e.Type)
+	enc.Blob(first.Data)
+	enc.Blob(second.Data)
+	h := sha256.Sum256(enc.Bytes())
+	return append(k, h[:]...)
+}
+
+func (e *Evidence) String() string {
+	return fmt.Sprintf(
+		"[offender: %s, type: %s, epoch: %d, rst: %d]",
+		e.Offender,
+		e.Type,
+		e.Epoch,
+		e.Rst,
+	)
+}
+
+func (e *Evidence) ToPb() *pb.Evidence {
+	return &pb.Evidence{
+		Offender: e.Offender,
+		Type:     int32(e.Type),
+		Epoch:    e.Epoch,
+		Rst:      e.Rst,
+		First:    &pb.SignedMessage{Data: e.First.Data, Signature: e.First.Signature},
+		Second:   &pb.SignedMessage{Data: e.Second.Data, Signature: e.Second.Signature},
+	}
+}
+
+func EvidenceFromPb(e *pb.Evidence) *Evidence {
+	return &Evidence{
+		Offender: e.GetOffender(),
+		Type:     MsgType(e.GetType()),
+		Epoch:    e.GetEpoch(),
+		Rst:      e.GetRst(),
+		First:    SignedMessage{e.GetFirst().GetData(), e.GetFirst().GetSignature()},
+		Second:   SignedMessage{e.GetSecond().GetData(), e.GetSecond().GetSignature()},
+	}
+}
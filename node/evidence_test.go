@@ -0,0 +1,178 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type stubStorage struct {
+	evidence chan *Evidence
+	commits  chan BlockData
+}
+
+func newStubStorage() *stubStorage {
+	return &stubStorage{make(chan *Evidence, 10), make(chan BlockData, 10)}
+}
+
+func (m *stubStorage) Commit(ctx context.Context, b BlockData) error {
+	m.commits <- b
+	return nil
+}
+
+func (m *stubStorage) GetLatestBlock() (*Block, error) {
+	return nil, nil
+}
+
+func (m *stubStorage) GetBlock(epoch uint64) (*Block, error) {
+	return nil, nil
+}
+
+func (m *stubStorage) GetBlockRange(from uint64, limit int) ([]*Block, uint64, error) {
+	return nil, 0, nil
+}
+
+func (m *stubStorage) GetLatestBlockEpoch() uint64 {
+	return 0
+}
+
+func (m *stubStorage) CommitEvidence(ctx context.Context, e *Evidence) error {
+	m.evidence <- e
+	return nil
+}
+
+func TestEquivocationDirectMessagesStored(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	store := newStubStorage()
+	self.store = store
+	self.ExcludeEquivocators = true
+	a := signingNode(t)
+	trust(t, self, a)
+	cons.SetRoundStartTime(1000)
+	cons.Alpha = VrfInput(nil, 1)
+
+	first := signedPulse(a, 0, 1000)
+	second := signedPulse(a, 0, 1000)
+	cons.PulsesChan <- first
+	cons.PulsesChan <- second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cons.ReceivePulses(ctx, self)
+	require.Len(t, cons.PulseProposals, 1)
+
+	e := <-store.evidence
+	require.Equal(t, a.ID, e.Offender)
+	require.Equal(t, Collect, e.Type)
+	require.Equal(t, first.SigningData(), e.First.Data)
+	require.Equal(t, second.SigningData(), e.Second.Data)
+	require.True(t, e.Verify(a.publicKey))
+	require.True(t, self.IsExcluded(a.ID))
+
+	// offender is ignored in the next rounds
+	cons.FlushData()
+	cons.SetRoundStartTime(1002)
+	cons.Alpha = VrfInput(nil, 1)
+	cons.PulsesChan <- signedPulse(a, 0, 1002)
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel2()
+	cons.ReceivePulses(ctx2, self)
+	require.Len(t, cons.PulseProposals, 0)
+	require.Equal(t, 1, cons.Guard.Rejected()[RejectExcluded])
+}
+
+func TestEquivocationRelayedProposalStored(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	store := newStubStorage()
+	self.store = store
+	a := signingNode(t)
+	b := signingNode(t)
+	trust(t, self, a, b)
+	cons.SetRoundStartTime(1000)
+
+	// a sends one proposal to us and another one to b
+	toSelf := signedPulse(a, 0, 1000)
+	toSelf.PulseProposal.Signature = toSelf.Signature
+	toB := signedPulse(a, 0, 1000)
+	toB.PulseProposal.Signature = toB.Signature
+	cons.DirectProposals[a.ID] = toSelf
+
+	vm := NewPulseVectorMessage(b.ID, 0, 1000, &PulseVector{b.ID, []*PulseProposal{toB.PulseProposal}}, nil)
+	vm.Payload.Signature = b.Sign(vm.Payload.SigningData())
+	cons.CheckRelayedProposals(vm.Payload.EntropiesVector, self)
+
+	e := <-store.evidence
+	require.Equal(t, a.ID, e.Offender)
+	require.True(t, e.Verify(a.publicKey))
+	require.False(t, self.IsExcluded(a.ID))
+
+	// proposal changed by relayer is not evidence against proposer
+	forged := NewPulseProposal(a.ID, "forged")
+	forged.Signature = toB.Signature
+	cons.CheckRelayedProposals(&PulseVector{b.ID, []*PulseProposal{forged}}, self)
+	require.Len(t, store.evidence, 0)
+}
+
+func TestEvidencePbRoundTrip(t *testing.T) {
+	a := signingNode(t)
+	first := signedPulse(a, 3, 1000)
+	e := NewEvidence(NewSignedMessage(first), signedPulse(a, 3, 1000))
+	require.Equal(t, e, EvidenceFromPb(e.ToPb()))
+	require.True(t, EvidenceFromPb(e.ToPb()).Verify(a.publicKey))
+}
+
+func TestEvidenceKeyUniquePerMessages(t *testing.T) {
+	a := signingNode(t)
+	first := signedPulse(a, 0, 1000)
+	second := signedPulse(a, 0, 1000)
+	third := signedPulse(a, 0, 1000)
+	e := NewEvidence(NewSignedMessage(first), second)
+	swapped := NewEvidence(NewSignedMessage(second), first)
+	other := NewEvidence(NewSignedMessage(first), third)
+
+	require.Equal(t, e.Key(), swapped.Key())
+	require.NotEqual(t, e.Key(), other.Key())
+	require.True(t, bytes.HasPrefix(e.Key(), []byte(a.ID+"/")))
+}
+
+func TestEvidenceVerifyRequiresSameMessageOfRound(t *testing.T) {
+	a := signingNode(t)
+	b := signingNode(t)
+	first := signedPulse(a, 3, 1000)
+	require.True(t, NewEvidence(NewSignedMessage(first), signedPulse(a, 3, 1000)).Verify(a.publicKey))
+
+	// messages of another round
+	require.False(t, NewEvidence(NewSignedMessage(first), signedPulse(a, 3, 1001)).Verify(a.publicKey))
+	require.False(t, NewEvidence(NewSignedMessage(first), signedPulse(a, 4, 1000)).Verify(a.publicKey))
+	// messages of another type
+	vm := NewPulseVectorMessage(a.ID, 3, 1000, &PulseVector{a.ID, nil}, nil)
+	vm.Payload.Signature = a.Sign(vm.Payload.SigningData())
+	require.False(t, NewEvidence(NewSignedMessage(first), vm.Payload).Verify(a.publicKey))
+	// messages of another view
+	prepare := NewSignedPbftMessage(a, Prepare, 1000, 0, "first", nil).Payload
+	require.True(t, NewEvidence(NewSignedMessage(prepare), NewSignedPbftMessage(a, Prepare, 1000, 0, "second", nil).Payload).Verify(a.publicKey))
+	require.False(t, NewEvidence(NewSignedMessage(prepare), NewSignedPbftMessage(a, Prepare, 1000, 1, "second", nil).Payload).Verify(a.publicKey))
+
+	// header doesn't match messages
+	e := NewEvidence(NewSignedMessage(first), signedPulse(a, 3, 1000))
+	e.Rst = 1001
+	require.False(t, e.Verify(a.publicKey))
+	e = NewEvidence(NewSignedMessage(first), signedPulse(a, 3, 1000))
+	e.Type = Vector
+	require.False(t, e.Verify(a.publicKey))
+	// messages of another sender signed with offender key aren't evidence against offender
+	forged := signedPulse(b, 3, 1000)
+	forged.Signature = a.Sign(forged.SigningData())
+	e = NewEvidence(NewSignedMessage(first), forged)
+	e.Offender = a.ID
+	require.False(t, e.Verify(a.publicKey))
+	// malformed data
+	e = NewEvidence(NewSignedMessage(first), signedPulse(a, 3, 1000))
+	e.Second.Data = e.Second.Data[:20]
+	e.Second.Signature = a.Sign(e.Second.Data)
+	require.False(t, e.Verify(a.publicKey))
+}
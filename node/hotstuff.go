@@ -0,0 +1,454 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	HotStuffEngine = "hotstuff"
+	// hotStuffMissBits low bits of view which count leader misses at height
+	hotStuffMissBits = 32
+)
+
+func init() {
+	RegisterEngine(HotStuffEngine, func(c *Config) Consensus {
+		return NewHotStuffConsensus(
+			c.ClusterSize(),
+			c.Node.Rounds.Collect.Duration,
+			c.Node.Rounds.Exchange.Duration,
+			c.Node.Rounds.Collect.MaxMessages+c.Node.Rounds.Exchange.MaxMessages,
+			c.Node.Rounds.RstTolerance,
+		)
+	})
+}
+
+// hotStuffView view of proposal and votes at height after leader misses, leader of every miss proposes
+// new entropy at the same height, so proposals and votes of successive leaders are messages of different views
+func hotStuffView(height uint64, misses uint64) uint64 {
+	return height<<hotStuffMissBits | misses&(1<<hotStuffMissBits-1)
+}
+
+// hotStuffViewHeight gets height of view
+func hotStuffViewHeight(view uint64) uint64 {
+	return view >> hotStuffMissBits
+}
+
+// HotStuffProposalPayload entropy proposed by leader at height, justified by certificate of the previous height
+type HotStuffProposalPayload struct {
+	Signature []byte
+	Rst       int64  `json:"rst"`
+	Epoch     uint64 `json:"epoch"`
+	From      string `json:"from"`
+	Height    uint64 `json:"height"`
+	// Misses leader misses at height before the proposal, leader is selected by height and misses
+	Misses  uint64 `json:"misses"`
+	Entropy string `json:"entropy"`
+	// Justify certificate of the previous height if it's not committed yet
+	Justify *QuorumCert `json:"justify"`
+}
+
+type HotStuffProposalMessage struct {
+	Header
+	Payload HotStuffProposalPayload `json:"payload"`
+}
+
+// NewSignedHotStuffProposal creates proposal signed by node
+func NewSignedHotStuffProposal(n Noder, rst int64, height uint64, misses uint64, entropy string, justify *QuorumCert) *HotStuffProposalMessage {
+	m := &HotStuffProposalMessage{
+		Header: Header{
+			Type: Propose,
+		},
+		Payload: HotStuffProposalPayload{
+			Rst:     rst,
+			Epoch:   n.GetPulseNumber(),
+			From:    n.GetID(),
+			Height:  height,
+			Misses:  misses,
+			Entropy: entropy,
+			Justify: justify,
+		},
+	}
+	m.Payload.Signature = n.Sign(m.Payload.SigningData())
+	return m
+}
+
+func (m HotStuffProposalPayload) GetType() MsgType {
+	return Propose
+}
+
+func (m HotStuffProposalPayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m HotStuffProposalPayload) GetRst() int64 {
+	return m.Rst
+}
+
+func (m HotStuffProposalPayload) GetFrom() string {
+	return m.From
+}
+
+// GetView proposals of different heights and of successive leaders of one height are sent in one round
+func (m HotStuffProposalPayload) GetView() uint64 {
+	return hotStuffView(m.Height, m.Misses)
+}
+
+func (m HotStuffProposalPayload) GetPayload() interface{} {
+	return m.Entropy
+}
+
+func (m HotStuffProposalPayload) GetSignature() []byte {
+	return m.Signature
+}
+
+// SigningData encodes message header with height and misses as view, entropy and justifying certificate
+func (m HotStuffProposalPayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.String(m.Entropy)
+	e.QuorumCert(m.Justify)
+	return e.Bytes()
+}
+
+func (m HotStuffProposalPayload) String() string {
+	justify := "none"
+	if m.Justify != nil {
+		justify = m.Justify.String()
+	}
+	return fmt.Sprintf(
+		"[ rst: %d, from: %s, height: %d, misses: %d, entropy: %s, justify: %s ]",
+		m.Rst,
+		m.From,
+		m.Height,
+		m.Misses,
+		m.Entropy,
+		justify,
+	)
+}
+
+// HotStuffConsensus chained consensus in the style of hotstuff, leader of a height proposes entropy for it
+// and nodes vote for it with confirm messages, proposal carries certificate of the previous height,
+// once quorum certifies a proposal leader of the next height proposes right away, so heights are pipelined,
+// entropy is committed when it's the head of three consecutive certified heights
+type HotStuffConsensus struct {
+	RoundBase
+	TotalNodes int
+	// Faulty max number of byzantine nodes cluster tolerates
+	Faulty  int
+	MsgChan chan Messager
+	// HighQC highest certificate node knows, it's kept between rounds until committed
+	HighQC *QuorumCert
+	// LockedQC head of the highest two-chain, node votes only for proposals extending it or justified by a later certificate
+	LockedQC *QuorumCert
+	// Misses leader timeouts in a row since the last certificate, leader is rotated on every miss
+	Misses int
+	// Committed the latest height committed to ledger
+	Committed uint64
+	// Proposal accepted at the height voted now
+	Proposal *HotStuffProposalPayload
+	// Proposals voted proposals which are not committed yet, by height, they link certificates into chains
+	Proposals map[uint64]HotStuffProposalPayload
+	// Votes received in current round, self included
+	Votes []PulseConfirmPayload
+}
+
+func NewHotStuffConsensus(totalNodes int, collectDuration int, exchangeDuration int, maxMessages int, rstTolerance int64) *HotStuffConsensus {
+	return &HotStuffConsensus{
+		NewRoundBase(collectDuration, exchangeDuration, rstTolerance),
+		totalNodes,
+		FaultTolerance(totalNodes),
+		make(chan Messager, maxMessages),
+		nil,
+		nil,
+		0,
+		0,
+		nil,
+		make(map[uint64]HotStuffProposalPayload),
+		make([]PulseConfirmPayload, 0),
+	}
+}
+
+func (r *HotStuffConsensus) Name() string {
+	return HotStuffEngine
+}
+
+func (r *HotStuffConsensus) FlushData() {
+	r.Proposal = nil
+	r.Votes = make([]PulseConfirmPayload, 0)
+	r.Guard.Flush()
+}
+
+// Quorum votes needed for certificate
+func (r *HotStuffConsensus) Quorum() int {
+	return 2*r.Faulty + 1
+}
+
+// Leader gets leader of the height, nodes are ordered by id and leader rotates with height and misses
+func (r *HotStuffConsensus) Leader(n Noder, height uint64) string {
+	ids := []string{n.GetID()}
+	for _, p := range n.GetPeers().Peers() {
+		ids = append(ids, p.ID)
+	}
+	sort.Strings(ids)
+	return ids[(height+uint64(r.Misses))%uint64(len(ids))]
+}
+
+// NextHeight height proposed next, the one after the highest certificate if it isn't committed yet
+func (r *HotStuffConsensus) NextHeight() uint64 {
+	if r.HighQC != nil && r.HighQC.Height >= r.Committed {
+		return r.HighQC.Height + 1
+	}
+	return r.Committed + 1
+}
+
+// Round pipelines heights until collect and exchange durations are over, leader of the next height proposes it
+// as soon as the previous one is certified, leader which doesn't get its height certified within collect duration
+// is counted as miss and replaced
+func (r *HotStuffConsensus) Round(rst int64, n Noder) {
+	r.FlushData()
+	r.SetRoundStartTime(rst)
+	r.SetCommitted(n.GetPulseNumber())
+
+	roundDuration := time.Duration(r.GetCollectDuration()+r.GetExchangeDuration()) * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), roundDuration)
+	defer cancel()
+	viewTimeout := time.Duration(r.GetCollectDuration()) * time.Millisecond
+	timer := time.NewTimer(viewTimeout)
+	defer timer.Stop()
+
+	r.ProposeIfLeader(ctx, n)
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Infof("round %d is over, committed: %d, certified: %d", rst, r.Committed, r.NextHeight()-1)
+			return
+		case <-timer.C:
+			r.LeaderTimeout(ctx, n)
+			timer.Reset(viewTimeout)
+		case msg := <-r.MsgChan:
+			if !r.accept(msg, n) {
+				continue
+			}
+			if r.handle(ctx, msg, n) {
+				timer.Reset(viewTimeout)
+			}
+		}
+	}
+}
+
+// SetCommitted sets the latest committed height, certificates and proposals committed or synced since are forgotten
+func (r *HotStuffConsensus) SetCommitted(height uint64) {
+	r.Committed = height
+	if r.HighQC != nil && r.HighQC.Height < height {
+		r.HighQC = nil
+	}
+	if r.LockedQC != nil && r.LockedQC.Height <= height {
+		r.LockedQC = nil
+	}
+	for h := range r.Proposals {
+		if h <= height {
+			delete(r.Proposals, h)
+		}
+	}
+}
+
+// LeaderTimeout counts miss of leader which hasn't got its height certified, next leader proposes the height
+func (r *HotStuffConsensus) LeaderTimeout(ctx context.Context, n Noder) {
+	height := r.NextHeight()
+	r.log.Infof("leader %s timed out at height %d", r.Leader(n, height), height)
+	r.Misses++
+	r.Proposal = nil
+	r.ProposeIfLeader(ctx, n)
+}
+
+// Receive decodes proposal and vote messages
+func (r *HotStuffConsensus) Receive(t MsgType, payload json.RawMessage) error {
+	switch t {
+	case Propose:
+		var proposalPayload = HotStuffProposalPayload{}
+		if err := json.Unmarshal(payload, &proposalPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), proposalPayload.String())
+		r.MsgChan <- proposalPayload
+	case Confirm:
+		var confirmPayload = PulseConfirmPayload{}
+		if err := json.Unmarshal(payload, &confirmPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), confirmPayload.String())
+		r.MsgChan <- confirmPayload
+	default:
+		return ErrUnexpectedMsgType(t, r.Name())
+	}
+	return nil
+}
+
+// handle handles verified message, returns true when certificate for the proposal is collected
+// and the next height is proposed
+func (r *HotStuffConsensus) handle(ctx context.Context, msg Messager, n Noder) bool {
+	switch m := msg.(type) {
+	case HotStuffProposalPayload:
+		if !r.VerifyProposal(n, m) {
+			r.log.Errorf("invalid proposal: %s", m)
+			return false
+		}
+		if m.Justify != nil {
+			r.Update(n, m.Justify)
+		}
+		if r.Proposal != nil || m.Height != r.NextHeight() || m.Misses != uint64(r.Misses) || m.From != r.Leader(n, m.Height) {
+			r.log.Infof("skipping proposal not from leader %s of height %d: %s", r.Leader(n, r.NextHeight()), r.NextHeight(), m)
+			return false
+		}
+		r.Vote(ctx, n, m)
+	case PulseConfirmPayload:
+		r.Votes = append(r.Votes, m)
+	}
+	if !r.CheckCertified(n) {
+		return false
+	}
+	r.ProposeIfLeader(ctx, n)
+	return true
+}
+
+// ProposeIfLeader proposes the next height justified by the highest certificate if node is leader of the height
+func (r *HotStuffConsensus) ProposeIfLeader(ctx context.Context, n Noder) {
+	height := r.NextHeight()
+	if r.Proposal != nil || r.Leader(n, height) != n.GetID() {
+		return
+	}
+	var justify *QuorumCert
+	if height > r.Committed+1 || (r.HighQC != nil && r.HighQC.Height == r.Committed) {
+		justify = r.HighQC
+	}
+	pm := NewSignedHotStuffProposal(n, r.GetRoundStartTime(), height, uint64(r.Misses), newEntropy(), justify)
+	if err := n.GetClient().Broadcast(ctx, pm); err != nil {
+		r.log.Error(err)
+	}
+	r.Vote(ctx, n, pm.Payload)
+}
+
+// Update makes certificate the highest one if it is, locks on its parent and commits its grandparent,
+// heights of certified proposals are consecutive, so parent and grandparent make three-chain with it,
+// new highest certificate ends leader misses and voting at its height
+func (r *HotStuffConsensus) Update(n Noder, qc *QuorumCert) {
+	if r.HighQC == nil || qc.Height > r.HighQC.Height || (qc.Height == r.HighQC.Height && qc.Rst > r.HighQC.Rst) {
+		r.HighQC = qc
+		r.Misses = 0
+		if r.Proposal != nil && r.Proposal.Height <= qc.Height {
+			r.Proposal = nil
+		}
+	}
+	p, ok := r.certified(qc)
+	if !ok || p.Justify == nil {
+		return
+	}
+	if r.LockedQC == nil || p.Justify.Height > r.LockedQC.Height {
+		r.LockedQC = p.Justify
+	}
+	parent, ok := r.certified(p.Justify)
+	if !ok || parent.Justify == nil || parent.Justify.Height != r.Committed+1 {
+		return
+	}
+	r.CommitCertified(n, parent.Justify)
+}
+
+// certified gets voted proposal certified by certificate
+func (r *HotStuffConsensus) certified(qc *QuorumCert) (HotStuffProposalPayload, bool) {
+	p, ok := r.Proposals[qc.Height]
+	if !ok || p.Entropy != qc.Entropy || p.Rst != qc.Rst || p.From != qc.Proposer {
+		return HotStuffProposalPayload{}, false
+	}
+	return p, true
+}
+
+// CommitCertified commits entropy certified by quorum certificate at certified height
+func (r *HotStuffConsensus) CommitCertified(n Noder, qc *QuorumCert) bool {
+	b, err := NewBlockData(qc.Height, qc.Rst, []string{qc.Proposer}, qc.Entropy, qc.Votes)
+	if err != nil {
+		r.log.Error(err)
+		return false
+	}
+	b.QC = qc
+	if err := SignBlock(n, &b); err != nil {
+		r.log.Errorf("failed to sign pulse: %s", err)
+		return false
+	}
+	r.log.Infof("committing certified pulse: %s", qc)
+	if err := n.Commit(context.Background(), b); err != nil {
+		r.log.Error(ErrStorageConnection(err))
+		return false
+	}
+	r.SetCommitted(qc.Height)
+	return true
+}
+
+// VerifyProposal checks proposal extends committed height, directly or by certified height which is not committed yet,
+// and that it's safe to vote for: it extends locked certificate or is justified by a later one
+func (r *HotStuffConsensus) VerifyProposal(n Noder, p HotStuffProposalPayload) bool {
+	if p.Justify == nil {
+		return p.Height == r.Committed+1 && r.LockedQC == nil
+	}
+	if p.Justify.Height < r.Committed || p.Height != p.Justify.Height+1 || !p.Justify.Verify(n, r.Quorum()) {
+		return false
+	}
+	return r.LockedQC == nil || p.Justify.Rst > r.LockedQC.Rst || r.extends(p.Justify, r.LockedQC)
+}
+
+// extends checks that certified proposals chain from certificate back to locked one
+func (r *HotStuffConsensus) extends(qc *QuorumCert, locked *QuorumCert) bool {
+	for qc != nil && qc.Height > locked.Height {
+		p, ok := r.certified(qc)
+		if !ok {
+			return false
+		}
+		qc = p.Justify
+	}
+	return qc != nil && qc.Height == locked.Height && qc.Entropy == locked.Entropy && qc.Rst == locked.Rst
+}
+
+// Vote accepts proposal and broadcasts vote for its entropy, vote is sent with proposal rst and view,
+// so all votes fit one certificate and vote for proposal of the next leader of the height isn't taken for equivocation,
+// signature share of the vote is for the block of proposal height
+func (r *HotStuffConsensus) Vote(ctx context.Context, n Noder, p HotStuffProposalPayload) {
+	r.Proposal = &p
+	r.Proposals[p.Height] = p
+	cm := NewPulseConfirmMessage(n.GetID(), n.GetPulseNumber(), p.Rst, p.Entropy)
+	cm.Payload.View = p.GetView()
+	signConfirm(n, cm, p.Height)
+	if err := n.GetClient().Broadcast(ctx, cm); err != nil {
+		r.log.Error(err)
+	}
+	r.Votes = append(r.Votes, cm.Payload)
+}
+
+// CheckCertified makes certificate of accepted proposal once quorum voted for it, it becomes highest certificate,
+// certificate ends leader misses
+func (r *HotStuffConsensus) CheckCertified(n Noder) bool {
+	if r.Proposal == nil {
+		return false
+	}
+	votes := make([]*Confirmation, 0)
+	for _, v := range r.Votes {
+		if v.Entropy == r.Proposal.Entropy && v.Rst == r.Proposal.Rst && v.View == r.Proposal.GetView() {
+			votes = append(votes, v.GetPayload().(*Confirmation))
+		}
+	}
+	if len(votes) < r.Quorum() {
+		return false
+	}
+	qc := &QuorumCert{
+		r.Proposal.Height,
+		r.Proposal.Epoch,
+		r.Proposal.Rst,
+		r.Proposal.Entropy,
+		votes,
+		r.Proposal.From,
+	}
+	r.log.Infof("certified: %s", qc)
+	r.Update(n, qc)
+	return true
+}
@@ -0,0 +1,231 @@
+package node
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// hotStuffCluster prepares 4 nodes where self is leader of the height ahead of committed epoch
+func hotStuffCluster(t *testing.T, ahead uint64) (*HotStuffConsensus, *Node, []*Node) {
+	cons := NewHotStuffConsensus(4, 200, 200, 100, 0)
+	self := signingNode(t)
+	self.store = newStubStorage()
+	self.client = &chanClient{make(chan interface{}, 10)}
+	peers := []*Node{signingNode(t), signingNode(t), signingNode(t)}
+	trust(t, self, peers...)
+	for cons.Leader(self, self.Epoch+ahead) != self.ID {
+		self.Epoch++
+	}
+	for _, p := range peers {
+		p.Epoch = self.Epoch
+		trust(t, p, append([]*Node{self}, peers...)...)
+	}
+	cons.SetRoundStartTime(1000)
+	cons.SetCommitted(self.Epoch)
+	return cons, self, peers
+}
+
+// signedVote vote for entropy proposed at height by its first leader
+func signedVote(n *Node, epoch uint64, rst int64, height uint64, entropy string) PulseConfirmPayload {
+	cm := NewPulseConfirmMessage(n.ID, epoch, rst, entropy)
+	cm.Payload.View = hotStuffView(height, 0)
+	cm.Payload.Signature = n.Sign(cm.Payload.SigningData())
+	return cm.Payload
+}
+
+func certificate(height uint64, epoch uint64, rst int64, entropy string, voters ...*Node) *QuorumCert {
+	qc := &QuorumCert{height, epoch, rst, entropy, nil, ""}
+	for _, v := range voters {
+		qc.Votes = append(qc.Votes, signedVote(v, epoch, rst, height, entropy).GetPayload().(*Confirmation))
+	}
+	return qc
+}
+
+// leaderOf gets node which leads the height
+func leaderOf(cons *HotStuffConsensus, self *Node, nodes []*Node, height uint64) *Node {
+	for _, n := range append([]*Node{self}, nodes...) {
+		if n.ID == cons.Leader(self, height) {
+			return n
+		}
+	}
+	return nil
+}
+
+func TestHotStuffProposalCertifiedByQuorum(t *testing.T) {
+	cons, self, peers := hotStuffCluster(t, 1)
+	client := self.client.(*chanClient)
+	ctx := context.Background()
+
+	cons.ProposeIfLeader(ctx, self)
+	p := (<-client.sent).(*HotStuffProposalMessage).Payload
+	require.Equal(t, self.Epoch+1, p.Height)
+	require.Nil(t, p.Justify)
+	vote := (<-client.sent).(*PulseConfirmMessage).Payload
+	require.Equal(t, p.Entropy, vote.Entropy)
+	require.Equal(t, p.GetView(), vote.View)
+
+	require.False(t, cons.handle(ctx, signedVote(peers[0], self.Epoch, 1000, p.Height, p.Entropy), self))
+	// votes for other entropy or height don't count
+	require.False(t, cons.handle(ctx, signedVote(peers[1], self.Epoch, 1000, p.Height, "other"), self))
+	require.False(t, cons.handle(ctx, signedVote(peers[1], self.Epoch, 1000, p.Height+1, p.Entropy), self))
+	require.True(t, cons.handle(ctx, signedVote(peers[2], self.Epoch, 1000, p.Height, p.Entropy), self))
+
+	require.Equal(t, p.Height, cons.HighQC.Height)
+	require.Len(t, cons.HighQC.Votes, 3)
+	require.True(t, cons.HighQC.Verify(peers[0], cons.Quorum()))
+	// a single certificate doesn't commit
+	require.Empty(t, self.store.(*stubStorage).commits)
+}
+
+func TestHotStuffNextLeaderProposesOnCertificate(t *testing.T) {
+	cons, self, peers := hotStuffCluster(t, 2)
+	client := self.client.(*chanClient)
+	ctx := context.Background()
+	height := self.Epoch + 1
+	leader := leaderOf(cons, self, peers, height)
+
+	p := NewSignedHotStuffProposal(leader, 1000, height, 0, "first", nil).Payload
+	require.False(t, cons.handle(ctx, p, self))
+	require.Equal(t, hotStuffView(height, 0), (<-client.sent).(*PulseConfirmMessage).Payload.View)
+	for _, v := range peers {
+		if v != leader && cons.handle(ctx, signedVote(v, self.Epoch, 1000, height, "first"), self) {
+			break
+		}
+	}
+
+	next := (<-client.sent).(*HotStuffProposalMessage).Payload
+	require.Equal(t, height+1, next.Height)
+	require.Equal(t, "first", next.Justify.Entropy)
+	require.Equal(t, leader.ID, next.Justify.Proposer)
+	require.Equal(t, next.Entropy, (<-client.sent).(*PulseConfirmMessage).Payload.Entropy)
+}
+
+func TestHotStuffCommitsThreeChain(t *testing.T) {
+	cons, self, peers := hotStuffCluster(t, 1)
+	ctx := context.Background()
+	commits := self.store.(*stubStorage).commits
+	epoch := self.Epoch
+
+	var justify *QuorumCert
+	for height := epoch + 1; height <= epoch+4; height++ {
+		require.Empty(t, commits)
+		leader := leaderOf(cons, self, peers, height)
+		entropy := newEntropy()
+		cons.handle(ctx, NewSignedHotStuffProposal(leader, 1000, height, 0, entropy, justify).Payload, self)
+		require.Equal(t, height, cons.Proposals[height].Height)
+		justify = certificate(height, epoch, 1000, entropy, peers...)
+		justify.Proposer = leader.ID
+		cons.Proposal = nil
+	}
+
+	b := <-commits
+	require.Equal(t, epoch+1, b.Epoch)
+	require.Equal(t, epoch+1, b.QC.Height)
+	require.Equal(t, epoch+1, cons.Committed)
+	require.Equal(t, epoch+2, cons.LockedQC.Height)
+	require.Equal(t, epoch+3, cons.HighQC.Height)
+	require.NoError(t, NewBlock(b).VerifyConfirmations(self.publicKeys()))
+}
+
+func TestHotStuffVerifyProposal(t *testing.T) {
+	cons, self, peers := hotStuffCluster(t, 1)
+	replica := peers[0]
+	epoch := self.Epoch
+
+	valid := NewSignedHotStuffProposal(self, 1000, epoch+2, 0, "next", certificate(epoch+1, epoch, 1000, "certified", peers...)).Payload
+	require.True(t, cons.VerifyProposal(replica, valid))
+	require.True(t, cons.VerifyProposal(replica, NewSignedHotStuffProposal(self, 1000, epoch+1, 0, "next", nil).Payload))
+
+	notJustified := NewSignedHotStuffProposal(self, 1000, epoch+2, 0, "next", nil).Payload
+	require.False(t, cons.VerifyProposal(replica, notJustified))
+	noQuorum := NewSignedHotStuffProposal(self, 1000, epoch+2, 0, "next", certificate(epoch+1, epoch, 1000, "certified", peers[:2]...)).Payload
+	require.False(t, cons.VerifyProposal(replica, noQuorum))
+	duplicateVotes := NewSignedHotStuffProposal(self, 1000, epoch+2, 0, "next", certificate(epoch+1, epoch, 1000, "certified", peers[0], peers[0], peers[1])).Payload
+	require.False(t, cons.VerifyProposal(replica, duplicateVotes))
+	forged := certificate(epoch+1, epoch, 1000, "certified", peers...)
+	forged.Entropy = "forged"
+	require.False(t, cons.VerifyProposal(replica, NewSignedHotStuffProposal(self, 1000, epoch+2, 0, "next", forged).Payload))
+	otherHeight := certificate(epoch+1, epoch, 1000, "certified", peers...)
+	otherHeight.Height = epoch + 2
+	require.False(t, cons.VerifyProposal(replica, NewSignedHotStuffProposal(self, 1000, epoch+3, 0, "next", otherHeight).Payload))
+
+	// proposal conflicting with locked certificate is voted only if it's justified by a later round
+	cons.LockedQC = certificate(epoch+2, epoch, 1000, "locked", peers...)
+	require.False(t, cons.VerifyProposal(replica, valid))
+	require.False(t, cons.VerifyProposal(replica, NewSignedHotStuffProposal(self, 1000, epoch+1, 0, "next", nil).Payload))
+	later := NewSignedHotStuffProposal(self, 1001, epoch+2, 0, "next", certificate(epoch+1, epoch, 1001, "certified", peers...)).Payload
+	require.True(t, cons.VerifyProposal(replica, later))
+}
+
+func TestHotStuffMissCountsLeaderTimeout(t *testing.T) {
+	cons, self, peers := hotStuffCluster(t, 1)
+	ctx := context.Background()
+	client := self.client.(*chanClient)
+	height := self.Epoch + 1
+
+	// round without commits isn't a miss while leader gets its height certified
+	cons.ProposeIfLeader(ctx, self)
+	p := (<-client.sent).(*HotStuffProposalMessage).Payload
+	<-client.sent
+	for _, v := range peers {
+		cons.handle(ctx, signedVote(v, self.Epoch, 1000, height, p.Entropy), self)
+	}
+	require.Equal(t, 0, cons.Misses)
+	require.Empty(t, self.store.(*stubStorage).commits)
+
+	next := cons.Leader(self, height+1)
+	cons.LeaderTimeout(ctx, self)
+	require.Equal(t, 1, cons.Misses)
+	require.NotEqual(t, next, cons.Leader(self, height+1))
+
+	// certificate of the next height ends misses
+	cons.Update(self, certificate(height+1, self.Epoch, 1000, "next", peers...))
+	require.Equal(t, 0, cons.Misses)
+}
+
+func TestHotStuffVoteAfterLeaderTimeoutIsNotEquivocation(t *testing.T) {
+	cons, self, peers := hotStuffCluster(t, 3)
+	self.ExcludeEquivocators = true
+	store := self.store.(*stubStorage)
+	client := self.client.(*chanClient)
+	ctx := context.Background()
+	height := self.Epoch + 1
+
+	first := leaderOf(cons, self, peers, height)
+	p := NewSignedHotStuffProposal(first, 1000, height, 0, "first", nil).Payload
+	require.True(t, cons.accept(p, self))
+	cons.handle(ctx, p, self)
+	require.Equal(t, hotStuffView(height, 0), (<-client.sent).(*PulseConfirmMessage).Payload.View)
+
+	cons.LeaderTimeout(ctx, self)
+	second := leaderOf(cons, self, peers, height)
+	require.NotEqual(t, first.ID, second.ID)
+	var replica *Node
+	for _, v := range peers {
+		if v != first && v != second {
+			replica = v
+		}
+	}
+	require.True(t, cons.accept(signedVote(replica, self.Epoch, 1000, height, "first"), self))
+
+	// leader of the next miss proposes the same height again, replica votes for its entropy
+	retry := NewSignedHotStuffProposal(second, 1000, height, 1, "second", nil).Payload
+	require.True(t, cons.accept(retry, self))
+	cons.handle(ctx, retry, self)
+	require.Equal(t, hotStuffView(height, 1), (<-client.sent).(*PulseConfirmMessage).Payload.View)
+	vote := NewPulseConfirmMessage(replica.ID, self.Epoch, 1000, "second")
+	vote.Payload.View = hotStuffView(height, 1)
+	vote.Payload.Signature = replica.Sign(vote.Payload.SigningData())
+	require.True(t, cons.accept(vote.Payload, self))
+	require.Empty(t, cons.Guard.Rejected())
+	require.Empty(t, store.evidence)
+	require.False(t, self.IsExcluded(replica.ID))
+
+	// the second vote for the same proposal view is still equivocation
+	forked := NewPulseConfirmMessage(replica.ID, self.Epoch, 1000, "forked")
+	forked.Payload.View = hotStuffView(height, 1)
+	forked.Payload.Signature = replica.Sign(forked.Payload.SigningData())
+	require.False(t, cons.accept(forked.Payload, self))
+	require.Equal(t, replica.ID, (<-store.evidence).Offender)
+}
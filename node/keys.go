@@ -4,11 +4,14 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/pem"
 	"errors"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"os"
 	"path"
 	"time"
@@ -19,27 +22,40 @@ const (
 	pubKeyFile  = "pub.key"
 )
 
-// WriteKeyPairIfNotExists writes new ecdsa keypair for node if not exists
+// WriteKeyPairIfNotExists writes new ecdsa keypair for node if not exists,
+// keys dir may already exist with keys generated by keygen
 func WriteKeyPairIfNotExists(c *Config) {
-	if _, err := os.Stat(c.Node.Keyspath); os.IsNotExist(err) {
-		if err := os.MkdirAll(c.Node.Keyspath, os.ModePerm); err != nil {
-			log.Fatal(err)
-		}
-		priv, pub := generateNewKeyPair()
-		privPem, pubPem := EncodeKeyPair(priv, pub)
-		log.Printf("path: %s", path.Join(c.Node.Keyspath, privKeyFile))
-		if err := ioutil.WriteFile(path.Join(c.Node.Keyspath, privKeyFile), []byte(privPem), os.ModePerm); err != nil {
-			log.Fatal(err)
-		}
-		if err := ioutil.WriteFile(path.Join(c.Node.Keyspath, pubKeyFile), []byte(pubPem), os.ModePerm); err != nil {
-			log.Fatal(err)
-		}
+	written, err := WriteKeyPair(c.Node.Keyspath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if written {
 		log.Printf("waiting for all nodes to generate keypairs")
 		time.Sleep(5 * time.Second)
 	}
 }
 
-//LoadKeyPair loads private and public keys, with public PEM for messages
+// WriteKeyPair writes new ecdsa keypair to keys dir if it has no keypair yet, returns true if keypair is written
+func WriteKeyPair(keyDir string) (bool, error) {
+	if _, err := os.Stat(path.Join(keyDir, privKeyFile)); !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := os.MkdirAll(keyDir, os.ModePerm); err != nil {
+		return false, err
+	}
+	priv, pub := generateNewKeyPair()
+	privPem, pubPem := EncodeKeyPair(priv, pub)
+	log.Printf("path: %s", path.Join(keyDir, privKeyFile))
+	if err := ioutil.WriteFile(path.Join(keyDir, privKeyFile), []byte(privPem), os.ModePerm); err != nil {
+		return false, err
+	}
+	if err := ioutil.WriteFile(path.Join(keyDir, pubKeyFile), []byte(pubPem), os.ModePerm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadKeyPair loads private and public keys, with public PEM for messages
 func LoadKeyPair(c *Config) (*ecdsa.PrivateKey, *ecdsa.PublicKey, string) {
 	privPath := path.Join(c.Node.Keyspath, privKeyFile)
 	pubPath := path.Join(c.Node.Keyspath, pubKeyFile)
@@ -126,3 +142,16 @@ func DecodePublicKey(pemEncodedPub string) (*ecdsa.PublicKey, error) {
 	}
 	return nil, errors.New("block pub is nil")
 }
+
+// VerifySignature verifies asn1 ecdsa signature over sha256 digest of data
+func VerifySignature(pub *ecdsa.PublicKey, data []byte, signature []byte) bool {
+	// by default ecdsa marshal signature in asn1 format
+	var esig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(signature, &esig); err != nil {
+		return false
+	}
+	digest := sha256.Sum256(data)
+	return ecdsa.Verify(pub, digest[:], esig.R, esig.S)
+}
@@ -0,0 +1,14 @@
+package node
+
+import (
+	"github.com/spf13/viper"
+	"os"
+	"testing"
+)
+
+// TestMain configures logging of loggers created in tests
+func TestMain(m *testing.M) {
+	viper.SetDefault("logging.level", "debug")
+	viper.SetDefault("logging.encoding", "console")
+	os.Exit(m.Run())
+}
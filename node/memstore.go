@@ -0,0 +1,103 @@
+package node
+
+import (
+	"context"
+	"rounds/logger"
+	"sync"
+)
+
+// MemoryStorage keeps blocks in memory, nodes of a cluster running in one process can share it as ledger
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	blocks   map[uint64]*Block
+	latest   *Block
+	evidence []*Evidence
+
+	log *logger.Logger
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		blocks: make(map[uint64]*Block),
+		log:    logger.NewLogger(),
+	}
+}
+
+// Commit chains block to the previous epoch block
+func (m *MemoryStorage) Commit(ctx context.Context, d BlockData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := NewBlock(d)
+	stored, err := ChainBlock(b, m.latest, m.getBlock)
+	if err != nil {
+		return err
+	}
+	if stored != b {
+		return VerifyCommitted(stored, b)
+	}
+	m.log.Infof("committing pulse: %s", b.String())
+	m.blocks[b.Epoch] = b
+	m.latest = b
+	return nil
+}
+
+func (m *MemoryStorage) getBlock(epoch uint64) (*Block, error) {
+	return m.blocks[epoch], nil
+}
+
+func (m *MemoryStorage) GetBlock(epoch uint64) (*Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.getBlock(epoch)
+}
+
+func (m *MemoryStorage) GetLatestBlock() (*Block, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest, nil
+}
+
+func (m *MemoryStorage) GetLatestBlockEpoch() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.latest == nil {
+		return 0
+	}
+	return m.latest.Epoch
+}
+
+func (m *MemoryStorage) GetBlockRange(from uint64, limit int) ([]*Block, uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.latest == nil {
+		return []*Block{}, 0, nil
+	}
+	if from == 0 {
+		from = 1
+	}
+	blocks := make([]*Block, 0)
+	epoch := from
+	for ; epoch <= m.latest.Epoch && len(blocks) < limit; epoch++ {
+		if b, ok := m.blocks[epoch]; ok {
+			blocks = append(blocks, b)
+		}
+	}
+	if epoch > m.latest.Epoch {
+		return blocks, 0, nil
+	}
+	return blocks, epoch, nil
+}
+
+func (m *MemoryStorage) CommitEvidence(ctx context.Context, e *Evidence) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evidence = append(m.evidence, e)
+	return nil
+}
+
+// Evidence gets committed evidence
+func (m *MemoryStorage) Evidence() []*Evidence {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*Evidence{}, m.evidence...)
+}
@@ -0,0 +1,45 @@
+package node
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// testBlockRange checks pages of 5 committed blocks, range is inclusive of both its epoch and the latest one
+func testBlockRange(t *testing.T, s Storage) {
+	ctx := context.Background()
+	for epoch := uint64(1); epoch <= 5; epoch++ {
+		d, err := NewBlockData(epoch, int64(1000+epoch), []string{"N0"}, "entropy", nil)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit(ctx, d))
+	}
+	pages := []struct {
+		from   uint64
+		limit  int
+		epochs []uint64
+		next   uint64
+	}{
+		{0, 2, []uint64{1, 2}, 3},
+		{3, 2, []uint64{3, 4}, 5},
+		{5, 2, []uint64{5}, 0},
+		// full page ending with the latest block is the last one
+		{4, 2, []uint64{4, 5}, 0},
+		{1, 10, []uint64{1, 2, 3, 4, 5}, 0},
+		{6, 2, []uint64{}, 0},
+	}
+	for _, p := range pages {
+		blocks, next, err := s.GetBlockRange(p.from, p.limit)
+		require.NoError(t, err)
+		epochs := make([]uint64, 0, len(blocks))
+		for _, b := range blocks {
+			epochs = append(epochs, b.Epoch)
+		}
+		require.Equal(t, p.epochs, epochs, "range from %d", p.from)
+		require.Equal(t, p.next, next, "next of range from %d", p.from)
+	}
+}
+
+func TestMemoryStorageBlockRange(t *testing.T) {
+	testBlockRange(t, NewMemoryStorage())
+}
@@ -4,7 +4,7 @@ package node
 
 import (
 	"fmt"
-	"github.com/mr-tron/base58"
+	"github.com/prometheus/common/log"
 )
 
 type MsgType int
@@ -12,11 +12,33 @@ type MsgType int
 const (
 	Collect MsgType = iota
 	Vector
+	Confirm
+	RequestVote
+	Vote
+	Append
+	PrePrepare
+	Prepare
+	ViewChange
+	NewView
+	Propose
+	SyncRequest
+	SyncResponse
+	RevealSet
 )
 
 type Messager interface {
+	// GetType gets message type
+	GetType() MsgType
+	// GetEpoch gets epoch message was sent in
+	GetEpoch() uint64
+	// GetRst gets round start time message was sent in
+	GetRst() int64
+	// GetFrom gets sender of the message
+	GetFrom() string
 	// GetSignature gets message signature
 	GetSignature() []byte
+	// SigningData gets canonical message bytes covered by signature
+	SigningData() []byte
 	// GetPayload gets message payload
 	GetPayload() interface{}
 }
@@ -38,27 +60,33 @@ type PulseMessage struct {
 	Payload PulseMessagePayload `json:"payload"`
 }
 
-func NewPulseMessage(from string, signature []byte, epoch uint64, rst int64) *PulseMessage {
-	t := randomBytesString(16)
-	t = base58.Encode([]byte(t))
+// NewPulseMessage creates unsigned pulse proposal message with commitment to node entropy
+func NewPulseMessage(from string, epoch uint64, rst int64, commitment string) *PulseMessage {
 	return &PulseMessage{
 		Header: Header{
 			Type: Collect,
 		},
 		Payload: PulseMessagePayload{
-			Signature:     signature,
 			Epoch:         epoch,
 			Rst:           rst,
 			From:          from,
-			PulseProposal: NewPulseProposal(from, t),
+			PulseProposal: NewPulseProposal(from, commitment),
 		},
 	}
 }
 
+func (m PulseMessagePayload) GetType() MsgType {
+	return Collect
+}
+
 func (m PulseMessagePayload) GetEpoch() uint64 {
 	return m.Epoch
 }
 
+func (m PulseMessagePayload) GetRst() int64 {
+	return m.Rst
+}
+
 func (m PulseMessagePayload) GetPayload() interface{} {
 	return m.PulseProposal
 }
@@ -67,6 +95,17 @@ func (m PulseMessagePayload) GetSignature() []byte {
 	return m.Signature
 }
 
+func (m PulseMessagePayload) GetFrom() string {
+	return m.From
+}
+
+// SigningData encodes message header and proposal
+func (m PulseMessagePayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.Proposal(m.PulseProposal)
+	return e.Bytes()
+}
+
 func (m PulseMessagePayload) String() string {
 	return fmt.Sprintf(
 		"[ rst: %d, from: %s, proposal: %s ]",
@@ -82,6 +121,9 @@ type PulseVectorPayload struct {
 	Epoch           uint64       `json:"epoch"`
 	From            string       `json:"from"`
 	EntropiesVector *PulseVector `json:"entropies_vector"`
+	// Proof VRF proof of sender entropy for the pulse, its output is entropy collect commitment was made to,
+	// proof reveals the output, so it's sent only after collect phase
+	Proof []byte `json:"proof"`
 }
 
 type PulseVectorMessage struct {
@@ -89,25 +131,34 @@ type PulseVectorMessage struct {
 	Payload PulseVectorPayload `json:"payload"`
 }
 
-func NewPulseVectorMessage(from string, signature []byte, epoch uint64, rst int64, ens *PulseVector) *PulseVectorMessage {
+// NewPulseVectorMessage creates unsigned message with proposals vector collected by node and VRF proof revealing its entropy
+func NewPulseVectorMessage(from string, epoch uint64, rst int64, ens *PulseVector, proof []byte) *PulseVectorMessage {
 	return &PulseVectorMessage{
 		Header: Header{
 			Type: Vector,
 		},
 		Payload: PulseVectorPayload{
-			Signature:       signature,
 			Rst:             rst,
 			Epoch:           epoch,
 			From:            from,
 			EntropiesVector: ens,
+			Proof:           proof,
 		},
 	}
 }
 
+func (m PulseVectorPayload) GetType() MsgType {
+	return Vector
+}
+
 func (m PulseVectorPayload) GetEpoch() uint64 {
 	return m.Epoch
 }
 
+func (m PulseVectorPayload) GetRst() int64 {
+	return m.Rst
+}
+
 func (m PulseVectorPayload) GetPayload() interface{} {
 	return m.EntropiesVector
 }
@@ -116,11 +167,147 @@ func (m PulseVectorPayload) GetSignature() []byte {
 	return m.Signature
 }
 
+func (m PulseVectorPayload) GetFrom() string {
+	return m.From
+}
+
+// SigningData encodes message header, VRF proof and every proposal of the vector in order
+func (m PulseVectorPayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.Blob(m.Proof)
+	if m.EntropiesVector == nil {
+		e.String("")
+		e.Uint64(0)
+		return e.Bytes()
+	}
+	e.String(m.EntropiesVector.From)
+	e.Uint64(uint64(len(m.EntropiesVector.Vector)))
+	for _, p := range m.EntropiesVector.Vector {
+		e.Proposal(p)
+	}
+	return e.Bytes()
+}
+
 func (m PulseVectorPayload) String() string {
 	return fmt.Sprintf(
-		"[ rst: %d, from: %s, vector: %s ]",
+		"[ rst: %d, from: %s, vector: %s, proof: %x ]",
 		m.Rst,
 		m.From,
 		m.EntropiesVector,
+		m.Proof,
+	)
+}
+
+// PulseConfirmPayload signed agreement on winning entropy,
+// Epoch is the latest committed epoch round builds on, confirmed block gets Epoch+1
+type PulseConfirmPayload struct {
+	Signature []byte
+	Rst       int64  `json:"rst"`
+	Epoch     uint64 `json:"epoch"`
+	From      string `json:"from"`
+	Entropy   string `json:"entropy"`
+	// Share threshold signature share of confirmed block, empty if threshold signing is disabled
+	Share []byte `json:"share,omitempty"`
+	// View the entropy is confirmed in, only for engines which change views within a round
+	View uint64 `json:"view,omitempty"`
+}
+
+type PulseConfirmMessage struct {
+	Header
+	Payload PulseConfirmPayload `json:"payload"`
+}
+
+// NewPulseConfirmMessage creates unsigned confirmation of winning entropy
+func NewPulseConfirmMessage(from string, epoch uint64, rst int64, entropy string) *PulseConfirmMessage {
+	return &PulseConfirmMessage{
+		Header: Header{
+			Type: Confirm,
+		},
+		Payload: PulseConfirmPayload{
+			Rst:     rst,
+			Epoch:   epoch,
+			From:    from,
+			Entropy: entropy,
+		},
+	}
+}
+
+// NewSignedConfirmMessage creates confirmation of entropy signed by node, entropy is confirmed for the next block
+func NewSignedConfirmMessage(n Noder, rst int64, entropy string) *PulseConfirmMessage {
+	return NewSignedBlockConfirmMessage(n, rst, n.GetPulseNumber()+1, entropy)
+}
+
+// NewSignedBlockConfirmMessage creates confirmation of entropy signed by node,
+// with threshold signature share of the block of epoch if threshold signing is enabled
+func NewSignedBlockConfirmMessage(n Noder, rst int64, epoch uint64, entropy string) *PulseConfirmMessage {
+	cm := NewPulseConfirmMessage(n.GetID(), n.GetPulseNumber(), rst, entropy)
+	signConfirm(n, cm, epoch)
+	return cm
+}
+
+// NewSignedViewConfirmMessage creates confirmation of entropy prepared in view signed by node, entropy is confirmed for the next block
+func NewSignedViewConfirmMessage(n Noder, rst int64, view uint64, entropy string) *PulseConfirmMessage {
+	cm := NewPulseConfirmMessage(n.GetID(), n.GetPulseNumber(), rst, entropy)
+	cm.Payload.View = view
+	signConfirm(n, cm, n.GetPulseNumber()+1)
+	return cm
+}
+
+// signConfirm signs confirmation, adding threshold signature share of the block of epoch if threshold signing is enabled
+func signConfirm(n Noder, cm *PulseConfirmMessage, epoch uint64) {
+	if t := n.GetThreshold(); t != nil {
+		share, err := signBlockShare(t, epoch, cm.Payload.Entropy)
+		if err != nil {
+			log.Error(err)
+		}
+		cm.Payload.Share = share
+	}
+	cm.Payload.Signature = n.Sign(cm.Payload.SigningData())
+}
+
+func (m PulseConfirmPayload) GetType() MsgType {
+	return Confirm
+}
+
+func (m PulseConfirmPayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m PulseConfirmPayload) GetRst() int64 {
+	return m.Rst
+}
+
+func (m PulseConfirmPayload) GetPayload() interface{} {
+	return &Confirmation{m.From, m.Signature, m.Share, m.View}
+}
+
+// GetView confirmations of different views are sent in one round by engines which change views within a round
+func (m PulseConfirmPayload) GetView() uint64 {
+	return m.View
+}
+
+func (m PulseConfirmPayload) GetSignature() []byte {
+	return m.Signature
+}
+
+func (m PulseConfirmPayload) GetFrom() string {
+	return m.From
+}
+
+// SigningData encodes message header with view, confirmed entropy and signature share
+func (m PulseConfirmPayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.String(m.Entropy)
+	e.Blob(m.Share)
+	return e.Bytes()
+}
+
+func (m PulseConfirmPayload) String() string {
+	return fmt.Sprintf(
+		"[ rst: %d, from: %s, view: %d, entropy: %s ]",
+		m.Rst,
+		m.From,
+		m.View,
+		m.Entropy,
 	)
 }
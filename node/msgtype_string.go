@@ -10,11 +10,23 @@ func _() {
 	var x [1]struct{}
 	_ = x[Collect-0]
 	_ = x[Vector-1]
+	_ = x[Confirm-2]
+	_ = x[RequestVote-3]
+	_ = x[Vote-4]
+	_ = x[Append-5]
+	_ = x[PrePrepare-6]
+	_ = x[Prepare-7]
+	_ = x[ViewChange-8]
+	_ = x[NewView-9]
+	_ = x[Propose-10]
+	_ = x[SyncRequest-11]
+	_ = x[SyncResponse-12]
+	_ = x[RevealSet-13]
 }
 
-const _MsgType_name = "CollectVector"
+const _MsgType_name = "CollectVectorConfirmRequestVoteVoteAppendPrePreparePrepareViewChangeNewViewProposeSyncRequestSyncResponseRevealSet"
 
-var _MsgType_index = [...]uint8{0, 7, 13}
+var _MsgType_index = [...]uint8{0, 7, 13, 20, 31, 35, 41, 51, 58, 68, 75, 82, 93, 105, 114}
 
 func (i MsgType) String() string {
 	if i < 0 || i >= MsgType(len(_MsgType_index)-1) {
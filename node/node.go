@@ -3,34 +3,33 @@ package node
 import (
 	"context"
 	"crypto/ecdsa"
-	"crypto/md5"
 	"crypto/rand"
-	"encoding/asn1"
+	"crypto/sha256"
 	"encoding/json"
-	"math/big"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"log"
 	"net"
 	"rounds/logger"
 	"time"
 )
 
-var (
-	DummyHashData = []byte("dummy")
-)
-
 // Noder describes pulse consensus node
 type Noder interface {
-	// VerifyMessageTrusted verifies that message is from known peer
-	VerifyMessageTrusted([]byte) bool
+	// VerifyMessageTrusted verifies that message is signed by the peer it claims to be from
+	VerifyMessageTrusted(msg Messager) bool
 	// GetClient gets node client
 	GetClient() Clienter
+	// GetID gets node id derived from public key
+	GetID() string
 	// GetAddr gets current receiving network port
 	GetAddr() string
-	// Sign signs new string
+	// Sign signs sha256 digest of data
 	Sign(data []byte) []byte
 	// Commit stores block
 	Commit(context.Context, BlockData) error
 	// GetLatestPulse gets latest block, by epoch
-	GetLatestPulse() (*BlockData, error)
+	GetLatestPulse() (*Block, error)
 	// GetLatestPulseNumber
 	GetLatestPulseNumber() uint64
 	// GetPulseNumber
@@ -39,22 +38,41 @@ type Noder interface {
 	SetPulseNumber(epoch uint64)
 	// RouteMsg
 	RouteMsg(addr net.Addr, rawMsg map[string]*json.RawMessage)
+	// GetPeers gets registry of known peers
+	GetPeers() *PeerRegistry
+	// IsExcluded checks if peer is excluded from rounds
+	IsExcluded(id string) bool
+	// ReportEquivocation stores evidence of conflicting messages from a peer
+	ReportEquivocation(e *Evidence)
+	// ProveVrf computes VRF output and proof for input with node key
+	ProveVrf(alpha []byte) ([]byte, []byte)
+	// VerifyVrf verifies VRF proof of a peer, returns VRF output
+	VerifyVrf(from string, alpha []byte, proof []byte) ([]byte, bool)
+	// GetThreshold gets node threshold key share, nil if threshold signing is disabled
+	GetThreshold() *Threshold
 }
 
 type Node struct {
-	privateKey      *ecdsa.PrivateKey
-	publicKey       *ecdsa.PublicKey
-	publicKeyPem    string
-	receiving       bool
-	transport       Transport
-	Addr            string
-	Reconnect       int
-	client          Clienter
-	Consensus       Consensus
-	peersPublicKeys []*ecdsa.PublicKey
+	privateKey   *ecdsa.PrivateKey
+	publicKey    *ecdsa.PublicKey
+	publicKeyPem string
+	receiving    bool
+	transport    Transport
+	ID           string
+	Addr         string
+	Reconnect    int
+	client       Clienter
+	Consensus    Consensus
+	peers        *PeerRegistry
+	// ExcludeEquivocators excludes peers from later rounds after equivocation is detected
+	ExcludeEquivocators bool
+	// threshold key share for group signatures of blocks, nil if disabled
+	threshold *Threshold
 
 	Epoch uint64
 	store Storage
+	// syncResponses responses of peers to catch-up sync requests
+	syncResponses chan SyncResponsePayload
 
 	log *logger.Logger
 }
@@ -63,40 +81,65 @@ func (n *Node) PublicKeyPem() string {
 	return n.publicKeyPem
 }
 
-func NewNode(c *Config, priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey, pubPem string) *Node {
-	tlsClient, tlsSrv := tlsContexts()
-	s := NewBadgerStorage(c.Store.Host)
+// NodeOption overrides node dependencies built from config
+type NodeOption func(n *Node)
+
+// WithStorage commits blocks to storage instead of the one of config store type
+func WithStorage(s Storage) NodeOption {
+	return func(n *Node) {
+		n.store = s
+	}
+}
+
+func NewNode(c *Config, priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey, pubPem string, opts ...NodeOption) *Node {
 	var transport Transport
 	var client Clienter
 	switch c.Node.Transport {
 	case "tcp":
+		tlsClient, tlsSrv := tlsContexts()
 		transport = NewTCPTransport(tlsSrv)
 		client = NewTCPClient(c, tlsClient)
 	case "udp":
 		transport = NewUDPTransport()
 		client = NewUDPClient(c)
 	}
+	id, err := NodeID(pub)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cons, err := NewConsensus(c)
+	if err != nil {
+		log.Fatal(err)
+	}
 	n := &Node{
 		priv,
 		pub,
 		pubPem,
 		false,
 		transport,
+		id,
 		c.Node.Addr,
 		c.Node.Reconnect,
 		client,
-		NewPulseConsensus(
-			c.Node.Rounds.Collect.Duration,
-			c.Node.Rounds.Exchange.Duration,
-			c.Node.Rounds.Collect.MaxMessages,
-			c.Node.Rounds.Exchange.MaxMessages,
-		),
+		cons,
 		nil,
+		c.Node.ExcludeEquivocators,
+		LoadThreshold(c),
 		0,
-		s,
+		nil,
+		make(chan SyncResponsePayload, len(c.Node.Peers)),
 		logger.NewLogger(),
 	}
-	n.LoadPeerPublicKeys(c.Node.Peers)
+	for _, opt := range opts {
+		opt(n)
+	}
+	if n.store == nil {
+		if n.store, err = NewStorage(c); err != nil {
+			log.Fatal(err)
+		}
+	}
+	n.log.Infof("node id: %s, consensus engine: %s", n.ID, cons.Name())
+	n.LoadPeers(c.Node.Peers)
 	n.Epoch = n.GetLatestPulseNumber()
 	return n
 }
@@ -113,8 +156,7 @@ func (n *Node) GetLatestPulseNumber() uint64 {
 	return n.store.GetLatestBlockEpoch()
 }
 
-func (n *Node) GetLatestPulse() (*BlockData, error) {
-	// no iterator api in cete for now
+func (n *Node) GetLatestPulse() (*Block, error) {
 	b, err := n.store.GetLatestBlock()
 	if err != nil {
 		return nil, err
@@ -129,19 +171,36 @@ func (n *Node) Commit(ctx context.Context, b BlockData) error {
 	return nil
 }
 
-func (n *Node) LoadPeerPublicKeys(peers []Peer) {
-	keys := make([]*ecdsa.PublicKey, 0)
-	for _, pubKeyPem := range peers {
-		n.log.Infof("pub key loaded from: %s", pubKeyPem.PubKeyDir)
-		keys = append(keys, LoadPublicKey(pubKeyPem.PubKeyDir))
+// LoadPeers loads peers public keys into registry, ids are derived from keys
+func (n *Node) LoadPeers(peers []Peer) {
+	r, err := LoadPeerRegistry(peers)
+	if err != nil {
+		n.log.Fatal(err)
+	}
+	for _, p := range r.Peers() {
+		n.log.Infof("peer loaded: %s, addr: %s", p.ID, p.Addr)
 	}
-	n.peersPublicKeys = keys
+	n.peers = r
+}
+
+// GetPeers gets registry of known peers
+func (n *Node) GetPeers() *PeerRegistry {
+	return n.peers
+}
+
+// GetThreshold gets node threshold key share, nil if threshold signing is disabled
+func (n *Node) GetThreshold() *Threshold {
+	return n.threshold
 }
 
 func (n *Node) GetClient() Clienter {
 	return n.client
 }
 
+func (n *Node) GetID() string {
+	return n.ID
+}
+
 func (n *Node) GetAddr() string {
 	return n.Addr
 }
@@ -150,41 +209,78 @@ func (n *Node) GetAddr() string {
 //	n.client.ConnectPeers(n.Reconnect)
 //}
 
-// VerifyMessageTrusted gets signature from message and check if it's any known peer message
-func (n *Node) VerifyMessageTrusted(signature []byte) bool {
-	for _, pubKey := range n.peersPublicKeys {
-		hasher := md5.New()
-		if _, err := hasher.Write(DummyHashData); err != nil {
-			n.log.Error(err)
-		}
-		// by default ecdsa marshal signature in asn1 format
-		var esig struct {
-			R, S *big.Int
-		}
-		if _, err := asn1.Unmarshal(signature, &esig); err != nil {
-			n.log.Error(err)
-			continue
-		}
-		verified := ecdsa.Verify(pubKey, hasher.Sum(nil), esig.R, esig.S)
-		if verified {
-			return true
-		}
+// VerifyMessageTrusted checks message signature against public key registered for the id message claims to be from
+func (n *Node) VerifyMessageTrusted(msg Messager) bool {
+	if msg.GetFrom() == n.ID {
+		return VerifySignature(n.publicKey, msg.SigningData(), msg.GetSignature())
 	}
-	return false
+	peer, ok := n.peers.Get(msg.GetFrom())
+	if !ok {
+		n.log.Errorf("message from unknown peer: %s", msg.GetFrom())
+		return false
+	}
+	return VerifySignature(peer.PublicKey, msg.SigningData(), msg.GetSignature())
+}
+
+func (n *Node) IsExcluded(id string) bool {
+	return n.peers.IsExcluded(id)
 }
 
-// Sign signs dummy hash with private key
+// ReportEquivocation stores evidence in ledger and excludes offender if configured
+func (n *Node) ReportEquivocation(e *Evidence) {
+	peer, ok := n.peers.Get(e.Offender)
+	if !ok || !e.Verify(peer.PublicKey) {
+		n.log.Errorf("invalid equivocation evidence: %s", e)
+		return
+	}
+	n.log.Warnf("equivocation detected: %s", e)
+	if n.ExcludeEquivocators {
+		n.log.Warnf("excluding peer from rounds: %s", e.Offender)
+		n.peers.Exclude(e.Offender)
+	}
+	go func() {
+		if err := n.store.CommitEvidence(context.Background(), e); err != nil {
+			n.log.Error(ErrStorageConnection(err))
+		}
+	}()
+}
+
+// Sign signs sha256 digest of data with private key
 func (n *Node) Sign(data []byte) []byte {
-	h := md5.New()
-	if _, err := h.Write(data); err != nil {
+	digest := sha256.Sum256(data)
+	sign, err := n.privateKey.Sign(rand.Reader, digest[:], nil)
+	if err != nil {
 		n.log.Fatal(err)
 	}
-	signhash := h.Sum(nil)
-	sign, err := n.privateKey.Sign(rand.Reader, signhash, nil)
+	return sign
+}
+
+// ProveVrf computes VRF output and proof for input with private key
+func (n *Node) ProveVrf(alpha []byte) ([]byte, []byte) {
+	beta, proof, err := VrfProve(n.privateKey, alpha)
 	if err != nil {
 		n.log.Fatal(err)
 	}
-	return sign
+	return beta, proof
+}
+
+// VerifyVrf checks VRF proof against public key registered for the id, returns VRF output
+func (n *Node) VerifyVrf(from string, alpha []byte, proof []byte) ([]byte, bool) {
+	pub := n.publicKey
+	if from != n.ID {
+		peer, ok := n.peers.Get(from)
+		if !ok {
+			n.log.Errorf("vrf proof of unknown peer: %s", from)
+			return nil, false
+		}
+		pub = peer.PublicKey
+	}
+	beta, err := VrfVerify(pub, alpha, proof)
+	if err != nil {
+		n.log.Error(ErrInvalidVrfProof(from, err))
+		return nil, false
+	}
+	return beta, true
 }
 
 // Schedule schedules round timings so it can be synced between nodes
@@ -203,65 +299,70 @@ func (n *Node) Schedule(cfg *Config) {
 	}
 }
 
-// Processing runs rounds forever, reacts to Schedule() signals to channels
+// Processing runs rounds forever, reacts to Schedule() signals to channels,
+// node catches up with peers before the first round and after rounds it hasn't committed in
 func (n *Node) Processing() {
+	n.catchUp()
 	for {
 		cons := n.Consensus
 		startTimeUnix := <-cons.GetStartChan()
-		cons.FlushData()
-		cons.SetRoundStartTime(startTimeUnix)
-
-		// Send pulses to all
-		ctx, cancel1 := context.WithTimeout(context.Background(), time.Duration(cons.GetCollectDuration())*time.Millisecond)
-		cons.SendPulses(ctx, n)
-		cons.ReceivePulses(ctx, n)
-
-		// After timeout send vectors to all
-		ctx2, cancel2 := context.WithTimeout(context.Background(), time.Duration(cons.GetExchangeDuration())*time.Millisecond)
-		cons.SendVectors(ctx2, n)
-		cons.ReceiveVectors(ctx2, n)
-
-		// Calculate approved data set
-		ctx3, cancel3 := context.WithTimeout(context.Background(), time.Duration(cons.GetCollectDuration())*time.Millisecond)
-		cons.Commit(ctx3, n)
+		startTime := time.Now()
+		epoch := n.GetPulseNumber()
+		cons.Round(startTimeUnix, n)
 
 		bn := n.GetLatestPulseNumber()
 		n.SetPulseNumber(bn)
+		n.RecordRound(startTime, bn > epoch)
+		if bn == epoch {
+			n.catchUp()
+			bn = n.GetPulseNumber()
+		}
 		n.log.Infof("next pulse number: %d", bn+1)
-		cancel1()
-		cancel2()
-		cancel3()
 	}
 }
 
+// RecordRound records round latency by engine and if round has produced a pulse
+func (n *Node) RecordRound(startTime time.Time, committed bool) {
+	status := RoundStatusCommitted
+	if !committed {
+		status = RoundStatusFailed
+	}
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(KeyEngine, n.Consensus.Name()),
+		tag.Insert(KeyStatus, status),
+	)
+	if err != nil {
+		n.log.Error(err)
+		return
+	}
+	stats.Record(ctx, RoundMs.M(SinceInMilliseconds(startTime)))
+}
+
 // Serve receives all messages, send them to router
 func (n *Node) StartTransport() {
 	n.transport.Serve(n)
 }
 
-// RouteMsg routes messages to channel by type
+// RouteMsg routes messages to consensus engine by type
 func (n *Node) RouteMsg(addr net.Addr, rawMsg map[string]*json.RawMessage) {
+	if rawMsg["type"] == nil || rawMsg["payload"] == nil {
+		n.log.Infof("[ %s ] malformed msg, dropping", addr)
+		return
+	}
 	var msgType MsgType
 	if err := json.Unmarshal(*rawMsg["type"], &msgType); err != nil {
 		n.log.Error(err)
+		return
 	}
 	n.log.Debugf("[ %s ] received msg: %s", addr, msgType.String())
-	switch msgType {
-	case Collect:
-		var pulsePayload = PulseMessagePayload{}
-		if err := json.Unmarshal(*rawMsg["payload"], &pulsePayload); err != nil {
-			n.log.Error(err)
-		}
-		n.log.Debugf("[ %s ] parsed msg: %s:%s", addr, msgType.String(), pulsePayload.String())
-		n.Consensus.GetPulsesChan() <- pulsePayload
-	case Vector:
-		var vectorPayload = PulseVectorPayload{}
-		if err := json.Unmarshal(*rawMsg["payload"], &vectorPayload); err != nil {
-			n.log.Error(err)
+	if msgType == SyncRequest || msgType == SyncResponse {
+		if err := n.receiveSync(msgType, *rawMsg["payload"]); err != nil {
+			n.log.Infof("[ %s ] failed to sync: %s", addr, err)
 		}
-		n.log.Debugf("[ %s ] parsed msg: %s:%s", addr, msgType.String(), vectorPayload.String())
-		n.Consensus.GetVectorsChan() <- vectorPayload
-	default:
-		n.log.Infof("unknown message type received: %s", msgType)
+		return
+	}
+	if err := n.Consensus.Receive(msgType, *rawMsg["payload"]); err != nil {
+		n.log.Infof("[ %s ] failed to receive msg: %s", addr, err)
 	}
 }
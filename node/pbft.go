@@ -0,0 +1,554 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	PbftEngine = "pbft"
+)
+
+func init() {
+	RegisterEngine(PbftEngine, func(c *Config) Consensus {
+		return NewPbftConsensus(
+			c.ClusterSize(),
+			c.Node.Rounds.Collect.Duration,
+			c.Node.Rounds.Exchange.Duration,
+			c.Node.Rounds.Collect.MaxMessages+c.Node.Rounds.Exchange.MaxMessages,
+			c.Node.Rounds.RstTolerance,
+		)
+	})
+}
+
+// PbftMessagePayload pre-prepare, prepare, view-change and new-view messages of pbft engine
+type PbftMessagePayload struct {
+	Signature []byte
+	Type      MsgType `json:"type"`
+	Rst       int64   `json:"rst"`
+	Epoch     uint64  `json:"epoch"`
+	From      string  `json:"from"`
+	View      uint64  `json:"view"`
+	// Entropy proposed by primary, for view-change it's entropy sender has prepared, if any
+	Entropy string `json:"entropy"`
+	// ViewChanges signed view-change messages new primary collected, only in new-view
+	ViewChanges []PbftMessagePayload `json:"viewChanges"`
+	// Prepared certificate of entropy sender has prepared in the highest view, only in view-change
+	Prepared *PreparedCert `json:"prepared,omitempty"`
+}
+
+// PreparedCert proves entropy is prepared in a view: pre-prepare of primary of the view
+// and prepares of the same view and entropy signed by 2f distinct backups
+type PreparedCert struct {
+	PrePrepare PbftMessagePayload   `json:"prePrepare"`
+	Prepares   []PbftMessagePayload `json:"prepares"`
+}
+
+type PbftMessage struct {
+	Header
+	Payload PbftMessagePayload `json:"payload"`
+}
+
+// NewSignedPbftMessage creates pbft message signed by node
+func NewSignedPbftMessage(n Noder, t MsgType, rst int64, view uint64, entropy string, viewChanges []PbftMessagePayload) *PbftMessage {
+	m := &PbftMessage{
+		Header: Header{
+			Type: t,
+		},
+		Payload: PbftMessagePayload{
+			Type:        t,
+			Rst:         rst,
+			Epoch:       n.GetPulseNumber(),
+			From:        n.GetID(),
+			View:        view,
+			Entropy:     entropy,
+			ViewChanges: viewChanges,
+		},
+	}
+	m.Payload.Signature = n.Sign(m.Payload.SigningData())
+	return m
+}
+
+// NewSignedViewChangeMessage creates view-change to view signed by node, carrying certificate of prepared entropy, if any
+func NewSignedViewChangeMessage(n Noder, rst int64, view uint64, prepared *PreparedCert) *PbftMessage {
+	m := &PbftMessage{
+		Header: Header{
+			Type: ViewChange,
+		},
+		Payload: PbftMessagePayload{
+			Type:     ViewChange,
+			Rst:      rst,
+			Epoch:    n.GetPulseNumber(),
+			From:     n.GetID(),
+			View:     view,
+			Prepared: prepared,
+		},
+	}
+	if prepared != nil {
+		m.Payload.Entropy = prepared.PrePrepare.Entropy
+	}
+	m.Payload.Signature = n.Sign(m.Payload.SigningData())
+	return m
+}
+
+func (m PbftMessagePayload) GetType() MsgType {
+	return m.Type
+}
+
+func (m PbftMessagePayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m PbftMessagePayload) GetRst() int64 {
+	return m.Rst
+}
+
+func (m PbftMessagePayload) GetFrom() string {
+	return m.From
+}
+
+func (m PbftMessagePayload) GetView() uint64 {
+	return m.View
+}
+
+func (m PbftMessagePayload) GetPayload() interface{} {
+	return m.Entropy
+}
+
+func (m PbftMessagePayload) GetSignature() []byte {
+	return m.Signature
+}
+
+// SigningData encodes message header with view, entropy, signed view-changes and prepared certificate
+func (m PbftMessagePayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.String(m.Entropy)
+	encodeSigned(e, m.ViewChanges)
+	if m.Prepared == nil {
+		e.Uint64(0)
+	} else {
+		e.Uint64(1)
+		encodeSigned(e, []PbftMessagePayload{m.Prepared.PrePrepare})
+		encodeSigned(e, m.Prepared.Prepares)
+	}
+	return e.Bytes()
+}
+
+// encodeSigned writes length prefixed list of signed pbft messages
+func encodeSigned(e *canonicalEncoder, ms []PbftMessagePayload) {
+	e.Uint64(uint64(len(ms)))
+	for _, m := range ms {
+		e.Blob(m.SigningData())
+		e.Blob(m.Signature)
+	}
+}
+
+func (m PbftMessagePayload) String() string {
+	return fmt.Sprintf(
+		"[ type: %s, rst: %d, from: %s, view: %d, entropy: %s, view changes: %d ]",
+		m.Type,
+		m.Rst,
+		m.From,
+		m.View,
+		m.Entropy,
+		len(m.ViewChanges),
+	)
+}
+
+// PbftConsensus byzantine fault tolerant consensus in the style of pbft, primary rotates every epoch,
+// it pre-prepares entropy, backups prepare it, when 2f prepares are collected nodes confirm it
+// and primary commits it after 2f+1 confirmations, silent primary is replaced by view change
+type PbftConsensus struct {
+	RoundBase
+	TotalNodes int
+	// Faulty max number of byzantine nodes cluster tolerates
+	Faulty  int
+	MsgChan chan Messager
+	// View current view of the round, primary of the view is selected by epoch and view
+	View uint64
+	// ViewEntropy entropy new-view of current view obliges its primary to pre-prepare
+	ViewEntropy string
+	// Entropy pre-prepared in current view
+	Entropy string
+	// Prepared certificate of entropy prepared in the highest view of this round, it's carried to the next views
+	Prepared *PreparedCert
+	// PrePrepares pre-prepare of primary, by view
+	PrePrepares map[uint64]PbftMessagePayload
+	// Prepares signed prepares of backups, by view and sender
+	Prepares map[uint64]map[string]PbftMessagePayload
+	// ViewChanges view-change messages, by view and sender
+	ViewChanges map[uint64]map[string]PbftMessagePayload
+	// Confirms confirm messages received in commit phase, self included
+	Confirms []PulseConfirmPayload
+}
+
+func NewPbftConsensus(totalNodes int, collectDuration int, exchangeDuration int, maxMessages int, rstTolerance int64) *PbftConsensus {
+	return &PbftConsensus{
+		NewRoundBase(collectDuration, exchangeDuration, rstTolerance),
+		totalNodes,
+		FaultTolerance(totalNodes),
+		make(chan Messager, maxMessages),
+		0,
+		"",
+		"",
+		nil,
+		make(map[uint64]PbftMessagePayload),
+		make(map[uint64]map[string]PbftMessagePayload),
+		make(map[uint64]map[string]PbftMessagePayload),
+		make([]PulseConfirmPayload, 0),
+	}
+}
+
+func (r *PbftConsensus) Name() string {
+	return PbftEngine
+}
+
+func (r *PbftConsensus) FlushData() {
+	r.View = 0
+	r.ViewEntropy = ""
+	r.Entropy = ""
+	r.Prepared = nil
+	r.PrePrepares = make(map[uint64]PbftMessagePayload)
+	r.Prepares = make(map[uint64]map[string]PbftMessagePayload)
+	r.ViewChanges = make(map[uint64]map[string]PbftMessagePayload)
+	r.Confirms = make([]PulseConfirmPayload, 0)
+	r.Guard.Flush()
+}
+
+// Primary gets primary of a view, nodes are ordered by id and primary rotates with epoch and view
+func (r *PbftConsensus) Primary(n Noder, view uint64) string {
+	ids := []string{n.GetID()}
+	for _, p := range n.GetPeers().Peers() {
+		ids = append(ids, p.ID)
+	}
+	sort.Strings(ids)
+	return ids[(n.GetPulseNumber()+view)%uint64(len(ids))]
+}
+
+// Round runs views until entropy is committed or collect and exchange durations are over,
+// view is changed if it doesn't commit within collect duration
+func (r *PbftConsensus) Round(rst int64, n Noder) {
+	r.FlushData()
+	r.SetRoundStartTime(rst)
+
+	roundDuration := time.Duration(r.GetCollectDuration()+r.GetExchangeDuration()) * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), roundDuration)
+	defer cancel()
+	viewTimeout := time.Duration(r.GetCollectDuration()) * time.Millisecond
+	timer := time.NewTimer(viewTimeout)
+	defer timer.Stop()
+
+	if r.Primary(n, 0) == n.GetID() {
+		r.PrePrepare(ctx, n, newEntropy())
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Infof("no commit in epoch #%d, view: %d, prepared: %t", n.GetPulseNumber(), r.View, r.Prepared != nil)
+			return
+		case <-timer.C:
+			r.log.Infof("view %d timed out, primary: %s", r.View, r.Primary(n, r.View))
+			r.StartViewChange(ctx, n, r.View+1)
+			timer.Reset(viewTimeout)
+		case msg := <-r.MsgChan:
+			if !r.accept(msg, n) {
+				continue
+			}
+			view := r.View
+			if r.handle(ctx, msg, n) {
+				return
+			}
+			if r.View != view {
+				timer.Reset(viewTimeout)
+			}
+		}
+	}
+}
+
+// Receive decodes pbft and confirm messages
+func (r *PbftConsensus) Receive(t MsgType, payload json.RawMessage) error {
+	switch t {
+	case PrePrepare, Prepare, ViewChange, NewView:
+		var pbftPayload = PbftMessagePayload{}
+		if err := json.Unmarshal(payload, &pbftPayload); err != nil {
+			return err
+		}
+		if pbftPayload.Type != t {
+			return ErrUnexpectedMsgType(pbftPayload.Type, r.Name())
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), pbftPayload.String())
+		r.MsgChan <- pbftPayload
+	case Confirm:
+		var confirmPayload = PulseConfirmPayload{}
+		if err := json.Unmarshal(payload, &confirmPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), confirmPayload.String())
+		r.MsgChan <- confirmPayload
+	default:
+		return ErrUnexpectedMsgType(t, r.Name())
+	}
+	return nil
+}
+
+// handle handles verified message, returns true when round is over
+func (r *PbftConsensus) handle(ctx context.Context, msg Messager, n Noder) bool {
+	if msg.GetType() == Confirm {
+		r.Confirms = append(r.Confirms, msg.(PulseConfirmPayload))
+		return r.CheckCommitted(n)
+	}
+	m := msg.(PbftMessagePayload)
+	switch m.Type {
+	case PrePrepare:
+		if m.View < r.View || m.From != r.Primary(n, m.View) {
+			r.log.Infof("skipping pre-prepare not from primary of view %d: %s", m.View, m)
+			return false
+		}
+		if _, ok := r.PrePrepares[m.View]; !ok {
+			r.PrePrepares[m.View] = m
+		}
+	case Prepare:
+		r.addPrepare(m)
+	case ViewChange:
+		if !r.VerifyPrepared(n, m) {
+			r.log.Errorf("invalid prepared certificate in view change: %s", m)
+			return false
+		}
+		r.addViewChange(m)
+		r.CheckNewView(ctx, n, m.View)
+	case NewView:
+		if !r.VerifyNewView(n, m) {
+			r.log.Errorf("invalid new view: %s", m)
+			return false
+		}
+		r.View = m.View
+		r.ViewEntropy = m.Entropy
+		r.Entropy = ""
+	}
+	r.Prepare(ctx, n)
+	return r.CheckPrepared(ctx, n)
+}
+
+// PrePrepare primary of current view broadcasts pre-prepare of entropy
+func (r *PbftConsensus) PrePrepare(ctx context.Context, n Noder, entropy string) {
+	pm := NewSignedPbftMessage(n, PrePrepare, r.GetRoundStartTime(), r.View, entropy, nil)
+	if err := n.GetClient().Broadcast(ctx, pm); err != nil {
+		r.log.Error(err)
+	}
+	r.PrePrepares[r.View] = pm.Payload
+	r.Entropy = entropy
+}
+
+// Prepare backup prepares entropy pre-prepared by primary of current view, in views after the first one
+// only entropy new-view obliges primary to pre-prepare is accepted
+func (r *PbftConsensus) Prepare(ctx context.Context, n Noder) {
+	pp, ok := r.PrePrepares[r.View]
+	if !ok || r.Entropy != "" || r.Primary(n, r.View) == n.GetID() {
+		return
+	}
+	if r.View != 0 && pp.Entropy != r.ViewEntropy {
+		r.log.Infof("skipping pre-prepare not agreed in new view %d: %s", r.View, pp)
+		return
+	}
+	r.Entropy = pp.Entropy
+	pm := NewSignedPbftMessage(n, Prepare, r.GetRoundStartTime(), r.View, pp.Entropy, nil)
+	if err := n.GetClient().Broadcast(ctx, pm); err != nil {
+		r.log.Error(err)
+	}
+	r.addPrepare(pm.Payload)
+}
+
+func (r *PbftConsensus) addPrepare(m PbftMessagePayload) {
+	if r.Prepares[m.View] == nil {
+		r.Prepares[m.View] = make(map[string]PbftMessagePayload)
+	}
+	r.Prepares[m.View][m.From] = m
+}
+
+func (r *PbftConsensus) addViewChange(m PbftMessagePayload) {
+	if r.ViewChanges[m.View] == nil {
+		r.ViewChanges[m.View] = make(map[string]PbftMessagePayload)
+	}
+	r.ViewChanges[m.View][m.From] = m
+}
+
+// CheckPrepared confirms pre-prepared entropy once 2f backups prepared it in current view,
+// prepares and pre-prepare make certificate of the view which is carried by view change
+func (r *PbftConsensus) CheckPrepared(ctx context.Context, n Noder) bool {
+	if r.Entropy == "" || (r.Prepared != nil && r.Prepared.PrePrepare.View == r.View) {
+		return false
+	}
+	primary := r.Primary(n, r.View)
+	prepares := make([]PbftMessagePayload, 0, len(r.Prepares[r.View]))
+	for from, p := range r.Prepares[r.View] {
+		if p.Entropy == r.Entropy && from != primary {
+			prepares = append(prepares, p)
+		}
+	}
+	if len(prepares) < 2*r.Faulty {
+		return false
+	}
+	sort.Slice(prepares, func(i, j int) bool {
+		return prepares[i].From < prepares[j].From
+	})
+	r.log.Infof("prepared in view %d: %s", r.View, r.Entropy)
+	r.Prepared = &PreparedCert{r.PrePrepares[r.View], prepares}
+	cm := NewSignedViewConfirmMessage(n, r.GetRoundStartTime(), r.View, r.Entropy)
+	if err := n.GetClient().Broadcast(ctx, cm); err != nil {
+		r.log.Error(err)
+	}
+	r.Confirms = append(r.Confirms, cm.Payload)
+	return r.CheckCommitted(n)
+}
+
+// CheckCommitted checks that 2f+1 nodes confirmed prepared entropy in the same view, every replica commits it to its ledger
+func (r *PbftConsensus) CheckCommitted(n Noder) bool {
+	if r.Prepared == nil {
+		return false
+	}
+	entropy := r.Prepared.PrePrepare.Entropy
+	byView := make(map[uint64][]*Confirmation)
+	for _, c := range r.Confirms {
+		if c.Entropy == entropy {
+			byView[c.View] = append(byView[c.View], c.GetPayload().(*Confirmation))
+		}
+	}
+	for view, confirmations := range byView {
+		if len(confirmations) < 2*r.Faulty+1 {
+			continue
+		}
+		r.log.Infof("entropy committed by %d nodes in view %d", len(confirmations), view)
+		b, err := NewBlockData(n.GetPulseNumber()+1, r.GetRoundStartTime(), []string{r.Primary(n, view)}, entropy, confirmations)
+		if err != nil {
+			r.log.Error(err)
+			return true
+		}
+		if err := SignBlock(n, &b); err != nil {
+			r.log.Errorf("failed to sign pulse: %s", err)
+			return true
+		}
+		r.log.Infof("committing primary pulse")
+		if err := n.Commit(context.Background(), b); err != nil {
+			r.log.Error(ErrStorageConnection(err))
+		}
+		return true
+	}
+	return false
+}
+
+// StartViewChange votes to replace primary with primary of the view, certificate of prepared entropy is sent along
+func (r *PbftConsensus) StartViewChange(ctx context.Context, n Noder, view uint64) {
+	if _, ok := r.ViewChanges[view][n.GetID()]; ok || view <= r.View {
+		return
+	}
+	r.log.Infof("changing view to %d", view)
+	vm := NewSignedViewChangeMessage(n, r.GetRoundStartTime(), view, r.Prepared)
+	if err := n.GetClient().Broadcast(ctx, vm); err != nil {
+		r.log.Error(err)
+	}
+	r.addViewChange(vm.Payload)
+	r.CheckNewView(ctx, n, view)
+}
+
+// CheckNewView joins view change supported by f+1 nodes, so at least one of them is correct,
+// when 2f+1 nodes support it primary of the view starts it with new-view message and pre-prepares its entropy
+func (r *PbftConsensus) CheckNewView(ctx context.Context, n Noder, view uint64) {
+	if view <= r.View {
+		return
+	}
+	vcs := r.ViewChanges[view]
+	if len(vcs) >= r.Faulty+1 {
+		r.StartViewChange(ctx, n, view)
+	}
+	if len(vcs) < 2*r.Faulty+1 || r.Primary(n, view) != n.GetID() {
+		return
+	}
+	proofs := make([]PbftMessagePayload, 0, len(vcs))
+	for _, vc := range vcs {
+		proofs = append(proofs, vc)
+	}
+	sort.Slice(proofs, func(i, j int) bool {
+		return proofs[i].From < proofs[j].From
+	})
+	entropy := NewViewEntropy(proofs)
+	if entropy == "" {
+		entropy = newEntropy()
+	}
+	r.log.Infof("starting view %d as primary", view)
+	nm := NewSignedPbftMessage(n, NewView, r.GetRoundStartTime(), view, entropy, proofs)
+	if err := n.GetClient().Broadcast(ctx, nm); err != nil {
+		r.log.Error(err)
+	}
+	r.View = view
+	r.ViewEntropy = entropy
+	r.PrePrepare(ctx, n, entropy)
+}
+
+// VerifyPrepared checks prepared certificate of view-change: pre-prepare is signed by primary of an earlier view,
+// prepares of the same view and entropy are signed by 2f distinct backups, all in round of the view-change,
+// view-change without certificate must not claim prepared entropy
+func (r *PbftConsensus) VerifyPrepared(n Noder, vc PbftMessagePayload) bool {
+	c := vc.Prepared
+	if c == nil {
+		return vc.Entropy == ""
+	}
+	pp := c.PrePrepare
+	if pp.Type != PrePrepare || pp.View >= vc.View || pp.Epoch != vc.Epoch || pp.Rst != vc.Rst || pp.Entropy != vc.Entropy {
+		return false
+	}
+	if pp.From != r.Primary(n, pp.View) || !n.VerifyMessageTrusted(pp) {
+		return false
+	}
+	backups := make(map[string]bool)
+	for _, p := range c.Prepares {
+		if p.Type != Prepare || p.View != pp.View || p.Epoch != pp.Epoch || p.Rst != pp.Rst || p.Entropy != pp.Entropy || p.From == pp.From {
+			return false
+		}
+		if !n.VerifyMessageTrusted(p) {
+			return false
+		}
+		backups[p.From] = true
+	}
+	return len(backups) >= 2*r.Faulty
+}
+
+// VerifyNewView checks new-view is sent by primary of the view, carries 2f+1 view-changes signed by distinct nodes
+// with valid prepared certificates and keeps entropy prepared in the highest of earlier views
+func (r *PbftConsensus) VerifyNewView(n Noder, m PbftMessagePayload) bool {
+	if m.View <= r.View || m.From != r.Primary(n, m.View) || m.Entropy == "" {
+		return false
+	}
+	senders := make(map[string]bool)
+	for _, vc := range m.ViewChanges {
+		if vc.Type != ViewChange || vc.View != m.View || vc.Epoch != m.Epoch || vc.Rst != m.Rst {
+			return false
+		}
+		if !n.VerifyMessageTrusted(vc) || !r.VerifyPrepared(n, vc) {
+			return false
+		}
+		senders[vc.From] = true
+	}
+	if len(senders) < 2*r.Faulty+1 {
+		return false
+	}
+	prepared := NewViewEntropy(m.ViewChanges)
+	return prepared == "" || prepared == m.Entropy
+}
+
+// NewViewEntropy gets entropy new primary must propose, the one certified prepared in the highest view,
+// empty if none of view-change senders prepared, certificates must be verified
+func NewViewEntropy(viewChanges []PbftMessagePayload) string {
+	var highest *PreparedCert
+	for _, vc := range viewChanges {
+		if vc.Prepared != nil && (highest == nil || vc.Prepared.PrePrepare.View > highest.PrePrepare.View) {
+			highest = vc.Prepared
+		}
+	}
+	if highest == nil {
+		return ""
+	}
+	return highest.PrePrepare.Entropy
+}
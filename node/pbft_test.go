@@ -0,0 +1,235 @@
+package node
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// pbftCluster prepares 4 nodes where self is primary of the view, in the epoch selected for it
+func pbftCluster(t *testing.T, view uint64) (*PbftConsensus, *Node, []*Node) {
+	cons := NewPbftConsensus(4, 200, 200, 100, 0)
+	self := signingNode(t)
+	self.store = newStubStorage()
+	self.client = &chanClient{make(chan interface{}, 10)}
+	peers := []*Node{signingNode(t), signingNode(t), signingNode(t)}
+	trust(t, self, peers...)
+	for cons.Primary(self, view) != self.ID {
+		self.Epoch++
+	}
+	for _, p := range peers {
+		p.Epoch = self.Epoch
+		trust(t, p, self)
+	}
+	cons.SetRoundStartTime(1000)
+	return cons, self, peers
+}
+
+// preparedCert certificate of entropy prepared in view, pre-prepared by primary of the view and prepared by two backups
+func preparedCert(cons *PbftConsensus, self *Node, nodes []*Node, view uint64, entropy string) *PreparedCert {
+	c := &PreparedCert{}
+	for _, n := range append([]*Node{self}, nodes...) {
+		if n.ID == cons.Primary(self, view) {
+			c.PrePrepare = NewSignedPbftMessage(n, PrePrepare, 1000, view, entropy, nil).Payload
+		} else if len(c.Prepares) < 2 {
+			c.Prepares = append(c.Prepares, NewSignedPbftMessage(n, Prepare, 1000, view, entropy, nil).Payload)
+		}
+	}
+	return c
+}
+
+func TestPbftPrimaryRotatesWithEpochAndView(t *testing.T) {
+	cons, self, _ := pbftCluster(t, 0)
+	require.Equal(t, self.ID, cons.Primary(self, 0))
+	require.NotEqual(t, self.ID, cons.Primary(self, 1))
+	next := cons.Primary(self, 1)
+	self.Epoch++
+	require.Equal(t, next, cons.Primary(self, 0))
+}
+
+func TestPbftPrimaryCommitsAfterPrepareAndConfirm(t *testing.T) {
+	cons, self, peers := pbftCluster(t, 0)
+	client := self.client.(*chanClient)
+	go cons.Round(1000, self)
+
+	pp := (<-client.sent).(*PbftMessage).Payload
+	require.Equal(t, PrePrepare, pp.Type)
+	cons.MsgChan <- NewSignedPbftMessage(peers[0], Prepare, 1000, 0, pp.Entropy, nil).Payload
+	// prepare of other entropy doesn't count
+	cons.MsgChan <- NewSignedPbftMessage(peers[1], Prepare, 1000, 0, "other", nil).Payload
+	cons.MsgChan <- NewSignedPbftMessage(peers[2], Prepare, 1000, 0, pp.Entropy, nil).Payload
+
+	cm := (<-client.sent).(*PulseConfirmMessage).Payload
+	require.Equal(t, pp.Entropy, cm.Entropy)
+	require.Equal(t, uint64(0), cm.View)
+	cons.MsgChan <- signedConfirm(peers[0], self.Epoch, 1000, pp.Entropy)
+	// confirm signed in another view doesn't count
+	cons.MsgChan <- NewSignedViewConfirmMessage(peers[1], 1000, 1, pp.Entropy).Payload
+	cons.MsgChan <- signedConfirm(peers[2], self.Epoch, 1000, pp.Entropy)
+
+	select {
+	case b := <-self.store.(*stubStorage).commits:
+		require.Len(t, b.Confirmations, 3)
+		require.Equal(t, self.ID, b.Confirmations[0].From)
+		require.NoError(t, NewBlock(b).VerifyConfirmations(self.publicKeys()))
+	case <-time.After(time.Second):
+		t.Fatal("pulse is not committed")
+	}
+}
+
+func TestPbftPrePrepareOnlyFromPrimary(t *testing.T) {
+	cons, self, peers := pbftCluster(t, 1)
+	ctx := context.Background()
+	primary := cons.Primary(self, 0)
+	var backup *Node
+	for _, p := range peers {
+		if p.ID != primary {
+			backup = p
+		}
+	}
+
+	cons.handle(ctx, NewSignedPbftMessage(backup, PrePrepare, 1000, 0, "fake", nil).Payload, self)
+	require.Empty(t, cons.Entropy)
+	require.Empty(t, cons.PrePrepares)
+	require.Empty(t, self.client.(*chanClient).sent)
+}
+
+func TestPbftViewChangeReplacesSilentPrimary(t *testing.T) {
+	cons, self, peers := pbftCluster(t, 1)
+	ctx := context.Background()
+	client := self.client.(*chanClient)
+
+	cons.StartViewChange(ctx, self, 1)
+	require.Equal(t, ViewChange, (<-client.sent).(*PbftMessage).Payload.Type)
+	// one of the peers has prepared entropy in view 0
+	prepared := preparedCert(cons, self, peers, 0, "prepared")
+	cons.handle(ctx, NewSignedViewChangeMessage(peers[0], 1000, 1, prepared).Payload, self)
+	require.Empty(t, client.sent)
+	cons.handle(ctx, NewSignedViewChangeMessage(peers[1], 1000, 1, nil).Payload, self)
+
+	nv := (<-client.sent).(*PbftMessage).Payload
+	require.Equal(t, NewView, nv.Type)
+	require.Equal(t, uint64(1), cons.View)
+	require.Equal(t, "prepared", nv.Entropy)
+	require.Len(t, nv.ViewChanges, 3)
+	pp := (<-client.sent).(*PbftMessage).Payload
+	require.Equal(t, PrePrepare, pp.Type)
+	require.Equal(t, uint64(1), pp.View)
+	require.Equal(t, "prepared", pp.Entropy)
+
+	backupCons := NewPbftConsensus(4, 200, 200, 100, 0)
+	backup := peers[2]
+	trust(t, backup, peers[0], peers[1])
+	require.True(t, backupCons.VerifyNewView(backup, nv))
+
+	forged := nv
+	forged.Entropy = "other"
+	require.False(t, backupCons.VerifyNewView(backup, forged))
+	forged = nv
+	forged.ViewChanges = nv.ViewChanges[:2]
+	require.False(t, backupCons.VerifyNewView(backup, forged))
+	forged = nv
+	forged.ViewChanges = append([]PbftMessagePayload{}, nv.ViewChanges...)
+	forged.ViewChanges[0].View = 2
+	require.False(t, backupCons.VerifyNewView(backup, forged))
+
+	// backup prepares pre-prepare of new primary only with entropy agreed in new-view
+	backup.Epoch = self.Epoch
+	backup.client = &chanClient{make(chan interface{}, 10)}
+	backupCons.SetRoundStartTime(1000)
+	backupCons.handle(ctx, NewSignedPbftMessage(self, PrePrepare, 1000, 1, "other", nil).Payload, backup)
+	backupCons.handle(ctx, nv, backup)
+	require.Equal(t, uint64(1), backupCons.View)
+	require.Empty(t, backupCons.Entropy)
+	backupCons = NewPbftConsensus(4, 200, 200, 100, 0)
+	backupCons.SetRoundStartTime(1000)
+	backupCons.handle(ctx, nv, backup)
+	backupCons.handle(ctx, pp, backup)
+	require.Equal(t, "prepared", backupCons.Entropy)
+	prepare := (<-backup.client.(*chanClient).sent).(*PbftMessage).Payload
+	require.Equal(t, Prepare, prepare.Type)
+	require.Equal(t, "prepared", prepare.Entropy)
+}
+
+func TestPbftViewChangeKeepsHighestCertifiedEntropy(t *testing.T) {
+	cons, self, peers := pbftCluster(t, 2)
+	ctx := context.Background()
+	client := self.client.(*chanClient)
+
+	stale := NewSignedViewChangeMessage(peers[0], 1000, 2, preparedCert(cons, self, peers, 0, "stale")).Payload
+	latest := NewSignedViewChangeMessage(peers[2], 1000, 2, preparedCert(cons, self, peers, 1, "latest")).Payload
+	cons.handle(ctx, stale, self)
+	cons.handle(ctx, latest, self)
+	require.Equal(t, ViewChange, (<-client.sent).(*PbftMessage).Payload.Type)
+	nv := (<-client.sent).(*PbftMessage).Payload
+	require.Equal(t, NewView, nv.Type)
+	require.Equal(t, "latest", nv.Entropy)
+
+	// majority of view-changes prepared stale entropy in view 0, one prepared another entropy in view 1
+	backupCons := NewPbftConsensus(4, 200, 200, 100, 0)
+	backup := peers[2]
+	trust(t, backup, peers[0], peers[1])
+	vcs := []PbftMessagePayload{
+		stale,
+		NewSignedViewChangeMessage(peers[1], 1000, 2, preparedCert(cons, self, peers, 0, "stale")).Payload,
+		latest,
+	}
+	require.False(t, backupCons.VerifyNewView(backup, NewSignedPbftMessage(self, NewView, 1000, 2, "stale", vcs).Payload))
+	require.True(t, backupCons.VerifyNewView(backup, NewSignedPbftMessage(self, NewView, 1000, 2, "latest", vcs).Payload))
+}
+
+func TestPbftViewChangeRejectsForgedCertificate(t *testing.T) {
+	cons, self, peers := pbftCluster(t, 1)
+	ctx := context.Background()
+
+	// entropy is claimed prepared without certificate
+	claimed := NewSignedPbftMessage(peers[0], ViewChange, 1000, 1, "claimed", nil).Payload
+	require.False(t, cons.VerifyPrepared(self, claimed))
+	cons.handle(ctx, claimed, self)
+	require.Empty(t, cons.ViewChanges[1])
+
+	// certificate lacks prepares of 2f backups
+	short := preparedCert(cons, self, peers, 0, "short")
+	short.Prepares = short.Prepares[:1]
+	require.False(t, cons.VerifyPrepared(self, NewSignedViewChangeMessage(peers[0], 1000, 1, short).Payload))
+
+	// the same backup prepared twice
+	duplicate := preparedCert(cons, self, peers, 0, "duplicate")
+	duplicate.Prepares[1] = duplicate.Prepares[0]
+	require.False(t, cons.VerifyPrepared(self, NewSignedViewChangeMessage(peers[0], 1000, 1, duplicate).Payload))
+
+	// pre-prepare isn't signed by primary of its view
+	notPrimary := preparedCert(cons, self, peers, 1, "not primary")
+	notPrimary.PrePrepare.View = 0
+	require.False(t, cons.VerifyPrepared(self, NewSignedViewChangeMessage(peers[0], 1000, 1, notPrimary).Payload))
+
+	// prepares are of another entropy
+	mismatch := preparedCert(cons, self, peers, 0, "a")
+	mismatch.Prepares[0] = preparedCert(cons, self, peers, 0, "b").Prepares[0]
+	require.False(t, cons.VerifyPrepared(self, NewSignedViewChangeMessage(peers[0], 1000, 1, mismatch).Payload))
+
+	require.True(t, cons.VerifyPrepared(self, NewSignedViewChangeMessage(peers[0], 1000, 1, preparedCert(cons, self, peers, 0, "valid")).Payload))
+}
+
+func TestPbftViewChangeJoinedByFaultyPlusOne(t *testing.T) {
+	cons, self, peers := pbftCluster(t, 0)
+	ctx := context.Background()
+	client := self.client.(*chanClient)
+
+	cons.handle(ctx, NewSignedViewChangeMessage(peers[0], 1000, 1, nil).Payload, self)
+	require.Empty(t, client.sent)
+	cons.handle(ctx, NewSignedViewChangeMessage(peers[1], 1000, 1, nil).Payload, self)
+	vc := (<-client.sent).(*PbftMessage).Payload
+	require.Equal(t, ViewChange, vc.Type)
+	require.Equal(t, uint64(1), vc.View)
+}
+
+func TestNewViewEntropy(t *testing.T) {
+	cert := func(view uint64, entropy string) *PreparedCert {
+		return &PreparedCert{PrePrepare: PbftMessagePayload{View: view, Entropy: entropy}}
+	}
+	require.Equal(t, "", NewViewEntropy([]PbftMessagePayload{{}, {}}))
+	require.Equal(t, "a", NewViewEntropy([]PbftMessagePayload{{}, {Prepared: cert(0, "a")}}))
+	require.Equal(t, "c", NewViewEntropy([]PbftMessagePayload{{Prepared: cert(0, "b")}, {Prepared: cert(1, "c")}, {Prepared: cert(0, "b")}}))
+}
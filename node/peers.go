@@ -0,0 +1,122 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"github.com/mr-tron/base58"
+	"sort"
+	"sync"
+)
+
+const (
+	nodeIDPrefix = "node-"
+	// nodeIDBytes how many bytes of public key fingerprint are used in node id
+	nodeIDBytes = 16
+)
+
+// NodeID derives node id from sha256 fingerprint of PKIX encoded public key
+func NodeID(pub *ecdsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	fp := sha256.Sum256(der)
+	return nodeIDPrefix + base58.Encode(fp[:nodeIDBytes]), nil
+}
+
+// PeerInfo is a known cluster member, id is bound to public key
+type PeerInfo struct {
+	ID        string
+	Addr      string
+	PublicKey *ecdsa.PublicKey
+}
+
+// PeerRegistry maps node ids to addresses and public keys of cluster members
+type PeerRegistry struct {
+	mu       sync.RWMutex
+	peers    map[string]*PeerInfo
+	excluded map[string]bool
+}
+
+func NewPeerRegistry() *PeerRegistry {
+	return &PeerRegistry{
+		peers:    make(map[string]*PeerInfo),
+		excluded: make(map[string]bool),
+	}
+}
+
+// LoadPeerRegistry loads peer public keys from config, if peer id is configured it must match key fingerprint
+func LoadPeerRegistry(peers []Peer) (*PeerRegistry, error) {
+	r := NewPeerRegistry()
+	for _, p := range peers {
+		info, err := r.Add(p.Addr, LoadPublicKey(p.PubKeyDir))
+		if err != nil {
+			return nil, err
+		}
+		if p.ID != "" && p.ID != info.ID {
+			return nil, ErrPeerIDMismatch(p.ID, info.ID)
+		}
+	}
+	return r, nil
+}
+
+// Add registers peer by address and public key, returns peer with derived id
+func (r *PeerRegistry) Add(addr string, pub *ecdsa.PublicKey) (*PeerInfo, error) {
+	id, err := NodeID(pub)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.peers[id]; ok {
+		return nil, fmt.Errorf("duplicate peer key: %s", id)
+	}
+	info := &PeerInfo{id, addr, pub}
+	r.peers[id] = info
+	return info, nil
+}
+
+// Get gets peer by node id
+func (r *PeerRegistry) Get(id string) (*PeerInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.peers[id]
+	return p, ok
+}
+
+// Peers gets all peers sorted by id
+func (r *PeerRegistry) Peers() []*PeerInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	peers := make([]*PeerInfo, 0, len(r.peers))
+	for _, p := range r.peers {
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].ID < peers[j].ID
+	})
+	return peers
+}
+
+// Len number of registered peers
+func (r *PeerRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.peers)
+}
+
+// Exclude marks peer as excluded from rounds, peer stays registered so its signatures can still be checked
+func (r *PeerRegistry) Exclude(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.excluded[id] = true
+}
+
+// IsExcluded checks if peer is excluded from rounds
+func (r *PeerRegistry) IsExcluded(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.excluded[id]
+}
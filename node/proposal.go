@@ -3,8 +3,11 @@ package node
 import "fmt"
 
 type PulseProposal struct {
-	From    string
+	From string
+	// Entropy commitment to proposer entropy, entropy itself is revealed in vector
 	Entropy string
+	// Signature proposer signature of collect message, so proposal relayed in vectors can be checked
+	Signature []byte
 }
 
 func (m *PulseProposal) String() string {
@@ -13,7 +16,7 @@ func (m *PulseProposal) String() string {
 
 func NewPulseProposal(from string, data string) *PulseProposal {
 	return &PulseProposal{
-		from,
-		data,
+		From:    from,
+		Entropy: data,
 	}
 }
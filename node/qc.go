@@ -0,0 +1,66 @@
+package node
+
+import (
+	"fmt"
+	pb "rounds/ledger/pb"
+)
+
+// QuorumCert certifies that 2f+1 nodes voted for entropy proposed at height,
+// votes are signatures of confirm messages sent in epoch and round
+type QuorumCert struct {
+	Height  uint64
+	Epoch   uint64
+	Rst     int64
+	Entropy string
+	Votes   []*Confirmation
+	// Proposer node proposed certified entropy
+	Proposer string
+}
+
+// Verify checks that certificate has votes of quorum distinct known nodes signed for its entropy in one view of its height
+func (q *QuorumCert) Verify(n Noder, quorum int) bool {
+	voters := make(map[string]bool)
+	for _, v := range q.Votes {
+		if hotStuffViewHeight(v.View) != q.Height || v.View != q.Votes[0].View {
+			return false
+		}
+		vote := PulseConfirmPayload{v.Signature, q.Rst, q.Epoch, v.From, q.Entropy, v.Share, v.View}
+		if !n.VerifyMessageTrusted(vote) {
+			return false
+		}
+		voters[v.From] = true
+	}
+	return len(voters) >= quorum
+}
+
+func (q *QuorumCert) String() string {
+	return fmt.Sprintf("[height: %d, epoch: %d, rst: %d, entropy: %s, votes: %d]", q.Height, q.Epoch, q.Rst, q.Entropy, len(q.Votes))
+}
+
+func (q *QuorumCert) ToPb() *pb.QuorumCert {
+	if q == nil {
+		return nil
+	}
+	return &pb.QuorumCert{
+		Height:   q.Height,
+		Epoch:    q.Epoch,
+		Rst:      q.Rst,
+		Entropy:  q.Entropy,
+		Votes:    ConfirmationsToPb(q.Votes),
+		Proposer: q.Proposer,
+	}
+}
+
+func QuorumCertFromPb(q *pb.QuorumCert) *QuorumCert {
+	if q == nil {
+		return nil
+	}
+	return &QuorumCert{
+		q.GetHeight(),
+		q.GetEpoch(),
+		q.GetRst(),
+		q.GetEntropy(),
+		ConfirmationsFromPb(q.GetVotes()),
+		q.GetProposer(),
+	}
+}
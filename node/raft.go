@@ -0,0 +1,343 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+const (
+	RaftEngine = "raft"
+)
+
+func init() {
+	RegisterEngine(RaftEngine, func(c *Config) Consensus {
+		return NewRaftConsensus(
+			c.ClusterSize(),
+			c.Node.Rounds.Collect.Duration,
+			c.Node.Rounds.Exchange.Duration,
+			c.Node.Rounds.Collect.MaxMessages+c.Node.Rounds.Exchange.MaxMessages,
+			c.Node.Rounds.RstTolerance,
+		)
+	})
+}
+
+// RaftMessagePayload election and replication messages of raft engine, epoch is used as raft term
+type RaftMessagePayload struct {
+	Signature []byte
+	Type      MsgType `json:"type"`
+	Rst       int64   `json:"rst"`
+	Epoch     uint64  `json:"epoch"`
+	From      string  `json:"from"`
+	// Candidate node vote is granted to
+	Candidate string `json:"candidate"`
+	// Entropy leader appends for epoch
+	Entropy string `json:"entropy"`
+}
+
+type RaftMessage struct {
+	Header
+	Payload RaftMessagePayload `json:"payload"`
+}
+
+// NewSignedRaftMessage creates raft message signed by node
+func NewSignedRaftMessage(n Noder, t MsgType, rst int64, candidate string, entropy string) *RaftMessage {
+	m := &RaftMessage{
+		Header: Header{
+			Type: t,
+		},
+		Payload: RaftMessagePayload{
+			Type:      t,
+			Rst:       rst,
+			Epoch:     n.GetPulseNumber(),
+			From:      n.GetID(),
+			Candidate: candidate,
+			Entropy:   entropy,
+		},
+	}
+	m.Payload.Signature = n.Sign(m.Payload.SigningData())
+	return m
+}
+
+func (m RaftMessagePayload) GetType() MsgType {
+	return m.Type
+}
+
+func (m RaftMessagePayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m RaftMessagePayload) GetRst() int64 {
+	return m.Rst
+}
+
+func (m RaftMessagePayload) GetFrom() string {
+	return m.From
+}
+
+func (m RaftMessagePayload) GetPayload() interface{} {
+	return m.Entropy
+}
+
+func (m RaftMessagePayload) GetSignature() []byte {
+	return m.Signature
+}
+
+// SigningData encodes message header, candidate and entropy
+func (m RaftMessagePayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.String(m.Candidate)
+	e.String(m.Entropy)
+	return e.Bytes()
+}
+
+func (m RaftMessagePayload) String() string {
+	return fmt.Sprintf(
+		"[ type: %s, rst: %d, from: %s, candidate: %s, entropy: %s ]",
+		m.Type,
+		m.Rst,
+		m.From,
+		m.Candidate,
+		m.Entropy,
+	)
+}
+
+// RaftConsensus crash fault tolerant leader based consensus in the style of raft,
+// leader is elected every epoch with randomized election timeouts during collect duration,
+// then it appends its entropy and every node commits it after majority, but at least 2f+1 nodes, confirm it during exchange duration
+type RaftConsensus struct {
+	RoundBase
+	TotalNodes int
+	MsgChan    chan Messager
+	// VotedFor candidate node voted for in current epoch
+	VotedFor string
+	// Votes granted to node in current epoch, if it's a candidate
+	Votes map[string]bool
+	// Leader elected for current epoch
+	Leader string
+	// Entropy appended by leader in current epoch
+	Entropy string
+	// Confirmations of appended entropy, own included
+	Confirmations []*Confirmation
+	// PendingConfirms confirm messages received before election ended
+	PendingConfirms []Messager
+}
+
+func NewRaftConsensus(totalNodes int, collectDuration int, exchangeDuration int, maxMessages int, rstTolerance int64) *RaftConsensus {
+	return &RaftConsensus{
+		NewRoundBase(collectDuration, exchangeDuration, rstTolerance),
+		totalNodes,
+		make(chan Messager, maxMessages),
+		"",
+		make(map[string]bool),
+		"",
+		"",
+		make([]*Confirmation, 0),
+		make([]Messager, 0),
+	}
+}
+
+func (r *RaftConsensus) Name() string {
+	return RaftEngine
+}
+
+func (r *RaftConsensus) FlushData() {
+	r.VotedFor = ""
+	r.Votes = make(map[string]bool)
+	r.Leader = ""
+	r.Entropy = ""
+	r.Confirmations = make([]*Confirmation, 0)
+	r.PendingConfirms = make([]Messager, 0)
+	r.Guard.Flush()
+}
+
+// Majority more than half of nodes, cluster tolerates crash of the rest
+func (r *RaftConsensus) Majority() int {
+	return r.TotalNodes/2 + 1
+}
+
+// ConfirmQuorum confirmations appended entropy needs to be committed, majority but at least 2f+1,
+// so raft blocks pass the same confirmation checks as blocks of other engines
+func (r *RaftConsensus) ConfirmQuorum() int {
+	if quorum := ConfirmationQuorum(r.TotalNodes); quorum > r.Majority() {
+		return quorum
+	}
+	return r.Majority()
+}
+
+// Round elects leader during collect duration and replicates leader entropy during exchange duration
+func (r *RaftConsensus) Round(rst int64, n Noder) {
+	r.FlushData()
+	r.SetRoundStartTime(rst)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.GetCollectDuration())*time.Millisecond)
+	defer cancel()
+	if !r.Elect(ctx, n) {
+		r.log.Infof("no leader elected for epoch #%d", n.GetPulseNumber())
+		return
+	}
+	r.log.Infof("leader for epoch #%d: %s, me: %s", n.GetPulseNumber(), r.Leader, n.GetID())
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Duration(r.GetExchangeDuration())*time.Millisecond)
+	defer cancel2()
+	r.Replicate(ctx2, n)
+}
+
+// Receive decodes election, append and confirm messages
+func (r *RaftConsensus) Receive(t MsgType, payload json.RawMessage) error {
+	switch t {
+	case RequestVote, Vote, Append:
+		var raftPayload = RaftMessagePayload{}
+		if err := json.Unmarshal(payload, &raftPayload); err != nil {
+			return err
+		}
+		if raftPayload.Type != t {
+			return ErrUnexpectedMsgType(raftPayload.Type, r.Name())
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), raftPayload.String())
+		r.MsgChan <- raftPayload
+	case Confirm:
+		var confirmPayload = PulseConfirmPayload{}
+		if err := json.Unmarshal(payload, &confirmPayload); err != nil {
+			return err
+		}
+		r.log.Debugf("parsed msg: %s:%s", t.String(), confirmPayload.String())
+		r.MsgChan <- confirmPayload
+	default:
+		return ErrUnexpectedMsgType(t, r.Name())
+	}
+	return nil
+}
+
+// electionTimeout random timeout in the middle half of collect duration, so usually one node becomes candidate first
+func (r *RaftConsensus) electionTimeout() time.Duration {
+	quarter := r.GetCollectDuration() / 4
+	if quarter == 0 {
+		return 0
+	}
+	return time.Duration(quarter+rand.Intn(2*quarter)) * time.Millisecond
+}
+
+// Elect waits for election timeout and becomes candidate unless it has already voted,
+// election ends when node becomes leader or receives append from a leader,
+// confirms of nodes which got append earlier are kept for replication
+func (r *RaftConsensus) Elect(ctx context.Context, n Noder) bool {
+	timer := time.NewTimer(r.electionTimeout())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			if r.VotedFor == "" {
+				r.RequestVotes(ctx, n)
+			}
+		case msg := <-r.MsgChan:
+			if msg.GetType() == Confirm {
+				r.PendingConfirms = append(r.PendingConfirms, msg)
+				continue
+			}
+			if !r.accept(msg, n) {
+				continue
+			}
+			if r.handleElection(ctx, msg, n) {
+				return true
+			}
+		}
+	}
+}
+
+// handleElection handles election message, returns true when leader is known
+func (r *RaftConsensus) handleElection(ctx context.Context, msg Messager, n Noder) bool {
+	switch msg.GetType() {
+	case RequestVote:
+		if r.VotedFor != "" {
+			r.log.Debugf("already voted for %s, vote request from %s ignored", r.VotedFor, msg.GetFrom())
+			return false
+		}
+		r.VotedFor = msg.GetFrom()
+		vm := NewSignedRaftMessage(n, Vote, r.GetRoundStartTime(), msg.GetFrom(), "")
+		if err := n.GetClient().Broadcast(ctx, vm); err != nil {
+			r.log.Error(err)
+		}
+	case Vote:
+		if msg.(RaftMessagePayload).Candidate != n.GetID() || r.VotedFor != n.GetID() {
+			return false
+		}
+		r.Votes[msg.GetFrom()] = true
+		if len(r.Votes) >= r.Majority() {
+			r.Leader = n.GetID()
+			return true
+		}
+	case Append:
+		r.Leader = msg.GetFrom()
+		r.Entropy = msg.(RaftMessagePayload).Entropy
+		return true
+	}
+	return false
+}
+
+// RequestVotes votes for self and asks peers for votes
+func (r *RaftConsensus) RequestVotes(ctx context.Context, n Noder) {
+	r.log.Infof("election timeout, requesting votes for epoch #%d", n.GetPulseNumber())
+	r.VotedFor = n.GetID()
+	r.Votes[n.GetID()] = true
+	vm := NewSignedRaftMessage(n, RequestVote, r.GetRoundStartTime(), n.GetID(), "")
+	if err := n.GetClient().Broadcast(ctx, vm); err != nil {
+		r.log.Error(err)
+	}
+}
+
+// Replicate leader appends new entropy, every node confirms appended entropy
+// and commits it to its ledger when confirm quorum confirms it, confirms received during election are counted first
+func (r *RaftConsensus) Replicate(ctx context.Context, n Noder) {
+	if r.Leader == n.GetID() {
+		r.Entropy = newEntropy()
+		am := NewSignedRaftMessage(n, Append, r.GetRoundStartTime(), "", r.Entropy)
+		if err := n.GetClient().Broadcast(ctx, am); err != nil {
+			r.log.Error(err)
+		}
+	}
+	cm := NewSignedConfirmMessage(n, r.GetRoundStartTime(), r.Entropy)
+	if err := n.GetClient().Broadcast(ctx, cm); err != nil {
+		r.log.Error(err)
+	}
+	r.Confirmations = append(r.Confirmations, cm.Payload.GetPayload().(*Confirmation))
+	pending := r.PendingConfirms
+	r.PendingConfirms = make([]Messager, 0)
+	for len(r.Confirmations) < r.ConfirmQuorum() {
+		var msg Messager
+		if len(pending) > 0 {
+			msg, pending = pending[0], pending[1:]
+		} else {
+			select {
+			case <-ctx.Done():
+				r.log.Infof("append of epoch #%d is not confirmed: %d/%d", n.GetPulseNumber(), len(r.Confirmations), r.ConfirmQuorum())
+				return
+			case msg = <-r.MsgChan:
+			}
+		}
+		if msg.GetType() != Confirm || !r.accept(msg, n) {
+			continue
+		}
+		if msg.(PulseConfirmPayload).Entropy != r.Entropy {
+			continue
+		}
+		r.Confirmations = append(r.Confirmations, msg.GetPayload().(*Confirmation))
+	}
+	b, err := NewBlockData(n.GetPulseNumber()+1, r.GetRoundStartTime(), []string{r.Leader}, r.Entropy, r.Confirmations)
+	if err != nil {
+		r.log.Error(err)
+		return
+	}
+	if err := SignBlock(n, &b); err != nil {
+		r.log.Errorf("failed to sign pulse: %s", err)
+		return
+	}
+	r.log.Infof("committing leader pulse")
+	if err := n.Commit(context.Background(), b); err != nil {
+		r.log.Error(ErrStorageConnection(err))
+	}
+}
@@ -0,0 +1,145 @@
+package node
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type chanClient struct {
+	sent chan interface{}
+}
+
+func (m *chanClient) Broadcast(ctx context.Context, msg interface{}) error {
+	m.sent <- msg
+	return nil
+}
+
+func (m *chanClient) Send(ctx context.Context, addr string, msg interface{}) error {
+	m.sent <- msg
+	return nil
+}
+
+func raftCluster(t *testing.T) (*RaftConsensus, *Node, []*Node) {
+	cons := NewRaftConsensus(4, 200, 200, 100, 0)
+	self := signingNode(t)
+	self.store = newStubStorage()
+	self.client = &stubClient{}
+	peers := []*Node{signingNode(t), signingNode(t), signingNode(t)}
+	trust(t, self, peers...)
+	cons.SetRoundStartTime(1000)
+	return cons, self, peers
+}
+
+func TestEngineRegistry(t *testing.T) {
+	require.Contains(t, Engines(), PulseEngine)
+	require.Contains(t, Engines(), RaftEngine)
+	require.Contains(t, Engines(), PbftEngine)
+	require.Contains(t, Engines(), HotStuffEngine)
+
+	c := &Config{}
+	c.Node.Peers = make([]Peer, 3)
+	cons, err := NewConsensus(c)
+	require.NoError(t, err)
+	require.Equal(t, PulseEngine, cons.Name())
+
+	c.Node.Consensus = RaftEngine
+	cons, err = NewConsensus(c)
+	require.NoError(t, err)
+	require.Equal(t, RaftEngine, cons.Name())
+
+	c.Node.Consensus = "paxos"
+	_, err = NewConsensus(c)
+	require.Error(t, err)
+}
+
+func TestRaftElectedByMajority(t *testing.T) {
+	cons, self, peers := raftCluster(t)
+	require.Equal(t, 3, cons.Majority())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cons.RequestVotes(ctx, self)
+	// vote for other candidate doesn't count
+	cons.MsgChan <- NewSignedRaftMessage(peers[0], Vote, 1000, peers[1].ID, "").Payload
+	cons.MsgChan <- NewSignedRaftMessage(peers[1], Vote, 1000, self.ID, "").Payload
+	cons.MsgChan <- NewSignedRaftMessage(peers[2], Vote, 1000, self.ID, "").Payload
+
+	require.True(t, cons.Elect(ctx, self))
+	require.Equal(t, self.ID, cons.Leader)
+	sent := self.client.(*stubClient).sent
+	require.Equal(t, RequestVote, sent[0].(*RaftMessage).Payload.Type)
+}
+
+func TestRaftVotesOncePerEpoch(t *testing.T) {
+	cons, self, peers := raftCluster(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cons.MsgChan <- NewSignedRaftMessage(peers[0], RequestVote, 1000, peers[0].ID, "").Payload
+	cons.MsgChan <- NewSignedRaftMessage(peers[1], RequestVote, 1000, peers[1].ID, "").Payload
+
+	require.False(t, cons.Elect(ctx, self))
+	require.Equal(t, peers[0].ID, cons.VotedFor)
+	sent := self.client.(*stubClient).sent
+	require.Len(t, sent, 1)
+	require.Equal(t, peers[0].ID, sent[0].(*RaftMessage).Payload.Candidate)
+}
+
+func TestRaftFollowerConfirmsLeaderAppend(t *testing.T) {
+	cons, self, peers := raftCluster(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cons.MsgChan <- NewSignedRaftMessage(peers[0], Append, 1000, "", "leader-entropy").Payload
+	require.True(t, cons.Elect(ctx, self))
+	require.Equal(t, peers[0].ID, cons.Leader)
+
+	cons.Replicate(ctx, self)
+	sent := self.client.(*stubClient).sent
+	require.Len(t, sent, 1)
+	require.Equal(t, "leader-entropy", sent[0].(*PulseConfirmMessage).Payload.Entropy)
+}
+
+func TestRaftLeaderCommitsAfterMajority(t *testing.T) {
+	cons, self, peers := raftCluster(t)
+	client := &chanClient{make(chan interface{}, 10)}
+	self.client = client
+	cons.Leader = self.ID
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	go cons.Replicate(ctx, self)
+
+	entropy := (<-client.sent).(*RaftMessage).Payload.Entropy
+	// confirmation of other entropy doesn't count
+	cons.MsgChan <- signedConfirm(peers[0], 0, 1000, "other")
+	cons.MsgChan <- signedConfirm(peers[1], 0, 1000, entropy)
+	cons.MsgChan <- signedConfirm(peers[2], 0, 1000, entropy)
+
+	b := <-self.store.(*stubStorage).commits
+	require.Len(t, b.Confirmations, 3)
+	require.Equal(t, self.ID, b.Confirmations[0].From)
+	require.Equal(t, peers[1].ID, b.Confirmations[1].From)
+	require.Equal(t, peers[2].ID, b.Confirmations[2].From)
+}
+
+func TestRaftCountsConfirmsReceivedDuringElection(t *testing.T) {
+	cons, self, peers := raftCluster(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// followers which got append first confirm it before it reaches self
+	cons.MsgChan <- signedConfirm(peers[1], 0, 1000, "leader-entropy")
+	cons.MsgChan <- signedConfirm(peers[2], 0, 1000, "leader-entropy")
+	cons.MsgChan <- NewSignedRaftMessage(peers[0], Append, 1000, "", "leader-entropy").Payload
+	require.True(t, cons.Elect(ctx, self))
+	require.Len(t, cons.PendingConfirms, 2)
+
+	cons.Replicate(ctx, self)
+	b := <-self.store.(*stubStorage).commits
+	require.Len(t, b.Confirmations, 3)
+	require.Equal(t, peers[1].ID, b.Confirmations[1].From)
+	require.Equal(t, peers[2].ID, b.Confirmations[2].From)
+}
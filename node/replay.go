@@ -0,0 +1,97 @@
+package node
+
+import "bytes"
+
+// msgKey identifies single message of a sender in a round
+type msgKey struct {
+	From  string
+	Epoch uint64
+	Rst   int64
+	Type  MsgType
+	View  uint64
+}
+
+// viewMessager message sent once per view of a round, so it's not a duplicate of the same message in another view
+type viewMessager interface {
+	GetView() uint64
+}
+
+func msgView(msg Messager) uint64 {
+	if vm, ok := msg.(viewMessager); ok {
+		return vm.GetView()
+	}
+	return 0
+}
+
+const (
+	RejectSignature    = "signature"
+	RejectStaleRound   = "stale_round"
+	RejectFutureRound  = "future_round"
+	RejectStaleEpoch   = "stale_epoch"
+	RejectFutureEpoch  = "future_epoch"
+	RejectDuplicate    = "duplicate"
+	RejectEquivocation = "equivocation"
+	RejectExcluded     = "excluded"
+	RejectVrf          = "vrf"
+)
+
+// ReplayGuard rejects messages from other rounds and repeated messages of a sender in current round
+type ReplayGuard struct {
+	// RstTolerance how many seconds message round start time may differ from local one
+	RstTolerance int64
+	seen         map[msgKey]SignedMessage
+	rejected     map[string]int
+}
+
+func NewReplayGuard(rstTolerance int64) *ReplayGuard {
+	return &ReplayGuard{
+		rstTolerance,
+		make(map[msgKey]SignedMessage),
+		make(map[string]int),
+	}
+}
+
+// CheckRound checks that message belongs to current epoch and round, returns rejection reason
+func (g *ReplayGuard) CheckRound(msg Messager, epoch uint64, rst int64) string {
+	switch {
+	case msg.GetEpoch() < epoch:
+		return RejectStaleEpoch
+	case msg.GetEpoch() > epoch:
+		return RejectFutureEpoch
+	case msg.GetRst() < rst-g.RstTolerance:
+		return RejectStaleRound
+	case msg.GetRst() > rst+g.RstTolerance:
+		return RejectFutureRound
+	}
+	return ""
+}
+
+// Mark remembers message of a sender for current round, returns rejection reason if sender already sent message
+// of that type, if the messages differ returns evidence of equivocation,
+// rst is local round start time, so sender can't get two messages counted by shifting rst within tolerance
+func (g *ReplayGuard) Mark(msg Messager, epoch uint64, rst int64) (string, *Evidence) {
+	k := msgKey{msg.GetFrom(), epoch, rst, msg.GetType(), msgView(msg)}
+	if first, ok := g.seen[k]; ok {
+		if bytes.Equal(first.Data, msg.SigningData()) {
+			return RejectDuplicate, nil
+		}
+		return RejectEquivocation, NewEvidence(first, msg)
+	}
+	g.seen[k] = NewSignedMessage(msg)
+	return "", nil
+}
+
+// Reject counts rejected message by reason
+func (g *ReplayGuard) Reject(reason string) {
+	g.rejected[reason]++
+}
+
+// Rejected gets rejected messages count by reason since guard creation
+func (g *ReplayGuard) Rejected() map[string]int {
+	return g.rejected
+}
+
+// Flush forgets seen messages, called on every round start
+func (g *ReplayGuard) Flush() {
+	g.seen = make(map[msgKey]SignedMessage)
+}
@@ -0,0 +1,98 @@
+package node
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// signedPulse proposal committed to random entropy, commitment is checked against VRF output only when it's revealed
+func signedPulse(n *Node, epoch uint64, rst int64) PulseMessagePayload {
+	pm := NewPulseMessage(n.ID, epoch, rst, Commitment(n.ID, newEntropy()))
+	pm.Payload.Signature = n.Sign(pm.Payload.SigningData())
+	return pm.Payload
+}
+
+func TestReceivePulsesRejectsReplays(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	self.store = newStubStorage()
+	a := signingNode(t)
+	b := signingNode(t)
+	trust(t, self, a, b)
+	self.SetPulseNumber(5)
+	cons.SetRoundStartTime(1000)
+	cons.Alpha = VrfInput(nil, 6)
+
+	valid := signedPulse(a, 5, 1000)
+	cons.PulsesChan <- valid
+	// same message delivered twice
+	cons.PulsesChan <- valid
+	// another proposal of the same sender in the same round
+	cons.PulsesChan <- signedPulse(a, 5, 1000)
+	// captured in previous round
+	cons.PulsesChan <- signedPulse(b, 5, 998)
+	cons.PulsesChan <- signedPulse(b, 6, 1000)
+	cons.PulsesChan <- signedPulse(b, 4, 1000)
+	cons.PulsesChan <- signedPulse(b, 5, 1002)
+	forged := signedPulse(b, 5, 1000)
+	forged.From = a.ID
+	cons.PulsesChan <- forged
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cons.ReceivePulses(ctx, self)
+
+	require.Len(t, cons.PulseProposals, 1)
+	require.Equal(t, valid.PulseProposal, cons.PulseProposals[0])
+	require.Equal(t, map[string]int{
+		RejectDuplicate:    1,
+		RejectEquivocation: 1,
+		RejectStaleRound:   1,
+		RejectFutureRound:  1,
+		RejectStaleEpoch:   1,
+		RejectFutureEpoch:  1,
+		RejectSignature:    1,
+	}, cons.Guard.Rejected())
+}
+
+func TestReplayGuardRstTolerance(t *testing.T) {
+	g := NewReplayGuard(1)
+	a := signingNode(t)
+	require.Equal(t, "", g.CheckRound(signedPulse(a, 1, 999), 1, 1000))
+	require.Equal(t, "", g.CheckRound(signedPulse(a, 1, 1001), 1, 1000))
+	require.Equal(t, RejectStaleRound, g.CheckRound(signedPulse(a, 1, 998), 1, 1000))
+
+	// shifted rst within tolerance is still the same round for sender
+	reason, _ := g.Mark(signedPulse(a, 1, 999), 1, 1000)
+	require.Equal(t, "", reason)
+	reason, _ = g.Mark(signedPulse(a, 1, 1001), 1, 1000)
+	require.Equal(t, RejectEquivocation, reason)
+
+	g.Flush()
+	reason, _ = g.Mark(signedPulse(a, 1, 1000), 1, 1000)
+	require.Equal(t, "", reason)
+}
+
+func TestDecideWinnerDuplicateProposalsCountedOnce(t *testing.T) {
+	cons := basicCons()
+	cons.PulseVectors = []*PulseVector{
+		{
+			From: "A",
+			Vector: []*PulseProposal{
+				{From: "B", Entropy: "2"},
+				{From: "B", Entropy: "2"},
+				{From: "B", Entropy: "2"},
+			},
+		},
+		{
+			From: "B",
+			Vector: []*PulseProposal{
+				{From: "B", Entropy: "2"},
+			},
+		},
+	}
+	winner := cons.DecideWinner()
+	require.Equal(t, NoConsensusStatus, winner)
+}
@@ -0,0 +1,119 @@
+package node
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/mr-tron/base58"
+	"sort"
+)
+
+// Commitment hash of node secret entropy, it's proposed during collect and the secret is revealed during exchange,
+// so no node can choose its entropy after seeing others, commitment is bound to node id so it can't be copied
+func Commitment(from string, reveal string) string {
+	e := newCanonicalEncoder(Collect)
+	e.String(from)
+	e.String(reveal)
+	h := sha256.Sum256(e.Bytes())
+	return base58.Encode(h[:])
+}
+
+// CombineReveals combines reveals of agreed commitments into pulse entropy, commitments without valid reveal are skipped,
+// empty if none of commitments is revealed
+func CombineReveals(commitments []string, reveals map[string]string) string {
+	sorted := append([]string{}, commitments...)
+	sort.Strings(sorted)
+	h := sha256.New()
+	revealed := 0
+	for _, c := range sorted {
+		reveal, ok := reveals[c]
+		if !ok {
+			continue
+		}
+		e := newCanonicalEncoder(Collect)
+		e.String(c)
+		e.String(reveal)
+		h.Write(e.Bytes())
+		revealed++
+	}
+	if revealed == 0 {
+		return ""
+	}
+	return base58.Encode(h.Sum(nil))
+}
+
+// RevealSetPayload reveals proposer of the winning commitment combines into pulse entropy, nodes agree on the set
+// before confirming, so a reveal which reached only some nodes is combined by all of them or by none,
+// every reveal is sent as VRF proof of its commitment, VRF output is the revealed entropy
+type RevealSetPayload struct {
+	Signature []byte
+	Rst       int64       `json:"rst"`
+	Epoch     uint64      `json:"epoch"`
+	From      string      `json:"from"`
+	Proofs    []*VrfProof `json:"proofs"`
+}
+
+type RevealSetMessage struct {
+	Header
+	Payload RevealSetPayload `json:"payload"`
+}
+
+// NewSignedRevealSetMessage creates reveal set of proofs signed by node
+func NewSignedRevealSetMessage(n Noder, rst int64, proofs []*VrfProof) *RevealSetMessage {
+	m := &RevealSetMessage{
+		Header: Header{
+			Type: RevealSet,
+		},
+		Payload: RevealSetPayload{
+			Rst:    rst,
+			Epoch:  n.GetPulseNumber(),
+			From:   n.GetID(),
+			Proofs: proofs,
+		},
+	}
+	m.Payload.Signature = n.Sign(m.Payload.SigningData())
+	return m
+}
+
+func (m RevealSetPayload) GetType() MsgType {
+	return RevealSet
+}
+
+func (m RevealSetPayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m RevealSetPayload) GetRst() int64 {
+	return m.Rst
+}
+
+func (m RevealSetPayload) GetFrom() string {
+	return m.From
+}
+
+func (m RevealSetPayload) GetSignature() []byte {
+	return m.Signature
+}
+
+func (m RevealSetPayload) GetPayload() interface{} {
+	return m.Proofs
+}
+
+// SigningData encodes message header and proofs of reveals
+func (m RevealSetPayload) SigningData() []byte {
+	e := newMessageEncoder(m)
+	e.Uint64(uint64(len(m.Proofs)))
+	for _, p := range m.Proofs {
+		e.String(p.From)
+		e.Blob(p.Proof)
+	}
+	return e.Bytes()
+}
+
+func (m RevealSetPayload) String() string {
+	return fmt.Sprintf(
+		"[ rst: %d, from: %s, reveals: %d ]",
+		m.Rst,
+		m.From,
+		len(m.Proofs),
+	)
+}
@@ -0,0 +1,92 @@
+package node
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCommitmentBoundToNode(t *testing.T) {
+	require.Equal(t, Commitment("node-a", "secret"), Commitment("node-a", "secret"))
+	require.NotEqual(t, Commitment("node-a", "secret"), Commitment("node-b", "secret"))
+	require.NotEqual(t, Commitment("node-a", "secret"), Commitment("node-a", "other"))
+}
+
+// revealingNode node trusted by self with VRF output and proof for alpha
+func revealingNode(t *testing.T, self *Node, alpha []byte) (*Node, string, []byte) {
+	n := signingNode(t)
+	trust(t, self, n)
+	beta, proof := n.ProveVrf(alpha)
+	return n, VrfEntropy(beta), proof
+}
+
+func TestAddRevealMatchingCommitment(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	cons.Alpha = VrfInput(nil, 1)
+	a, aReveal, aProof := revealingNode(t, self, cons.Alpha)
+	b, bReveal, bProof := revealingNode(t, self, cons.Alpha)
+
+	// commitment received directly
+	direct := signedPulse(a, 0, 1000)
+	direct.PulseProposal = NewPulseProposal(a.ID, Commitment(a.ID, aReveal))
+	cons.DirectProposals[a.ID] = direct
+	require.True(t, cons.AddReveal(PulseVectorPayload{From: a.ID, Proof: aProof}, self))
+	require.Equal(t, aReveal, cons.Reveals[Commitment(a.ID, aReveal)])
+	require.Equal(t, &VrfProof{a.ID, aProof}, cons.Proofs[Commitment(a.ID, aReveal)])
+
+	// commitment relayed by sender in its own vector
+	own := NewPulseProposal(b.ID, Commitment(b.ID, bReveal))
+	require.True(t, cons.AddReveal(PulseVectorPayload{From: b.ID, EntropiesVector: &PulseVector{b.ID, []*PulseProposal{own}}, Proof: bProof}, self))
+	require.Equal(t, bReveal, cons.Reveals[own.Entropy])
+}
+
+func TestAddRevealMismatchDiscarded(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	cons.Alpha = VrfInput(nil, 1)
+	a, aReveal, aProof := revealingNode(t, self, cons.Alpha)
+	b, _, _ := revealingNode(t, self, cons.Alpha)
+
+	direct := signedPulse(a, 0, 1000)
+	direct.PulseProposal = NewPulseProposal(a.ID, Commitment(a.ID, "a-secret"))
+	cons.DirectProposals[a.ID] = direct
+	// sender committed to entropy it chose instead of VRF output
+	require.False(t, cons.AddReveal(PulseVectorPayload{From: a.ID, Proof: aProof}, self))
+	// commitment in own vector differs from one received directly
+	require.False(t, cons.AddReveal(PulseVectorPayload{
+		From:            a.ID,
+		EntropiesVector: &PulseVector{a.ID, []*PulseProposal{NewPulseProposal(a.ID, Commitment(a.ID, aReveal))}},
+		Proof:           aProof,
+	}, self))
+	// proof of another node commitment
+	require.False(t, cons.AddReveal(PulseVectorPayload{
+		From:            b.ID,
+		EntropiesVector: &PulseVector{b.ID, []*PulseProposal{NewPulseProposal(b.ID, Commitment(a.ID, aReveal))}},
+		Proof:           aProof,
+	}, self))
+	// vector without proof
+	require.False(t, cons.AddReveal(PulseVectorPayload{
+		From:            b.ID,
+		EntropiesVector: &PulseVector{b.ID, []*PulseProposal{NewPulseProposal(b.ID, Commitment(b.ID, "b-secret"))}},
+	}, self))
+	require.Empty(t, cons.Reveals)
+	require.Empty(t, cons.Proofs)
+}
+
+func TestCombineReveals(t *testing.T) {
+	reveals := map[string]string{
+		Commitment("a", "1"): "1",
+		Commitment("b", "2"): "2",
+		Commitment("c", "3"): "3",
+	}
+	all := []string{Commitment("a", "1"), Commitment("b", "2"), Commitment("c", "3")}
+	reordered := []string{all[2], all[0], all[1]}
+	combined := CombineReveals(all, reveals)
+	require.NotEmpty(t, combined)
+	require.Equal(t, combined, CombineReveals(reordered, reveals))
+	require.NotEqual(t, combined, CombineReveals(all[:2], reveals))
+
+	// unrevealed commitment is skipped
+	require.Equal(t, CombineReveals(all[:2], reveals), CombineReveals(append(all[:2:2], Commitment("d", "4")), reveals))
+	require.Empty(t, CombineReveals([]string{Commitment("d", "4")}, reveals))
+}
@@ -0,0 +1,94 @@
+package node
+
+import (
+	"github.com/stretchr/testify/require"
+	"rounds/logger"
+	"testing"
+)
+
+func signingNode(t *testing.T) *Node {
+	priv, pub := generateNewKeyPair()
+	id, err := NodeID(pub)
+	require.NoError(t, err)
+	return &Node{
+		privateKey: priv,
+		publicKey:  pub,
+		ID:         id,
+		peers:      NewPeerRegistry(),
+		log:        logger.NewLogger(),
+	}
+}
+
+func trust(t *testing.T, n *Node, peers ...*Node) {
+	for _, p := range peers {
+		_, err := n.peers.Add(p.ID, p.publicKey)
+		require.NoError(t, err)
+	}
+}
+
+func TestVerifyMessageSignedBySender(t *testing.T) {
+	a := signingNode(t)
+	b := signingNode(t)
+	trust(t, b, a)
+
+	pm := NewPulseMessage(a.ID, 1, 100, Commitment(a.ID, newEntropy()))
+	pm.Payload.Signature = a.Sign(pm.Payload.SigningData())
+	require.True(t, b.VerifyMessageTrusted(pm.Payload))
+
+	vm := NewPulseVectorMessage(a.ID, 1, 100, &PulseVector{a.ID, []*PulseProposal{pm.Payload.PulseProposal}}, nil)
+	vm.Payload.Signature = a.Sign(vm.Payload.SigningData())
+	require.True(t, b.VerifyMessageTrusted(vm.Payload))
+}
+
+func TestVerifyMessageTamperedPayloadRejected(t *testing.T) {
+	a := signingNode(t)
+	b := signingNode(t)
+	trust(t, b, a)
+
+	pm := NewPulseMessage(a.ID, 1, 100, Commitment(a.ID, newEntropy()))
+	pm.Payload.Signature = a.Sign(pm.Payload.SigningData())
+
+	replayed := pm.Payload
+	replayed.Rst = 102
+	require.False(t, b.VerifyMessageTrusted(replayed))
+
+	replaced := pm.Payload
+	replaced.PulseProposal = NewPulseProposal(a.ID, "other")
+	require.False(t, b.VerifyMessageTrusted(replaced))
+
+	// signature of collect message is not valid for vector message with the same fields
+	vm := NewPulseVectorMessage(a.ID, 1, 100, nil, nil)
+	vm.Payload.Signature = pm.Payload.Signature
+	require.False(t, b.VerifyMessageTrusted(vm.Payload))
+}
+
+func TestVerifyMessageImpersonationRejected(t *testing.T) {
+	a := signingNode(t)
+	c := signingNode(t)
+	b := signingNode(t)
+	trust(t, b, a, c)
+
+	// C signs message claiming to be A
+	pm := NewPulseMessage(a.ID, 1, 100, Commitment(a.ID, newEntropy()))
+	pm.Payload.Signature = c.Sign(pm.Payload.SigningData())
+	require.False(t, b.VerifyMessageTrusted(pm.Payload))
+
+	unknown := NewPulseMessage("node-unknown", 1, 100, Commitment("node-unknown", newEntropy()))
+	unknown.Payload.Signature = c.Sign(unknown.Payload.SigningData())
+	require.False(t, b.VerifyMessageTrusted(unknown.Payload))
+}
+
+func TestPeerRegistryBindsIDToKey(t *testing.T) {
+	a := signingNode(t)
+	r := NewPeerRegistry()
+	info, err := r.Add("0.0.0.0:20001", a.publicKey)
+	require.NoError(t, err)
+	require.Equal(t, a.ID, info.ID)
+
+	p, ok := r.Get(a.ID)
+	require.True(t, ok)
+	require.Equal(t, "0.0.0.0:20001", p.Addr)
+
+	_, err = r.Add("0.0.0.0:20002", a.publicKey)
+	require.Error(t, err)
+}
@@ -2,43 +2,105 @@ package node
 
 import (
 	"context"
-	"github.com/mosuka/cete/kvs"
+	"errors"
+	"fmt"
 	"google.golang.org/grpc"
 	"log"
 	testBadgerPb "rounds/ledger/pb"
 	"rounds/logger"
+	"sort"
+	"sync"
+)
+
+const (
+	// LedgerStore blocks are committed to ledger server
+	LedgerStore = "ledger"
+	// CeteStore blocks are committed to cete cluster
+	CeteStore = "cete"
+	// MemoryStorageKind blocks are kept in node memory, for tests and simulations,
+	// named apart from ledger.MemoryStore, which is in-memory db of ledger
+	MemoryStorageKind = "memory"
+	// EmbeddedStore blocks are committed to ledger running in node process, registered by ledger package
+	EmbeddedStore = "embedded"
 )
 
 type Storage interface {
 	// Commit commits block to storage
 	Commit(context.Context, BlockData) error
-	// GetLatestPulse gets latest block from storage
-	GetLatestBlock() (*BlockData, error)
+	// GetLatestBlock gets latest block from storage, nil if there are no blocks
+	GetLatestBlock() (*Block, error)
+	// GetBlock gets block by epoch, nil if not found
+	GetBlock(epoch uint64) (*Block, error)
+	// GetBlockRange gets up to limit blocks in epoch order from epoch up to the latest one, both inclusive,
+	// returns epoch the next page starts from, 0 if page ends with the latest block
+	GetBlockRange(from uint64, limit int) ([]*Block, uint64, error)
 	// GetLatestBlockEpoch get latest block epoch for nodes sync
 	GetLatestBlockEpoch() uint64
+	// CommitEvidence stores evidence of peer misbehaviour
+	CommitEvidence(context.Context, *Evidence) error
 }
 
-type CeteStorage struct {
-	client *kvs.GRPCClient
+// StorageFactory creates storage from node config
+type StorageFactory func(c *Config) (Storage, error)
+
+var (
+	storagesMu sync.RWMutex
+	storages   = make(map[string]StorageFactory)
+)
+
+func init() {
+	RegisterStorage(LedgerStore, func(c *Config) (Storage, error) {
+		if c.Store.Host == "" {
+			return nil, ErrStoreHostRequired(LedgerStore)
+		}
+		return NewBadgerStorage(c.Store.Host), nil
+	})
+	RegisterStorage(CeteStore, func(c *Config) (Storage, error) {
+		if c.Store.Host == "" {
+			return nil, ErrStoreHostRequired(CeteStore)
+		}
+		return NewCeteStorage(c.Store.Host), nil
+	})
+	RegisterStorage(MemoryStorageKind, func(c *Config) (Storage, error) {
+		return NewMemoryStorage(), nil
+	})
 }
 
-func (m *CeteStorage) GetLatestBlock() (*BlockData, error) {
-	// no iterator api in cete for now
-	return nil, nil
+// RegisterStorage makes storage selectable by store type in node config
+func RegisterStorage(name string, f StorageFactory) {
+	storagesMu.Lock()
+	defer storagesMu.Unlock()
+	if _, ok := storages[name]; ok {
+		panic(fmt.Sprintf("storage already registered: %s", name))
+	}
+	storages[name] = f
 }
 
-func (m *CeteStorage) Commit(ctx context.Context, b BlockData) error {
-	return nil
+// Storages names of registered storages
+func Storages() []string {
+	storagesMu.RLock()
+	defer storagesMu.RUnlock()
+	names := make([]string, 0, len(storages))
+	for name := range storages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
-func NewCeteStorage(host string) *CeteStorage {
-	s, err := kvs.NewGRPCClient(host)
-	if err != nil {
-		log.Fatal(err)
+// NewStorage storage of config store type, ledger if not set
+func NewStorage(c *Config) (Storage, error) {
+	name := c.Store.Type
+	if name == "" {
+		name = LedgerStore
 	}
-	return &CeteStorage{
-		s,
+	storagesMu.RLock()
+	f, ok := storages[name]
+	storagesMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownStore(name, Storages())
 	}
+	return f(c)
 }
 
 type TestBadgerStorage struct {
@@ -59,16 +121,82 @@ func (m *TestBadgerStorage) GetLatestBlockEpoch() uint64 {
 }
 
 func (m *TestBadgerStorage) Commit(ctx context.Context, b BlockData) error {
-	resp, err := m.client.Commit(ctx, &testBadgerPb.CommitPulseRequest{Entropy: b.WinnerEntropy})
+	resp, err := m.client.Commit(ctx, &testBadgerPb.CommitPulseRequest{
+		Epoch:         b.Epoch,
+		Entropy:       b.WinnerEntropy,
+		Confirmations: ConfirmationsToPb(b.Confirmations),
+		Qc:            b.QC.ToPb(),
+		Proofs:        VrfProofsToPb(b.Proofs),
+		Signature:     b.Signature,
+		Rst:           b.Rst,
+		Proposers:     b.Proposers,
+	})
 	if err != nil {
 		return err
 	}
 	m.log.Debugf("storage response: %s", resp)
+	if resp.Conflict {
+		return ErrEpochConflict(b.Epoch)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+func (m *TestBadgerStorage) CommitEvidence(ctx context.Context, e *Evidence) error {
+	resp, err := m.client.CommitEvidence(ctx, &testBadgerPb.CommitEvidenceRequest{Evidence: e.ToPb()})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
 	return nil
 }
 
-func (m *TestBadgerStorage) GetLatestBlock() (*BlockData, error) {
-	return nil, nil
+func (m *TestBadgerStorage) GetLatestBlock() (*Block, error) {
+	resp, err := m.client.GetLatestBlock(context.Background(), &testBadgerPb.GetLatestBlockRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return blockFromResponse(resp)
+}
+
+func (m *TestBadgerStorage) GetBlock(epoch uint64) (*Block, error) {
+	resp, err := m.client.GetBlock(context.Background(), &testBadgerPb.GetBlockRequest{Epoch: epoch})
+	if err != nil {
+		return nil, err
+	}
+	return blockFromResponse(resp)
+}
+
+func (m *TestBadgerStorage) GetBlockRange(from uint64, limit int) ([]*Block, uint64, error) {
+	resp, err := m.client.GetBlockRange(context.Background(), &testBadgerPb.GetBlockRangeRequest{
+		FromEpoch: from,
+		Limit:     uint32(limit),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.Error != "" {
+		return nil, 0, errors.New(resp.Error)
+	}
+	blocks := make([]*Block, 0, len(resp.Blocks))
+	for _, b := range resp.Blocks {
+		blocks = append(blocks, BlockFromPb(b))
+	}
+	return blocks, resp.NextEpoch, nil
+}
+
+func blockFromResponse(resp *testBadgerPb.GetBlockResponse) (*Block, error) {
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	if resp.Block == nil {
+		return nil, nil
+	}
+	return BlockFromPb(resp.Block), nil
 }
 
 func NewBadgerStorage(addr string) *TestBadgerStorage {
@@ -0,0 +1,359 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	pb "rounds/ledger/pb"
+	"sort"
+	"time"
+)
+
+// Catch-up sync, lagging node requests blocks from its latest epoch, peers respond with blocks of their ledgers,
+// hashes of the blocks differ between ledgers, so the block at requested epoch must agree with the local one,
+// the rest must be linked to it and confirmed by 2f+1 nodes, verified blocks are committed to the local chain
+
+const (
+	// syncBatch max blocks in sync response, response must fit udp datagram
+	syncBatch = 10
+	// syncTimeout how long node waits for sync responses of peers
+	syncTimeout = 500 * time.Millisecond
+)
+
+// SyncRequestPayload asks peers for blocks starting from epoch
+type SyncRequestPayload struct {
+	Signature []byte
+	Epoch     uint64 `json:"epoch"`
+	From      string `json:"from"`
+}
+
+type SyncRequestMessage struct {
+	Header
+	Payload SyncRequestPayload `json:"payload"`
+}
+
+// NewSyncRequestMessage creates unsigned request of blocks starting from epoch
+func NewSyncRequestMessage(from string, epoch uint64) *SyncRequestMessage {
+	return &SyncRequestMessage{
+		Header: Header{
+			Type: SyncRequest,
+		},
+		Payload: SyncRequestPayload{
+			Epoch: epoch,
+			From:  from,
+		},
+	}
+}
+
+func (m SyncRequestPayload) GetType() MsgType {
+	return SyncRequest
+}
+
+func (m SyncRequestPayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m SyncRequestPayload) GetRst() int64 {
+	return 0
+}
+
+func (m SyncRequestPayload) GetFrom() string {
+	return m.From
+}
+
+func (m SyncRequestPayload) GetSignature() []byte {
+	return m.Signature
+}
+
+func (m SyncRequestPayload) GetPayload() interface{} {
+	return m.Epoch
+}
+
+// SigningData encodes requested epoch and sender
+func (m SyncRequestPayload) SigningData() []byte {
+	e := newCanonicalEncoder(SyncRequest)
+	e.Uint64(m.Epoch)
+	e.String(m.From)
+	return e.Bytes()
+}
+
+// SyncResponsePayload blocks of responder ledger starting from requested epoch,
+// Latest is the latest epoch of responder, so requester knows if there are more blocks
+type SyncResponsePayload struct {
+	Signature []byte
+	Epoch     uint64 `json:"epoch"`
+	Latest    uint64 `json:"latest"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	// Blocks protobuf encoded blocks
+	Blocks [][]byte `json:"blocks"`
+}
+
+type SyncResponseMessage struct {
+	Header
+	Payload SyncResponsePayload `json:"payload"`
+}
+
+// NewSyncResponseMessage creates unsigned response with blocks to requester
+func NewSyncResponseMessage(from string, to string, epoch uint64, latest uint64, blocks []*Block) (*SyncResponseMessage, error) {
+	encoded := make([][]byte, 0, len(blocks))
+	for _, b := range blocks {
+		data, err := proto.Marshal(b.ToPb())
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data)
+	}
+	return &SyncResponseMessage{
+		Header: Header{
+			Type: SyncResponse,
+		},
+		Payload: SyncResponsePayload{
+			Epoch:  epoch,
+			Latest: latest,
+			From:   from,
+			To:     to,
+			Blocks: encoded,
+		},
+	}, nil
+}
+
+func (m SyncResponsePayload) GetType() MsgType {
+	return SyncResponse
+}
+
+func (m SyncResponsePayload) GetEpoch() uint64 {
+	return m.Epoch
+}
+
+func (m SyncResponsePayload) GetRst() int64 {
+	return 0
+}
+
+func (m SyncResponsePayload) GetFrom() string {
+	return m.From
+}
+
+func (m SyncResponsePayload) GetSignature() []byte {
+	return m.Signature
+}
+
+func (m SyncResponsePayload) GetPayload() interface{} {
+	return m.Blocks
+}
+
+// SigningData encodes requested epoch, latest epoch, sender, recipient and every block in order
+func (m SyncResponsePayload) SigningData() []byte {
+	e := newCanonicalEncoder(SyncResponse)
+	e.Uint64(m.Epoch)
+	e.Uint64(m.Latest)
+	e.String(m.From)
+	e.String(m.To)
+	e.Uint64(uint64(len(m.Blocks)))
+	for _, b := range m.Blocks {
+		e.Blob(b)
+	}
+	return e.Bytes()
+}
+
+// DecodeBlocks decodes blocks of response
+func (m SyncResponsePayload) DecodeBlocks() ([]*Block, error) {
+	blocks := make([]*Block, 0, len(m.Blocks))
+	for _, data := range m.Blocks {
+		var b pb.Block
+		if err := proto.Unmarshal(data, &b); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, BlockFromPb(&b))
+	}
+	return blocks, nil
+}
+
+func (m SyncResponsePayload) String() string {
+	return fmt.Sprintf("[ from: %s, to: %s, epoch: %d, latest: %d, blocks: %d ]", m.From, m.To, m.Epoch, m.Latest, len(m.Blocks))
+}
+
+// receiveSync serves sync requests of peers and passes responses to node catching up
+func (n *Node) receiveSync(t MsgType, payload json.RawMessage) error {
+	switch t {
+	case SyncRequest:
+		var req SyncRequestPayload
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return err
+		}
+		if !n.VerifyMessageTrusted(req) {
+			return ErrInvalidSignature(req.From)
+		}
+		return n.serveSync(req)
+	case SyncResponse:
+		var resp SyncResponsePayload
+		if err := json.Unmarshal(payload, &resp); err != nil {
+			return err
+		}
+		if resp.To != n.ID {
+			return nil
+		}
+		if !n.VerifyMessageTrusted(resp) {
+			return ErrInvalidSignature(resp.From)
+		}
+		select {
+		case n.syncResponses <- resp:
+		default:
+			n.log.Debugf("node is not syncing, dropping sync response: %s", resp)
+		}
+	}
+	return nil
+}
+
+// serveSync responds to peer with a batch of local blocks from requested epoch
+func (n *Node) serveSync(req SyncRequestPayload) error {
+	peer, ok := n.peers.Get(req.From)
+	if !ok {
+		return ErrUnknownPeer(req.From)
+	}
+	blocks, _, err := n.store.GetBlockRange(req.Epoch, syncBatch)
+	if err != nil {
+		return ErrStorageConnection(err)
+	}
+	msg, err := NewSyncResponseMessage(n.ID, req.From, req.Epoch, n.GetLatestPulseNumber(), blocks)
+	if err != nil {
+		return err
+	}
+	msg.Payload.Signature = n.Sign(msg.Payload.SigningData())
+	return n.client.Send(context.Background(), peer.Addr, msg)
+}
+
+// CatchUp commits blocks peers have committed after the latest local block, blocks are requested in batches
+// until no peer responds with newer valid blocks, fails if no peer responds in timeout
+func (n *Node) CatchUp(timeout time.Duration) error {
+	for {
+		anchor, err := n.store.GetLatestBlock()
+		if err != nil {
+			return ErrStorageConnection(err)
+		}
+		var latest uint64
+		from := uint64(1)
+		if anchor != nil {
+			latest, from = anchor.Epoch, anchor.Epoch
+		}
+		responses := n.requestSync(from, timeout)
+		if len(responses) == 0 {
+			return ErrNoSyncResponse(from)
+		}
+		// the most advanced peers first, a peer which lies about its latest epoch can't forge confirmed blocks
+		sort.Slice(responses, func(i, j int) bool { return responses[i].Latest > responses[j].Latest })
+		var blocks []*Block
+		for _, resp := range responses {
+			if resp.Latest <= latest {
+				break
+			}
+			if blocks, err = n.verifySyncBlocks(anchor, resp); err == nil && len(blocks) > 0 {
+				break
+			}
+			n.log.Errorf("invalid sync response %s: %v", resp, err)
+			blocks = nil
+		}
+		if len(blocks) == 0 {
+			return nil
+		}
+		for _, b := range blocks {
+			if err := n.store.Commit(context.Background(), b.Data()); err != nil {
+				return err
+			}
+		}
+		n.log.Infof("synced blocks up to epoch #%d", blocks[len(blocks)-1].Epoch)
+	}
+}
+
+// requestSync broadcasts sync request, collects responses of peers until all of them respond or timeout
+func (n *Node) requestSync(from uint64, timeout time.Duration) []SyncResponsePayload {
+	for len(n.syncResponses) > 0 {
+		<-n.syncResponses
+	}
+	msg := NewSyncRequestMessage(n.ID, from)
+	msg.Payload.Signature = n.Sign(msg.Payload.SigningData())
+	if err := n.client.Broadcast(context.Background(), msg); err != nil {
+		n.log.Error(err)
+		return nil
+	}
+	responded := make(map[string]bool)
+	responses := make([]SyncResponsePayload, 0, n.peers.Len())
+	deadline := time.After(timeout)
+	for len(responded) < n.peers.Len() {
+		select {
+		case resp := <-n.syncResponses:
+			if resp.Epoch != from || responded[resp.From] {
+				continue
+			}
+			responded[resp.From] = true
+			responses = append(responses, resp)
+		case <-deadline:
+			return responses
+		}
+	}
+	return responses
+}
+
+// verifySyncBlocks checks that response blocks are hash chained, the first one agrees with local latest block
+// and the rest are confirmed by 2f+1 distinct known nodes and signed by group if threshold signing is enabled,
+// returns blocks after local latest
+func (n *Node) verifySyncBlocks(anchor *Block, resp SyncResponsePayload) ([]*Block, error) {
+	blocks, err := resp.DecodeBlocks()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocks {
+		if !bytes.Equal(b.Hash, b.ComputeHash()) {
+			return nil, ErrBrokenChain(b.Epoch)
+		}
+	}
+	var prev *Block
+	if anchor != nil {
+		if len(blocks) == 0 || blocks[0].Epoch != anchor.Epoch || !bytes.Equal(blocks[0].AgreedHash(), anchor.AgreedHash()) {
+			return nil, ErrSyncMismatch(anchor.Epoch)
+		}
+		prev, blocks = blocks[0], blocks[1:]
+	}
+	keys := n.publicKeys()
+	for _, b := range blocks {
+		expected := &Block{}
+		expected.Chain(prev)
+		if b.Epoch != expected.Epoch || !bytes.Equal(b.PrevHash, expected.PrevHash) {
+			return nil, ErrBrokenChain(b.Epoch)
+		}
+		if err := b.VerifyConfirmations(keys); err != nil {
+			return nil, err
+		}
+		if n.threshold != nil {
+			if err := VerifyBlockSignature(n.threshold.GroupKey(), b); err != nil {
+				return nil, err
+			}
+		}
+		prev = b
+	}
+	return blocks, nil
+}
+
+// publicKeys keys of cluster nodes by id, self included
+func (n *Node) publicKeys() map[string]*ecdsa.PublicKey {
+	keys := map[string]*ecdsa.PublicKey{n.ID: n.publicKey}
+	for _, p := range n.peers.Peers() {
+		keys[p.ID] = p.PublicKey
+	}
+	return keys
+}
+
+// catchUp syncs missed blocks before node joins rounds, round start signal sent while syncing is stale and skipped
+func (n *Node) catchUp() {
+	if err := n.CatchUp(syncTimeout); err != nil {
+		n.log.Infof("catch-up sync failed: %s", err)
+	}
+	n.SetPulseNumber(n.GetLatestPulseNumber())
+	select {
+	case <-n.Consensus.GetStartChan():
+	default:
+	}
+}
@@ -0,0 +1,133 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// routeClient delivers messages to nodes by address as transport does
+type routeClient struct {
+	nodes map[string]*Node
+}
+
+func (m *routeClient) Broadcast(ctx context.Context, msg interface{}) error {
+	for addr := range m.nodes {
+		if err := m.Send(ctx, addr, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *routeClient) Send(ctx context.Context, addr string, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var raw map[string]*json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	go m.nodes[addr].RouteMsg(nil, raw)
+	return nil
+}
+
+// syncCluster nodes trusting each other, peers are addressed by id, nodes get empty memory storages
+func syncCluster(t *testing.T, size int) []*Node {
+	nodes := make([]*Node, 0, size)
+	for i := 0; i < size; i++ {
+		n := signingNode(t)
+		n.store = NewMemoryStorage()
+		n.syncResponses = make(chan SyncResponsePayload, size)
+		nodes = append(nodes, n)
+	}
+	for _, n := range nodes {
+		client := &routeClient{make(map[string]*Node)}
+		for _, p := range nodes {
+			if p != n {
+				trust(t, n, p)
+				client.nodes[p.ID] = p
+			}
+		}
+		n.client = client
+	}
+	return nodes
+}
+
+// commitConfirmed commits block of entropy confirmed by nodes to storage
+func commitConfirmed(t *testing.T, s Storage, epoch uint64, entropy string, nodes ...*Node) {
+	rst := int64(1000 + epoch)
+	confirmations := make([]*Confirmation, 0, len(nodes))
+	for _, n := range nodes {
+		confirmations = append(confirmations, signedConfirm(n, epoch-1, rst, entropy).GetPayload().(*Confirmation))
+	}
+	b, err := NewBlockData(epoch, rst, []string{nodes[0].ID}, entropy, confirmations)
+	require.NoError(t, err)
+	require.NoError(t, s.Commit(context.Background(), b))
+}
+
+func TestCatchUpSyncsConfirmedBlocks(t *testing.T) {
+	nodes := syncCluster(t, 4)
+	lagging := nodes[3]
+	for epoch := uint64(1); epoch <= syncBatch+2; epoch++ {
+		for _, n := range nodes[:3] {
+			commitConfirmed(t, n.store, epoch, "entropy", nodes[0], nodes[1], nodes[2])
+		}
+	}
+	require.NoError(t, lagging.CatchUp(time.Second))
+	require.Equal(t, uint64(syncBatch+2), lagging.GetLatestPulseNumber())
+	synced, err := lagging.store.GetBlock(syncBatch + 2)
+	require.NoError(t, err)
+	peer, err := nodes[0].store.GetBlock(syncBatch + 2)
+	require.NoError(t, err)
+	require.Equal(t, peer.AgreedHash(), synced.AgreedHash())
+	require.Len(t, synced.Confirmations, 3)
+
+	// nothing to sync when peers aren't ahead
+	require.NoError(t, lagging.CatchUp(time.Second))
+	require.Equal(t, uint64(syncBatch+2), lagging.GetLatestPulseNumber())
+}
+
+func TestCatchUpRejectsUnconfirmedBlocks(t *testing.T) {
+	nodes := syncCluster(t, 4)
+	lagging := nodes[3]
+	for _, n := range nodes[:3] {
+		commitConfirmed(t, n.store, 1, "entropy", nodes[0], nodes[1], nodes[2])
+	}
+	require.NoError(t, lagging.CatchUp(time.Second))
+	require.Equal(t, uint64(1), lagging.GetLatestPulseNumber())
+
+	// a single peer forges block confirmed by itself only
+	commitConfirmed(t, nodes[0].store, 2, "forged", nodes[0])
+	require.NoError(t, lagging.CatchUp(time.Second))
+	require.Equal(t, uint64(1), lagging.GetLatestPulseNumber())
+
+	// f+1 confirmations, one of them repeated, aren't a quorum
+	for _, n := range nodes[1:3] {
+		commitConfirmed(t, n.store, 2, "entropy", nodes[0], nodes[1], nodes[1])
+	}
+	require.NoError(t, lagging.CatchUp(time.Second))
+	require.Equal(t, uint64(1), lagging.GetLatestPulseNumber())
+
+	// local block doesn't agree with the chain of peers
+	nodes = syncCluster(t, 4)
+	lagging = nodes[3]
+	for epoch := uint64(1); epoch <= 2; epoch++ {
+		for _, n := range nodes[:3] {
+			commitConfirmed(t, n.store, epoch, "entropy", nodes[0], nodes[1], nodes[2])
+		}
+	}
+	commitConfirmed(t, lagging.store, 1, "another", nodes[0], nodes[1], nodes[2])
+	require.NoError(t, lagging.CatchUp(time.Second))
+	require.Equal(t, uint64(1), lagging.GetLatestPulseNumber())
+}
+
+func TestCatchUpFailsWithoutResponses(t *testing.T) {
+	nodes := syncCluster(t, 4)
+	n := nodes[0]
+	n.client = &routeClient{make(map[string]*Node)}
+	require.Error(t, n.CatchUp(10*time.Millisecond))
+}
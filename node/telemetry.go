@@ -25,6 +25,29 @@ var (
 		// [>=0ms, >=25ms, >=50ms, >=75ms, >=100ms, >=200ms, >=400ms, >=600ms, >=800ms, >=1s, >=2s, >=4s, >=6s]
 		Aggregation: view.Distribution(0, 25, 50, 75, 100, 200, 400, 600, 800, 1000, 2000, 4000, 6000),
 		TagKeys:     []tag.Key{KeyMethod, KeyLabel}}
+
+	RoundMs = stats.Float64("consensus/round_latency", "The latency in milliseconds per consensus round", "ms")
+
+	RoundLatencyView = &view.View{
+		Name:        "consensus/round_latency",
+		Measure:     RoundMs,
+		Description: "consensus round latencies distribution by engine, count of committed rounds is throughput",
+		Aggregation: view.Distribution(0, 25, 50, 75, 100, 200, 400, 600, 800, 1000, 2000, 4000, 6000),
+		TagKeys:     []tag.Key{KeyEngine, KeyStatus}}
+
+	RejectedMessages = stats.Int64("consensus/rejected", "The number of consensus messages rejected", "1")
+
+	RejectedMessagesView = &view.View{
+		Name:        "consensus/rejected",
+		Measure:     RejectedMessages,
+		Description: "rejected consensus messages by reason",
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{KeyReason, KeyMsgType}}
+)
+
+const (
+	RoundStatusCommitted = "committed"
+	RoundStatusFailed    = "failed"
 )
 
 var (
@@ -32,6 +55,10 @@ var (
 	KeyMethod, _ = tag.NewKey("method")
 	KeyStatus, _ = tag.NewKey("status")
 	KeyError, _  = tag.NewKey("error")
+
+	KeyEngine, _  = tag.NewKey("engine")
+	KeyReason, _  = tag.NewKey("reason")
+	KeyMsgType, _ = tag.NewKey("msg_type")
 )
 
 func SinceInMilliseconds(startTime time.Time) float64 {
@@ -0,0 +1,250 @@
+package node
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing/bn256"
+	"go.dedis.ch/kyber/v3/share"
+	"go.dedis.ch/kyber/v3/sign/bls"
+	"go.dedis.ch/kyber/v3/sign/tbls"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"strconv"
+)
+
+// Threshold BLS on bn256, any t of n nodes sign a block with their key shares and the shares are recovered
+// into one signature verified against the group public key, shares are generated by nodes with offline dkg
+
+const (
+	shareKeyFile = "share.key"
+	groupKeyFile = "group.key"
+
+	sharePemType = "THRESHOLD SHARE"
+	groupPemType = "GROUP KEY"
+)
+
+var thresholdSuite = bn256.NewSuite()
+
+var errThresholdKey = errors.New("threshold: malformed key")
+
+// Threshold key share of node and public polynomial of the group, commitment of the polynomial is the group public key
+type Threshold struct {
+	Share *share.PriShare
+	Group *share.PubPoly
+	// N total number of shares
+	N int
+}
+
+// BlockSigningData encodes epoch and winner entropy of a block as stored in ledger
+func BlockSigningData(epoch uint64, winnerEntropy []byte) []byte {
+	e := newCanonicalEncoder(blockSignatureDomain)
+	e.Uint64(epoch)
+	e.Blob(winnerEntropy)
+	return e.Bytes()
+}
+
+// Sign signs message with node key share
+func (t *Threshold) Sign(msg []byte) ([]byte, error) {
+	return tbls.Sign(thresholdSuite, t.Share, msg)
+}
+
+// VerifyShare checks signature share against public key share of its index
+func (t *Threshold) VerifyShare(msg []byte, sig []byte) error {
+	i, err := tbls.SigShare(sig).Index()
+	if err != nil {
+		return err
+	}
+	if i >= t.N {
+		return errThresholdKey
+	}
+	return tbls.Verify(thresholdSuite, t.Group, msg, sig)
+}
+
+// Recover recovers group signature of message from signature shares, invalid and duplicate shares are skipped
+func (t *Threshold) Recover(msg []byte, sigs [][]byte) ([]byte, error) {
+	valid := make([][]byte, 0, len(sigs))
+	seen := make(map[int]bool)
+	for _, sig := range sigs {
+		if err := t.VerifyShare(msg, sig); err != nil {
+			continue
+		}
+		i, _ := tbls.SigShare(sig).Index()
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		valid = append(valid, sig)
+	}
+	if len(valid) < t.Group.Threshold() {
+		return nil, ErrNotEnoughShares(len(valid), t.Group.Threshold())
+	}
+	return tbls.Recover(thresholdSuite, t.Group, msg, valid, t.Group.Threshold(), t.N)
+}
+
+// GroupKey marshalled group public key
+func (t *Threshold) GroupKey() []byte {
+	b, _ := t.Group.Commit().MarshalBinary()
+	return b
+}
+
+// VerifyGroupSignature checks group signature of message against marshalled group public key
+func VerifyGroupSignature(groupKey []byte, msg []byte, sig []byte) error {
+	pub := thresholdSuite.G2().Point()
+	if err := pub.UnmarshalBinary(groupKey); err != nil {
+		return err
+	}
+	return bls.Verify(thresholdSuite, pub, msg, sig)
+}
+
+// VerifyBlockSignature checks group signature of committed block
+func VerifyBlockSignature(groupKey []byte, b *Block) error {
+	if len(b.Signature) == 0 {
+		return errors.New("threshold: block is not signed")
+	}
+	return VerifyGroupSignature(groupKey, BlockSigningData(b.Epoch, b.WinnerEntropy), b.Signature)
+}
+
+// WriteThresholdKeys writes key share and group public polynomial to node keys dir
+func WriteThresholdKeys(keyDir string, s *share.PriShare, group *share.PubPoly, n int) error {
+	if err := os.MkdirAll(keyDir, os.ModePerm); err != nil {
+		return err
+	}
+	v, err := s.V.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	shareBytes := make([]byte, 4, 4+len(v))
+	binary.BigEndian.PutUint32(shareBytes, uint32(s.I))
+	sharePem := pem.EncodeToMemory(&pem.Block{Type: sharePemType, Bytes: append(shareBytes, v...)})
+	if err := ioutil.WriteFile(path.Join(keyDir, shareKeyFile), sharePem, 0600); err != nil {
+		return err
+	}
+	_, commits := group.Info()
+	groupBytes := make([]byte, 0)
+	for _, c := range commits {
+		b, err := c.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		groupBytes = append(groupBytes, b...)
+	}
+	groupPem := pem.EncodeToMemory(&pem.Block{
+		Type:    groupPemType,
+		Headers: map[string]string{"Nodes": strconv.Itoa(n)},
+		Bytes:   groupBytes,
+	})
+	return ioutil.WriteFile(path.Join(keyDir, groupKeyFile), groupPem, os.ModePerm)
+}
+
+// LoadThreshold loads node key share and group key if threshold signing is enabled
+func LoadThreshold(c *Config) *Threshold {
+	if !c.Node.Threshold {
+		return nil
+	}
+	t, err := ReadThresholdKeys(c.Node.Keyspath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return t
+}
+
+// ReadThresholdKeys reads key share and group key written by keygen combine, share must match the group polynomial
+func ReadThresholdKeys(keyDir string) (*Threshold, error) {
+	sharePem, err := ioutil.ReadFile(path.Join(keyDir, shareKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	groupPem, err := ioutil.ReadFile(path.Join(keyDir, groupKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	sb, _ := pem.Decode(sharePem)
+	if sb == nil || sb.Type != sharePemType || len(sb.Bytes) < 4 {
+		return nil, errThresholdKey
+	}
+	v := thresholdSuite.G2().Scalar()
+	if err := v.UnmarshalBinary(sb.Bytes[4:]); err != nil {
+		return nil, err
+	}
+	s := &share.PriShare{I: int(binary.BigEndian.Uint32(sb.Bytes)), V: v}
+	group, n, err := decodeGroup(groupPem)
+	if err != nil {
+		return nil, err
+	}
+	if s.I >= n || !group.Check(s) {
+		return nil, errThresholdKey
+	}
+	return &Threshold{s, group, n}, nil
+}
+
+// ReadGroupKey reads marshalled group public key written by keygen combine
+func ReadGroupKey(keyDir string) ([]byte, error) {
+	groupPem, err := ioutil.ReadFile(path.Join(keyDir, groupKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	group, n, err := decodeGroup(groupPem)
+	if err != nil {
+		return nil, err
+	}
+	return (&Threshold{Group: group, N: n}).GroupKey(), nil
+}
+
+// decodeGroup decodes public polynomial of the group and number of shares
+func decodeGroup(groupPem []byte) (*share.PubPoly, int, error) {
+	gb, _ := pem.Decode(groupPem)
+	if gb == nil || gb.Type != groupPemType {
+		return nil, 0, errThresholdKey
+	}
+	n, err := strconv.Atoi(gb.Headers["Nodes"])
+	if err != nil {
+		return nil, 0, errThresholdKey
+	}
+	pointLen := thresholdSuite.G2().PointLen()
+	if len(gb.Bytes) == 0 || len(gb.Bytes)%pointLen != 0 {
+		return nil, 0, errThresholdKey
+	}
+	commits := make([]kyber.Point, 0, len(gb.Bytes)/pointLen)
+	for i := 0; i < len(gb.Bytes); i += pointLen {
+		p := thresholdSuite.G2().Point()
+		if err := p.UnmarshalBinary(gb.Bytes[i : i+pointLen]); err != nil {
+			return nil, 0, err
+		}
+		commits = append(commits, p)
+	}
+	return share.NewPubPoly(thresholdSuite.G2(), thresholdSuite.G2().Point().Base(), commits), n, nil
+}
+
+// signBlockShare signs block of epoch with winner entropy encoded from entropy
+func signBlockShare(t *Threshold, epoch uint64, entropy string) ([]byte, error) {
+	winnerEntropy, err := EncodeEntropy(entropy)
+	if err != nil {
+		return nil, err
+	}
+	return t.Sign(BlockSigningData(epoch, winnerEntropy))
+}
+
+// SignBlock recovers group signature of block from signature shares of its confirmations,
+// block is left unsigned if threshold signing is disabled
+func SignBlock(n Noder, b *BlockData) error {
+	t := n.GetThreshold()
+	if t == nil {
+		return nil
+	}
+	shares := make([][]byte, 0, len(b.Confirmations))
+	for _, c := range b.Confirmations {
+		if len(c.Share) != 0 {
+			shares = append(shares, c.Share)
+		}
+	}
+	sig, err := t.Recover(BlockSigningData(b.Epoch, b.WinnerEntropy), shares)
+	if err != nil {
+		return err
+	}
+	b.Signature = sig
+	return nil
+}
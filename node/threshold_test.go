@@ -0,0 +1,118 @@
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// thresholdKeys generates threshold of n keys by distributed key generation of n nodes, keys are in participants order
+func thresholdKeys(t *testing.T, threshold int, n int) []*Threshold {
+	privs := make([]*ecdsa.PrivateKey, 0, n)
+	pubs := make([]*ecdsa.PublicKey, 0, n)
+	for i := 0; i < n; i++ {
+		priv, pub := generateNewKeyPair()
+		privs = append(privs, priv)
+		pubs = append(pubs, pub)
+	}
+	participants, err := NewDkgParticipants(pubs)
+	require.NoError(t, err)
+	deals := make([]*DkgDeal, 0, n)
+	for _, priv := range privs {
+		d, err := NewDkgDeal(priv, threshold, participants)
+		require.NoError(t, err)
+		deals = append(deals, d)
+	}
+	keys := make([]*Threshold, n)
+	for _, priv := range privs {
+		k, err := CombineDkgDeals(priv, participants, deals)
+		require.NoError(t, err)
+		keys[k.Share.I] = k
+	}
+	return keys
+}
+
+func TestThresholdRecoverGroupSignature(t *testing.T) {
+	keys := thresholdKeys(t, 2, 4)
+	msg := BlockSigningData(1, []byte("entropy"))
+	sigs := make([][]byte, 0)
+	for _, k := range keys {
+		sig, err := k.Sign(msg)
+		require.NoError(t, err)
+		require.NoError(t, k.VerifyShare(msg, sig))
+		sigs = append(sigs, sig)
+	}
+
+	// any t shares recover the same signature
+	sig, err := keys[0].Recover(msg, sigs[:2])
+	require.NoError(t, err)
+	other, err := keys[3].Recover(msg, sigs[2:])
+	require.NoError(t, err)
+	require.Equal(t, sig, other)
+	require.NoError(t, VerifyGroupSignature(keys[1].GroupKey(), msg, sig))
+	require.Error(t, VerifyGroupSignature(keys[1].GroupKey(), BlockSigningData(2, []byte("entropy")), sig))
+	require.Error(t, VerifyGroupSignature(thresholdKeys(t, 2, 4)[0].GroupKey(), msg, sig))
+
+	// duplicate and invalid shares don't count
+	_, err = keys[0].Recover(msg, [][]byte{sigs[0], sigs[0]})
+	require.Error(t, err)
+	wrong, err := keys[1].Sign(BlockSigningData(2, []byte("entropy")))
+	require.NoError(t, err)
+	_, err = keys[0].Recover(msg, [][]byte{sigs[0], wrong})
+	require.Error(t, err)
+	recovered, err := keys[0].Recover(msg, [][]byte{wrong, sigs[0], sigs[3]})
+	require.NoError(t, err)
+	require.Equal(t, sig, recovered)
+}
+
+func TestThresholdKeysWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "threshold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keys := thresholdKeys(t, 2, 4)
+	for i, k := range keys {
+		require.NoError(t, WriteThresholdKeys(path.Join(dir, string(rune('a'+i))), k.Share, k.Group, 4))
+	}
+
+	k, err := ReadThresholdKeys(path.Join(dir, "c"))
+	require.NoError(t, err)
+	require.Equal(t, 2, k.Share.I)
+	require.Equal(t, 4, k.N)
+	require.True(t, k.Group.Equal(keys[0].Group))
+
+	// share of another group is refused
+	otherGroup := thresholdKeys(t, 2, 4)[0].Group
+	require.NoError(t, WriteThresholdKeys(path.Join(dir, "a"), keys[0].Share, otherGroup, 4))
+	_, err = ReadThresholdKeys(path.Join(dir, "a"))
+	require.Error(t, err)
+}
+
+func TestCommitSignedByThreshold(t *testing.T) {
+	cons, self, peers, entropy := winningRound(t)
+	keys := thresholdKeys(t, 2, 4)
+	for i, n := range append([]*Node{self}, peers...) {
+		n.threshold = keys[i]
+	}
+
+	// share for another block is skipped
+	stale := NewSignedBlockConfirmMessage(peers[0], 1000, 2, entropy)
+	cons.ConfirmChan <- stale.Payload
+	cons.ConfirmChan <- NewSignedConfirmMessage(peers[2], 1000, entropy).Payload
+	cons.ConfirmChan <- NewSignedConfirmMessage(peers[1], 1000, entropy).Payload
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cons.Commit(ctx, self)
+
+	b := <-self.store.(*stubStorage).commits
+	require.Len(t, b.Confirmations, 3)
+	block := &Block{Epoch: 1, WinnerEntropy: b.WinnerEntropy, Signature: b.Signature}
+	require.NoError(t, VerifyBlockSignature(keys[0].GroupKey(), block))
+	block.Epoch = 2
+	require.Error(t, VerifyBlockSignature(keys[0].GroupKey(), block))
+}
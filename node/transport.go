@@ -19,6 +19,9 @@ func NewUDPTransport() *UDPTransport {
 	return &UDPTransport{logger.NewLogger()}
 }
 
+// maxDatagramSize max size of udp message
+const maxDatagramSize = 65535
+
 func (m *UDPTransport) Serve(node Noder) {
 	udpAddr, err := net.ResolveUDPAddr("udp", node.GetAddr())
 	if err != nil {
@@ -30,10 +33,16 @@ func (m *UDPTransport) Serve(node Noder) {
 		m.log.Fatal(err)
 	}
 	m.log.Infof("UDP server up and listening on %s", node.GetAddr())
+	// whole datagram is read at once, the rest of datagram which doesn't fit the buffer is discarded
+	buf := make([]byte, maxDatagramSize)
 	for {
-		var rawMsg map[string]*json.RawMessage
-		err := json.NewDecoder(ln).Decode(&rawMsg)
+		size, err := ln.Read(buf)
 		if err != nil {
+			m.log.Infof("failed to read udp message: %s", err.Error())
+			continue
+		}
+		var rawMsg map[string]*json.RawMessage
+		if err := json.Unmarshal(buf[:size], &rawMsg); err != nil {
 			m.log.Infof("failed to unmarshal udp message, dropping: %s", err.Error())
 			continue
 		}
@@ -0,0 +1,72 @@
+package node
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+	"time"
+)
+
+// routeNoder noder serving at addr, which passes routed messages to channel
+type routeNoder struct {
+	Noder
+	addr   string
+	routed chan map[string]*json.RawMessage
+}
+
+func (n *routeNoder) GetAddr() string {
+	return n.addr
+}
+
+func (n *routeNoder) RouteMsg(addr net.Addr, rawMsg map[string]*json.RawMessage) {
+	n.routed <- rawMsg
+}
+
+func TestUDPTransportRoutesEveryDatagram(t *testing.T) {
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.LocalAddr().String()
+	require.NoError(t, l.Close())
+	n := &routeNoder{addr: addr, routed: make(chan map[string]*json.RawMessage, 10)}
+	go NewUDPTransport().Serve(n)
+
+	conn, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	// server may not listen yet, probe is resent until it's routed
+	require.Eventually(t, func() bool {
+		if _, err := conn.Write([]byte(`{"type":"probe"}`)); err != nil {
+			return false
+		}
+		select {
+		case <-n.routed:
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// malformed datagram is dropped and doesn't corrupt the next ones, each datagram is one message
+	_, err = conn.Write([]byte(`{"type":`))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`{"type":"first"}`))
+	require.NoError(t, err)
+	large := make([]byte, 60000)
+	for i := range large {
+		large[i] = 'a'
+	}
+	_, err = conn.Write([]byte(`{"type":"second","data":"` + string(large) + `"}`))
+	require.NoError(t, err)
+	for _, want := range []string{`"first"`, `"second"`} {
+		select {
+		case msg := <-n.routed:
+			if string(*msg["type"]) == `"probe"` {
+				msg = <-n.routed
+			}
+			require.Equal(t, want, string(*msg["type"]))
+		case <-time.After(time.Second):
+			t.Fatalf("message %s is not routed", want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package node
 import (
 	crypto_rand "crypto/rand"
 	"encoding/base32"
+	"github.com/mr-tron/base58"
 	"hash/fnv"
 	"strings"
 )
@@ -16,6 +17,11 @@ func randomBytesString(length int) string {
 	return base32.StdEncoding.EncodeToString(randomBytes)[:length]
 }
 
+// newEntropy random entropy node proposes for a pulse
+func newEntropy() string {
+	return base58.Encode([]byte(randomBytesString(16)))
+}
+
 func hashFnv64(s []string) uint64 {
 	d := strings.Join(s, "")
 	h := fnv.New64a()
@@ -0,0 +1,216 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"github.com/mr-tron/base58"
+	"math/big"
+)
+
+// ECVRF on P-384 in the style of RFC 9381 with try-and-increment hash to curve,
+// output is unique for a key and input, proof lets anyone with public key check the output
+
+const (
+	vrfSuite = 0xFE
+	// vrfChallengeLen challenge length, half of the curve order length
+	vrfChallengeLen = 24
+	vrfPointLen     = 49
+	vrfScalarLen    = 48
+	// VrfProofLen encoded proof length, gamma point, challenge and response
+	VrfProofLen = vrfPointLen + vrfChallengeLen + vrfScalarLen
+)
+
+var (
+	errVrfHashToCurve = errors.New("vrf: failed to hash input to curve")
+	errVrfProof       = errors.New("vrf: malformed proof")
+)
+
+// VrfProof proof of node entropy contribution to a pulse
+type VrfProof struct {
+	From  string
+	Proof []byte
+}
+
+// VrfInput input of node VRF for a pulse, previous pulse entropy and epoch of the pulse
+func VrfInput(prev []byte, epoch uint64) []byte {
+	e := newCanonicalEncoder(Collect)
+	e.Blob(prev)
+	e.Uint64(epoch)
+	return e.Bytes()
+}
+
+// VrfEntropy encodes VRF output as node entropy
+func VrfEntropy(beta []byte) string {
+	return base58.Encode(beta)
+}
+
+// PulseEntropy recomputes pulse entropy from proofs of node contributions,
+// keys are public keys of nodes by id, prev is entropy of the previous pulse as stored in ledger
+func PulseEntropy(prev []byte, epoch uint64, proofs []*VrfProof, keys map[string]*ecdsa.PublicKey) (string, error) {
+	alpha := VrfInput(prev, epoch)
+	commitments := make([]string, 0, len(proofs))
+	reveals := make(map[string]string)
+	for _, p := range proofs {
+		pub, ok := keys[p.From]
+		if !ok {
+			return "", ErrUnknownPeer(p.From)
+		}
+		beta, err := VrfVerify(pub, alpha, p.Proof)
+		if err != nil {
+			return "", ErrInvalidVrfProof(p.From, err)
+		}
+		reveal := VrfEntropy(beta)
+		c := Commitment(p.From, reveal)
+		commitments = append(commitments, c)
+		reveals[c] = reveal
+	}
+	return CombineReveals(commitments, reveals), nil
+}
+
+// VrfProve computes VRF output and proof for input alpha
+func VrfProve(priv *ecdsa.PrivateKey, alpha []byte) ([]byte, []byte, error) {
+	c := priv.Curve
+	q := c.Params().N
+	hx, hy, err := vrfHashToCurve(c, &priv.PublicKey, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+	gx, gy := c.ScalarMult(hx, hy, vrfScalar(priv.D))
+	k, err := rand.Int(rand.Reader, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	ux, uy := c.ScalarBaseMult(vrfScalar(k))
+	vx, vy := c.ScalarMult(hx, hy, vrfScalar(k))
+	ch := vrfChallenge(c, hx, hy, gx, gy, ux, uy, vx, vy)
+	s := new(big.Int).Mul(ch, priv.D)
+	s.Add(s, k)
+	s.Mod(s, q)
+
+	proof := make([]byte, 0, VrfProofLen)
+	proof = append(proof, vrfCompress(c, gx, gy)...)
+	proof = append(proof, vrfPad(ch, vrfChallengeLen)...)
+	proof = append(proof, vrfPad(s, vrfScalarLen)...)
+	return vrfOutput(c, gx, gy), proof, nil
+}
+
+// VrfVerify checks proof of input alpha for public key, returns VRF output if proof is valid
+func VrfVerify(pub *ecdsa.PublicKey, alpha []byte, proof []byte) ([]byte, error) {
+	c := pub.Curve
+	q := c.Params().N
+	if len(proof) != VrfProofLen {
+		return nil, errVrfProof
+	}
+	gx, gy, ok := vrfDecompress(c, proof[:vrfPointLen])
+	if !ok {
+		return nil, errVrfProof
+	}
+	ch := new(big.Int).SetBytes(proof[vrfPointLen : vrfPointLen+vrfChallengeLen])
+	s := new(big.Int).SetBytes(proof[vrfPointLen+vrfChallengeLen:])
+	if s.Cmp(q) >= 0 {
+		return nil, errVrfProof
+	}
+	hx, hy, err := vrfHashToCurve(c, pub, alpha)
+	if err != nil {
+		return nil, err
+	}
+	negCh := new(big.Int).Sub(q, ch)
+	// U = s*B - c*Y
+	sbx, sby := c.ScalarBaseMult(vrfScalar(s))
+	cyx, cyy := c.ScalarMult(pub.X, pub.Y, vrfScalar(negCh))
+	ux, uy := c.Add(sbx, sby, cyx, cyy)
+	// V = s*H - c*Gamma
+	shx, shy := c.ScalarMult(hx, hy, vrfScalar(s))
+	cgx, cgy := c.ScalarMult(gx, gy, vrfScalar(negCh))
+	vx, vy := c.Add(shx, shy, cgx, cgy)
+	if vrfChallenge(c, hx, hy, gx, gy, ux, uy, vx, vy).Cmp(ch) != 0 {
+		return nil, errVrfProof
+	}
+	return vrfOutput(c, gx, gy), nil
+}
+
+// vrfHashToCurve hashes public key and input to a curve point, counter is incremented until hash is a valid x coordinate
+func vrfHashToCurve(c elliptic.Curve, pub *ecdsa.PublicKey, alpha []byte) (*big.Int, *big.Int, error) {
+	pk := vrfCompress(c, pub.X, pub.Y)
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New384()
+		h.Write([]byte{vrfSuite, 0x01})
+		h.Write(pk)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		x, y, ok := vrfDecompress(c, append([]byte{0x02}, h.Sum(nil)...))
+		if ok {
+			return x, y, nil
+		}
+	}
+	return nil, nil, errVrfHashToCurve
+}
+
+func vrfChallenge(c elliptic.Curve, points ...*big.Int) *big.Int {
+	h := sha512.New384()
+	h.Write([]byte{vrfSuite, 0x02})
+	for i := 0; i < len(points); i += 2 {
+		h.Write(vrfCompress(c, points[i], points[i+1]))
+	}
+	h.Write([]byte{0x00})
+	return new(big.Int).SetBytes(h.Sum(nil)[:vrfChallengeLen])
+}
+
+func vrfOutput(c elliptic.Curve, gx, gy *big.Int) []byte {
+	h := sha512.New384()
+	h.Write([]byte{vrfSuite, 0x03})
+	h.Write(vrfCompress(c, gx, gy))
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
+
+func vrfScalar(k *big.Int) []byte {
+	return vrfPad(k, vrfScalarLen)
+}
+
+// vrfPad big endian bytes of v left padded with zeros to length l
+func vrfPad(v *big.Int, l int) []byte {
+	b := make([]byte, l)
+	vb := v.Bytes()
+	copy(b[l-len(vb):], vb)
+	return b
+}
+
+// vrfCompress SEC1 compressed point encoding
+func vrfCompress(c elliptic.Curve, x, y *big.Int) []byte {
+	b := make([]byte, vrfPointLen)
+	b[0] = byte(2 + y.Bit(0))
+	copy(b[1:], vrfPad(x, vrfPointLen-1))
+	return b
+}
+
+// vrfDecompress decodes SEC1 compressed point, y is found from y^2 = x^3 - 3x + b
+func vrfDecompress(c elliptic.Curve, b []byte) (*big.Int, *big.Int, bool) {
+	if len(b) != vrfPointLen || (b[0] != 2 && b[0] != 3) {
+		return nil, nil, false
+	}
+	p := c.Params().P
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(p) >= 0 {
+		return nil, nil, false
+	}
+	y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+	threeX := new(big.Int).Mul(x, big.NewInt(3))
+	y2.Sub(y2, threeX)
+	y2.Add(y2, c.Params().B)
+	y2.Mod(y2, p)
+	y := new(big.Int).ModSqrt(y2, p)
+	if y == nil {
+		return nil, nil, false
+	}
+	if y.Bit(0) != uint(b[0]&1) {
+		y.Sub(p, y)
+	}
+	if !c.IsOnCurve(x, y) {
+		return nil, nil, false
+	}
+	return x, y, true
+}
@@ -0,0 +1,138 @@
+package node
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestVrfProveVerify(t *testing.T) {
+	priv, pub := generateNewKeyPair()
+	alpha := VrfInput([]byte("prev"), 2)
+
+	beta, proof, err := VrfProve(priv, alpha)
+	require.NoError(t, err)
+	require.Len(t, proof, VrfProofLen)
+	verified, err := VrfVerify(pub, alpha, proof)
+	require.NoError(t, err)
+	require.Equal(t, beta, verified)
+
+	// output is unique for key and input
+	beta2, proof2, err := VrfProve(priv, alpha)
+	require.NoError(t, err)
+	require.Equal(t, beta, beta2)
+	require.NotEqual(t, proof, proof2)
+	otherBeta, _, err := VrfProve(priv, VrfInput([]byte("prev"), 3))
+	require.NoError(t, err)
+	require.NotEqual(t, beta, otherBeta)
+}
+
+func TestVrfVerifyRejectsInvalidProof(t *testing.T) {
+	priv, pub := generateNewKeyPair()
+	_, otherPub := generateNewKeyPair()
+	alpha := VrfInput([]byte("prev"), 2)
+	_, proof, err := VrfProve(priv, alpha)
+	require.NoError(t, err)
+
+	_, err = VrfVerify(otherPub, alpha, proof)
+	require.Error(t, err)
+	_, err = VrfVerify(pub, VrfInput([]byte("other"), 2), proof)
+	require.Error(t, err)
+	_, err = VrfVerify(pub, alpha, proof[:len(proof)-1])
+	require.Error(t, err)
+	for _, i := range []int{0, vrfPointLen, VrfProofLen - 1} {
+		tampered := append([]byte{}, proof...)
+		tampered[i] ^= 1
+		_, err = VrfVerify(pub, alpha, tampered)
+		require.Error(t, err)
+	}
+}
+
+func TestPulseEntropyRecomputedFromProofs(t *testing.T) {
+	a := signingNode(t)
+	b := signingNode(t)
+	prev := []byte("prev pulse")
+	alpha := VrfInput(prev, 7)
+	keys := map[string]*ecdsa.PublicKey{a.ID: a.publicKey, b.ID: b.publicKey}
+
+	reveals := make(map[string]string)
+	commitments := make([]string, 0)
+	proofs := make([]*VrfProof, 0)
+	for _, n := range []*Node{a, b} {
+		beta, proof := n.ProveVrf(alpha)
+		c := Commitment(n.ID, VrfEntropy(beta))
+		reveals[c] = VrfEntropy(beta)
+		commitments = append(commitments, c)
+		proofs = append(proofs, &VrfProof{n.ID, proof})
+	}
+	entropy, err := PulseEntropy(prev, 7, proofs, keys)
+	require.NoError(t, err)
+	require.Equal(t, CombineReveals(commitments, reveals), entropy)
+
+	// proofs are bound to previous pulse and epoch
+	_, err = PulseEntropy(prev, 8, proofs, keys)
+	require.Error(t, err)
+	_, err = PulseEntropy(prev, 7, proofs, map[string]*ecdsa.PublicKey{a.ID: a.publicKey})
+	require.Error(t, err)
+}
+
+// signedVector vector of node with its own proposal and VRF proof revealing its entropy
+func signedVector(n *Node, epoch uint64, rst int64, own *PulseProposal, proof []byte) PulseVectorPayload {
+	vm := NewPulseVectorMessage(n.ID, epoch, rst, &PulseVector{n.ID, []*PulseProposal{own}}, proof)
+	vm.Payload.Signature = n.Sign(vm.Payload.SigningData())
+	return vm.Payload
+}
+
+func TestVrfProofRevealedOnlyInVector(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	self.store = newStubStorage()
+	self.client = &stubClient{}
+	cons.SetRoundStartTime(1000)
+
+	cons.SendPulses(context.Background(), self)
+	collect := self.client.(*stubClient).sent[0].(*PulseMessage).Payload
+	require.Empty(t, cons.Proofs)
+	require.NotContains(t, string(collect.SigningData()), string(cons.SelfProof))
+
+	cons.SendVectors(context.Background(), self)
+	vector := self.client.(*stubClient).sent[1].(*PulseVectorMessage).Payload
+	require.Equal(t, cons.SelfProof, vector.Proof)
+	beta, ok := self.VerifyVrf(self.ID, cons.Alpha, vector.Proof)
+	require.True(t, ok)
+	require.Equal(t, collect.PulseProposal.Entropy, Commitment(self.ID, VrfEntropy(beta)))
+	require.Equal(t, &VrfProof{self.ID, vector.Proof}, cons.Proofs[collect.PulseProposal.Entropy])
+}
+
+func TestReceiveVectorsRejectsInvalidVrfProof(t *testing.T) {
+	cons := basicCons()
+	self := signingNode(t)
+	self.store = newStubStorage()
+	a := signingNode(t)
+	b := signingNode(t)
+	c := signingNode(t)
+	trust(t, self, a, b, c)
+	cons.SetRoundStartTime(1000)
+	cons.Alpha = VrfInput(nil, 1)
+
+	reveal, proof := a.ProveVrf(cons.Alpha)
+	valid := NewPulseProposal(a.ID, Commitment(a.ID, VrfEntropy(reveal)))
+	cons.VectorChan <- signedVector(a, 0, 1000, valid, proof)
+	// entropy chosen by proposer instead of VRF output
+	_, proof = b.ProveVrf(cons.Alpha)
+	cons.VectorChan <- signedVector(b, 0, 1000, NewPulseProposal(b.ID, Commitment(b.ID, "chosen")), proof)
+	// proof for another pulse
+	beta, proof := c.ProveVrf(VrfInput(nil, 2))
+	cons.VectorChan <- signedVector(c, 0, 1000, NewPulseProposal(c.ID, Commitment(c.ID, VrfEntropy(beta))), proof)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	cons.ReceiveVectors(ctx, self)
+	require.Len(t, cons.PulseVectors, 1)
+	require.Equal(t, a.ID, cons.PulseVectors[0].From)
+	require.Equal(t, 2, cons.Guard.Rejected()[RejectVrf])
+	require.Len(t, cons.Proofs, 1)
+	require.Equal(t, VrfEntropy(reveal), cons.Reveals[valid.Entropy])
+}
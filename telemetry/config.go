@@ -19,8 +19,8 @@ type OpencensusConfig struct {
 		Port      string `validate:"required"`
 	} `validate:"required"`
 	ZPages struct {
-		Port string `json:"port",validate:"required"`
-	} `json:"zpages",validate:"required"`
+		Port string `json:"port" validate:"required"`
+	} `json:"zpages" validate:"required"`
 }
 
 func ServeZPages(c OpencensusConfig) {